@@ -0,0 +1,356 @@
+package anvil
+
+import "fmt"
+import "sort"
+import "github.com/davidcallanan/go-mcp/nbt"
+import "github.com/davidcallanan/go-mcp/registry"
+
+// Number of sections in a chunk, which makes the world 256 blocks high.
+const SectionCount = 16
+
+// The first data version, 20w17a of 1.16, in which block states no longer span multiple longs.
+const nonSpanningDataVersion = 2529
+
+// A block and the values of its properties, such as minecraft:grass_block[snowy=false].
+type BlockState = registry.BlockState
+
+// Converts between block states and their ids in the global palette of a protocol version, as sent to clients of that version.
+// Implemented by registry.Blocks.
+type GlobalPalette interface {
+	// Returns false for block states that do not exist in the version.
+	BlockStateId(state BlockState, protocol uint) (id uint32, ok bool)
+	// Returns false for ids that are not used by the version.
+	BlockState(id uint32, protocol uint) (state BlockState, ok bool)
+}
+
+// A 16x16x16 part of a chunk, as saved by vanilla.
+type Section struct {
+	Y int8 `nbt:"Y"`
+	Palette []BlockState `nbt:"Palette"`
+	// Indices into the palette, packed like in the chunk data packet of the version that saved the chunk.
+	BlockStates []int64 `nbt:"BlockStates"`
+}
+
+// A chunk column as saved by vanilla, leaving out everything but the blocks.
+type Chunk struct {
+	// The version of the game that saved the chunk, which decides how its blocks are stored.
+	DataVersion int32
+	X int32
+	Z int32
+	// Such as "full" for chunks that have been generated completely.
+	Status string
+	Sections []Section
+
+	// Everything else that was decoded along with the chunk, such as its entities, which is kept when it is encoded again.
+	raw map[string]interface{}
+}
+
+// The layout of a chunk in region files up to 1.17.
+type chunkNbt struct {
+	DataVersion int32 `nbt:"DataVersion"`
+	Level *struct {
+		X int32 `nbt:"xPos"`
+		Z int32 `nbt:"zPos"`
+		Status string `nbt:"Status"`
+		Sections []Section `nbt:"Sections"`
+	} `nbt:"Level"`
+}
+
+// Decodes the uncompressed NBT data of a chunk, as returned by Region.ChunkData.
+func DecodeChunk(data []byte) (*Chunk, error) {
+	var decoded chunkNbt
+	err := nbt.Unmarshal(data, &decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	// From 1.18 the sections are at the root, and their palettes are stored differently
+	if decoded.Level == nil {
+		return nil, UnsupportedChunkError { "Chunks saved by 1.18 or later are not supported" }
+	}
+
+	var raw map[string]interface{}
+	err = nbt.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Chunk {
+		DataVersion: decoded.DataVersion,
+		X: decoded.Level.X,
+		Z: decoded.Level.Z,
+		Status: decoded.Level.Status,
+		Sections: decoded.Level.Sections,
+		raw: raw,
+	}, nil
+}
+
+// Encodes the chunk as uncompressed NBT, as passed to Region.WriteChunkData.
+// Everything else that was decoded along with the chunk is kept, except for its light and heightmaps,
+// which are left for vanilla to compute again as they do not follow changes to the sections.
+func (chunk *Chunk) Encode() ([]byte, error) {
+	root := copyCompound(chunk.raw)
+	level, _ := root["Level"].(map[string]interface{})
+	level = copyCompound(level)
+
+	delete(level, "isLightOn")
+	delete(level, "Heightmaps")
+
+	root["DataVersion"] = chunk.DataVersion
+	root["Level"] = level
+	level["xPos"] = chunk.X
+	level["zPos"] = chunk.Z
+	level["Status"] = chunk.Status
+
+	// Sections keep their light, and the sections just below and above the world are kept as they are
+	sections := make(map[int8]map[string]interface{})
+	previous, _ := level["Sections"].([]interface{})
+
+	for _, value := range previous {
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		y, ok := section["Y"].(int8)
+		if !ok {
+			continue
+		}
+
+		section = copyCompound(section)
+		delete(section, "Palette")
+		delete(section, "BlockStates")
+		sections[y] = section
+	}
+
+	for _, section := range chunk.Sections {
+		encoded := sections[section.Y]
+		if encoded == nil {
+			encoded = map[string]interface{} { "Y": section.Y }
+			sections[section.Y] = encoded
+		}
+
+		if len(section.Palette) > 0 {
+			encoded["Palette"] = section.Palette
+			encoded["BlockStates"] = section.BlockStates
+		}
+	}
+
+	ys := make([]int, 0, len(sections))
+	for y := range sections {
+		ys = append(ys, int(y))
+	}
+	sort.Ints(ys)
+
+	list := make([]interface{}, len(ys))
+	for i, y := range ys {
+		list[i] = sections[int8(y)]
+	}
+	level["Sections"] = list
+
+	return nbt.Marshal(root)
+}
+
+// A shallow copy, so that encoding a chunk leaves what it was decoded from unchanged.
+func copyCompound(compound map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(compound))
+	for name, value := range compound {
+		result[name] = value
+	}
+	return result
+}
+
+// Whether every stage of generation has finished. Chunks next to those a player has visited are often only partly generated.
+func (chunk *Chunk) IsFullyGenerated() bool {
+	switch chunk.Status {
+	case "full":
+		return true
+	case "fullchunk", "postprocessed": // 1.13
+		return true
+	default:
+		return false
+	}
+}
+
+// Palette indices take at least 4 bits, and as many more as the size of the palette needs.
+func bitsPerBlockOf(paletteLength int) uint {
+	bitsPerBlock := uint(4)
+	for paletteLength > 1 << bitsPerBlock {
+		bitsPerBlock++
+	}
+	return bitsPerBlock
+}
+
+// The palette index of each of the 4096 blocks of the section, indexed by y * 256 + z * 16 + x.
+// A section without a palette is filled with air, which is then the only entry in the palette.
+func (section *Section) PaletteIndices(dataVersion int32) ([]uint32, error) {
+	if len(section.Palette) == 0 {
+		return make([]uint32, 4096), nil
+	}
+
+	bitsPerBlock := bitsPerBlockOf(len(section.Palette))
+	spanning := dataVersion < nonSpanningDataVersion
+	valuesPerLong := 64 / int(bitsPerBlock)
+
+	expectedLength := (4096 * int(bitsPerBlock) + 63) / 64
+	if !spanning {
+		expectedLength = (4096 + valuesPerLong - 1) / valuesPerLong
+	}
+
+	if len(section.BlockStates) != expectedLength {
+		return nil, MalformedRegionError { fmt.Sprintf("Section %d has %d longs of block states instead of %d", section.Y, len(section.BlockStates), expectedLength) }
+	}
+
+	result := make([]uint32, 4096)
+	mask := uint64(1) << bitsPerBlock - 1
+
+	for i := range result {
+		var value uint64
+
+		if spanning {
+			bit := i * int(bitsPerBlock)
+			value = uint64(section.BlockStates[bit / 64]) >> uint(bit % 64)
+
+			if bit % 64 + int(bitsPerBlock) > 64 {
+				value |= uint64(section.BlockStates[bit / 64 + 1]) << uint(64 - bit % 64)
+			}
+		} else {
+			value = uint64(section.BlockStates[i / valuesPerLong]) >> uint(i % valuesPerLong * int(bitsPerBlock))
+		}
+
+		index := uint32(value & mask)
+		if index >= uint32(len(section.Palette)) {
+			return nil, MalformedRegionError { fmt.Sprintf("Palette index %d out of range in section %d", index, section.Y) }
+		}
+
+		result[i] = index
+	}
+
+	return result, nil
+}
+
+// Converts the blocks of the chunk to global palette ids of a protocol version, in the layout of javaio.ChunkData.
+// Sections that hold nothing but air are left nil. Block states missing from the global palette become air, like in vanilla.
+func (chunk *Chunk) GlobalSections(protocol uint, palette GlobalPalette) ([SectionCount][]uint32, error) {
+	var result [SectionCount][]uint32
+
+	for i := range chunk.Sections {
+		section := &chunk.Sections[i]
+
+		// Vanilla saves the light of the sections just below and above the world as well
+		if section.Y < 0 || int(section.Y) >= SectionCount || len(section.Palette) == 0 {
+			continue
+		}
+
+		indices, err := section.PaletteIndices(chunk.DataVersion)
+		if err != nil {
+			return result, err
+		}
+
+		ids := make([]uint32, len(section.Palette))
+		isEmpty := true
+
+		for i, state := range section.Palette {
+			if id, ok := palette.BlockStateId(state, protocol); ok {
+				ids[i] = id
+			}
+
+			if ids[i] != 0 {
+				isEmpty = false
+			}
+		}
+
+		if isEmpty {
+			continue
+		}
+
+		blocks := make([]uint32, 4096)
+		for i, index := range indices {
+			blocks[i] = ids[index]
+		}
+
+		result[section.Y] = blocks
+	}
+
+	return result, nil
+}
+
+// Replaces the blocks of the chunk with global palette ids of a protocol version, the reverse of GlobalSections.
+// Ids missing from the global palette are saved as air, and nil sections are left out like sections of only air.
+func (chunk *Chunk) SetGlobalSections(sections [SectionCount][]uint32, protocol uint, palette GlobalPalette) {
+	chunk.Sections = nil
+
+	for y, blocks := range sections {
+		if blocks == nil {
+			continue
+		}
+
+		section := Section { Y: int8(y) }
+		indices := make([]uint32, len(blocks))
+		indicesById := make(map[uint32]uint32)
+		indicesByState := make(map[string]uint32)
+
+		for i, id := range blocks {
+			index, ok := indicesById[id]
+
+			if !ok {
+				state, ok := palette.BlockState(id, protocol)
+				if !ok {
+					state = BlockState { Name: "minecraft:air" }
+				}
+
+				// Ids the global palette does not know may all end up as air
+				index, ok = indicesByState[state.String()]
+				if !ok {
+					index = uint32(len(section.Palette))
+					section.Palette = append(section.Palette, state)
+					indicesByState[state.String()] = index
+				}
+
+				indicesById[id] = index
+			}
+
+			indices[i] = index
+		}
+
+		if len(section.Palette) == 1 && section.Palette[0].Name == "minecraft:air" {
+			continue
+		}
+
+		section.BlockStates = packIndices(indices, bitsPerBlockOf(len(section.Palette)), chunk.DataVersion < nonSpanningDataVersion)
+		chunk.Sections = append(chunk.Sections, section)
+	}
+}
+
+// Packs palette indices into longs, the reverse of Section.PaletteIndices.
+func packIndices(indices []uint32, bitsPerBlock uint, spanning bool) []int64 {
+	valuesPerLong := 64 / int(bitsPerBlock)
+
+	var result []uint64
+	if spanning {
+		result = make([]uint64, (len(indices) * int(bitsPerBlock) + 63) / 64)
+	} else {
+		result = make([]uint64, (len(indices) + valuesPerLong - 1) / valuesPerLong)
+	}
+
+	for i, index := range indices {
+		value := uint64(index)
+
+		if spanning {
+			bit := i * int(bitsPerBlock)
+			result[bit / 64] |= value << uint(bit % 64)
+
+			if bit % 64 + int(bitsPerBlock) > 64 {
+				result[bit / 64 + 1] |= value >> uint(64 - bit % 64)
+			}
+		} else {
+			result[i / valuesPerLong] |= value << uint(i % valuesPerLong * int(bitsPerBlock))
+		}
+	}
+
+	packed := make([]int64, len(result))
+	for i, value := range result {
+		packed[i] = int64(value)
+	}
+	return packed
+}
@@ -0,0 +1,83 @@
+package anvil
+
+import "bytes"
+import "io/ioutil"
+import "github.com/davidcallanan/go-mcp/nbt"
+import "github.com/google/uuid"
+
+// What vanilla keeps about a player between sessions, in the playerdata directory of the world.
+type PlayerData struct {
+	DataVersion int32 `nbt:"DataVersion"`
+	// The X, Y and Z of the feet of the player.
+	Position [3]float64 `nbt:"Pos"`
+	// The yaw and pitch of the player, in degrees.
+	Rotation [2]float32 `nbt:"Rotation"`
+	OnGround bool `nbt:"OnGround"`
+	// 0 for survival, 1 for creative, 2 for adventure and 3 for spectator.
+	Gamemode int32 `nbt:"playerGameType"`
+	Inventory []ItemStack `nbt:"Inventory"`
+	// The hotbar slot the player has selected, from 0 to 8.
+	SelectedSlot int32 `nbt:"SelectedItemSlot"`
+
+	// Everything else that was read along with the data, such as the health of the player, which is kept when it is written again.
+	raw map[string]interface{}
+}
+
+// A stack of items in an inventory, as saved by vanilla.
+type ItemStack struct {
+	// 0 to 8 for the hotbar, 9 to 35 for the rest of the inventory, 100 to 103 for the armor from the feet up, and -106 for the off hand.
+	Slot int8 `nbt:"Slot"`
+	// Such as "minecraft:stone".
+	Id string `nbt:"id"`
+	Count int8 `nbt:"Count"`
+	// Such as the enchantments and the custom name of the item.
+	Tag map[string]interface{} `nbt:"tag,omitempty"`
+}
+
+// The name of the file in the playerdata directory that holds the data of a player, such as "069a79f4-44e9-4726-a5be-fca90e38aaf5.dat".
+func PlayerDataFileName(playerUuid uuid.UUID) string {
+	return playerUuid.String() + ".dat"
+}
+
+// Reads a player data file. Fails with an error for which os.IsNotExist is true if the player has not played before.
+func ReadPlayerData(path string) (*PlayerData, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result PlayerData
+	_, err = nbt.ReadCompressed(bytes.NewReader(data), &result)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = nbt.ReadCompressed(bytes.NewReader(data), &result.raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Writes a player data file, replacing it in one step.
+// Everything else that was read along with the data is kept.
+func WritePlayerData(path string, data *PlayerData) error {
+	encoded, err := nbt.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	err = nbt.Unmarshal(encoded, &fields)
+	if err != nil {
+		return err
+	}
+
+	root := copyCompound(data.raw)
+	for name, value := range fields {
+		root[name] = value
+	}
+
+	return nbt.WriteFile(path, root)
+}
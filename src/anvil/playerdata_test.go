@@ -0,0 +1,76 @@
+package anvil
+
+import "os"
+import "testing"
+import "io/ioutil"
+import "path/filepath"
+import "github.com/davidcallanan/go-mcp/nbt"
+import "github.com/google/uuid"
+
+func TestPlayerData(t *testing.T) {
+	directory, err := ioutil.TempDir("", "anvil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, PlayerDataFileName(uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")))
+	if filepath.Base(path) != "069a79f4-44e9-4726-a5be-fca90e38aaf5.dat" {
+		t.Errorf("File name incorrect: %s", filepath.Base(path))
+	}
+
+	_, err = ReadPlayerData(path)
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file but instead got: %v", err)
+	}
+
+	// Saved by vanilla, with more than is read
+	err = nbt.WriteFile(path, map[string]interface{} {
+		"Pos": []float64 {1.5, 64, -3.25},
+		"Rotation": []float32 {90, -10},
+		"playerGameType": int32(0),
+		"Health": float32(17),
+		"Inventory": []map[string]interface{} {
+			{ "Slot": int8(0), "id": "minecraft:stone", "Count": int8(64) },
+			{ "Slot": int8(-106), "id": "minecraft:shield", "Count": int8(1), "tag": map[string]interface{} { "Damage": int32(3) } },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ReadPlayerData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Position != [3]float64 {1.5, 64, -3.25} || data.Rotation != [2]float32 {90, -10} || data.Gamemode != 0 {
+		t.Errorf("Data incorrect: %#v", data)
+	}
+
+	if len(data.Inventory) != 2 || data.Inventory[1].Slot != -106 || data.Inventory[1].Id != "minecraft:shield" || data.Inventory[1].Tag["Damage"] != int32(3) {
+		t.Errorf("Inventory incorrect: %#v", data.Inventory)
+	}
+
+	data.Position[1] = 80
+	data.Gamemode = 1
+	data.Inventory = data.Inventory[:1]
+
+	err = WritePlayerData(path, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = ReadPlayerData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Position[1] != 80 || data.Gamemode != 1 || len(data.Inventory) != 1 || data.Inventory[0].Count != 64 {
+		t.Errorf("Written data incorrect: %#v", data)
+	}
+
+	if data.raw["Health"] != float32(17) {
+		t.Errorf("Health not kept: %v", data.raw["Health"])
+	}
+}
@@ -0,0 +1,329 @@
+// Package anvil reads and writes worlds saved in the Anvil format, as used by vanilla from 1.13 up to 1.17,
+// where chunks are stored in region files of 32x32 chunks each.
+package anvil
+
+import "io"
+import "os"
+import "fmt"
+import "sync"
+import "time"
+import "bytes"
+import "compress/zlib"
+import "encoding/binary"
+import "github.com/davidcallanan/go-mcp/nbt"
+
+// Region files are made up of sectors of this many bytes, the first two of which hold the sector table.
+const SectorSize = 4096
+
+// Number of chunks along each side of a region.
+const RegionSize = 32
+
+const (
+	compressionGzip = 1
+	compressionZlib = 2
+	compressionNone = 3
+	// Set on the compression type of chunks too large for the region file, which are stored in separate .mcc files.
+	compressionExternal = 0x80
+)
+
+type MalformedRegionError struct {
+	details string
+}
+
+func (err MalformedRegionError) Error() string {
+	return fmt.Sprintf("Malformed region file: %s", err.details)
+}
+
+type UnsupportedChunkError struct {
+	details string
+}
+
+func (err UnsupportedChunkError) Error() string {
+	return fmt.Sprintf("Unsupported chunk: %s", err.details)
+}
+
+// Returned when writing to a region that was opened for reading only.
+type ReadOnlyRegionError struct {
+	details string
+}
+
+func (err ReadOnlyRegionError) Error() string {
+	return fmt.Sprintf("Read-only region: %s", err.details)
+}
+
+// The name of the region file holding the chunk at the given chunk coordinates, such as "r.-1.0.mca".
+func RegionFileName(chunkX int32, chunkZ int32) string {
+	return fmt.Sprintf("r.%d.%d.mca", chunkX >> 5, chunkZ >> 5)
+}
+
+// A region file opened for reading, and possibly writing. Safe to use from multiple goroutines.
+type Region struct {
+	file io.ReaderAt
+	// nil for regions opened for reading only.
+	writer io.WriterAt
+	closer io.Closer
+	// Guards locations, and keeps the sectors of a chunk from being reused while the chunk is read.
+	lock sync.RWMutex
+	// The first sector and sector count of each chunk, indexed by z * 32 + x. Zero for chunks that have not been generated.
+	locations [RegionSize * RegionSize]uint32
+}
+
+func OpenRegion(path string) (*Region, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := ReadRegion(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	region.closer = file
+	return region, nil
+}
+
+// Opens a region file for reading and writing, creating an empty one if it does not exist.
+func OpenWritableRegion(path string) (*Region, error) {
+	file, err := os.OpenFile(path, os.O_RDWR | os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	region, err := ReadRegion(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	region.writer = file
+	region.closer = file
+	return region, nil
+}
+
+// Reads the sector table of a region file, leaving the chunks to be read as they are needed.
+func ReadRegion(file io.ReaderAt) (*Region, error) {
+	header := make([]byte, RegionSize * RegionSize * 4)
+
+	_, err := file.ReadAt(header, 0)
+	if err == io.EOF {
+		// Vanilla creates empty region files, which hold no chunks
+		return &Region { file: file }, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	region := &Region { file: file }
+	for i := range region.locations {
+		region.locations[i] = binary.BigEndian.Uint32(header[i * 4:])
+	}
+
+	return region, nil
+}
+
+// Closes the file, if the region was opened with OpenRegion.
+func (region *Region) Close() error {
+	if region.closer == nil {
+		return nil
+	}
+
+	return region.closer.Close()
+}
+
+// Chunk coordinates are taken modulo the size of the region, so either absolute or relative ones may be used.
+func locationIndex(chunkX int32, chunkZ int32) int {
+	return int(chunkZ & (RegionSize - 1)) * RegionSize + int(chunkX & (RegionSize - 1))
+}
+
+func (region *Region) HasChunk(chunkX int32, chunkZ int32) bool {
+	region.lock.RLock()
+	defer region.lock.RUnlock()
+	return region.locations[locationIndex(chunkX, chunkZ)] != 0
+}
+
+// The uncompressed NBT data of a chunk, or nil if the chunk has not been generated.
+func (region *Region) ChunkData(chunkX int32, chunkZ int32) ([]byte, error) {
+	region.lock.RLock()
+	defer region.lock.RUnlock()
+
+	location := region.locations[locationIndex(chunkX, chunkZ)]
+	if location == 0 {
+		return nil, nil
+	}
+
+	offset := int64(location >> 8) * SectorSize
+	sectorCount := int(location & 0xff)
+
+	if offset < 2 * SectorSize {
+		return nil, MalformedRegionError { fmt.Sprintf("Chunk %d, %d overlaps the sector table", chunkX, chunkZ) }
+	}
+
+	header := make([]byte, 5)
+	_, err := region.file.ReadAt(header, offset)
+	if err != nil {
+		return nil, MalformedRegionError { fmt.Sprintf("Cannot read header of chunk %d, %d: %s", chunkX, chunkZ, err) }
+	}
+
+	// The length includes the compression type
+	length := int(binary.BigEndian.Uint32(header))
+	compression := header[4]
+
+	if length < 1 || length + 4 > sectorCount * SectorSize {
+		return nil, MalformedRegionError { fmt.Sprintf("Invalid length %d of chunk %d, %d", length, chunkX, chunkZ) }
+	}
+
+	if compression & compressionExternal != 0 {
+		return nil, UnsupportedChunkError { fmt.Sprintf("Chunk %d, %d is stored in a separate file", chunkX, chunkZ) }
+	}
+
+	data := make([]byte, length - 1)
+	_, err = region.file.ReadAt(data, offset + 5)
+	if err != nil {
+		return nil, MalformedRegionError { fmt.Sprintf("Cannot read chunk %d, %d: %s", chunkX, chunkZ, err) }
+	}
+
+	switch compression {
+	case compressionGzip, compressionZlib:
+		decompressed, err := nbt.NewDecompressingReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, MalformedRegionError { fmt.Sprintf("Cannot decompress chunk %d, %d: %s", chunkX, chunkZ, err) }
+		}
+
+		var result bytes.Buffer
+		_, err = result.ReadFrom(decompressed)
+		if err != nil {
+			return nil, MalformedRegionError { fmt.Sprintf("Cannot decompress chunk %d, %d: %s", chunkX, chunkZ, err) }
+		}
+
+		return result.Bytes(), nil
+	case compressionNone:
+		return data, nil
+	default:
+		return nil, UnsupportedChunkError { fmt.Sprintf("Unknown compression type %d of chunk %d, %d", compression, chunkX, chunkZ) }
+	}
+}
+
+// Reads and decodes a chunk, or returns nil if the chunk has not been generated.
+func (region *Region) ReadChunk(chunkX int32, chunkZ int32) (*Chunk, error) {
+	data, err := region.ChunkData(chunkX, chunkZ)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	return DecodeChunk(data)
+}
+
+// Compresses the NBT data of a chunk with zlib, like vanilla, and stores it in the region.
+// The chunk is written to sectors that no chunk uses before the sector table is changed to point to them,
+// so that the previous version of the chunk is left intact should the server stop halfway through.
+func (region *Region) WriteChunkData(chunkX int32, chunkZ int32, data []byte) error {
+	if region.writer == nil {
+		return ReadOnlyRegionError { fmt.Sprintf("Cannot write chunk %d, %d", chunkX, chunkZ) }
+	}
+
+	// Room for the length and compression type, which are filled in once the length is known
+	var payload bytes.Buffer
+	payload.Write(make([]byte, 5))
+
+	compressor := zlib.NewWriter(&payload)
+	compressor.Write(data)
+	err := compressor.Close()
+	if err != nil {
+		return err
+	}
+
+	sectorCount := (payload.Len() + SectorSize - 1) / SectorSize
+	if sectorCount > 0xff {
+		return UnsupportedChunkError { fmt.Sprintf("Chunk %d, %d is too large to be stored in the region file", chunkX, chunkZ) }
+	}
+
+	// The compressed length plus one for the compression type, leaving out the padding
+	length := payload.Len() - 4
+
+	payload.Write(make([]byte, sectorCount * SectorSize - payload.Len()))
+	sectors := payload.Bytes()
+	binary.BigEndian.PutUint32(sectors, uint32(length))
+	sectors[4] = compressionZlib
+
+	region.lock.Lock()
+	defer region.lock.Unlock()
+
+	firstSector := region.freeSectors(sectorCount)
+	_, err = region.writer.WriteAt(sectors, int64(firstSector) * SectorSize)
+	if err != nil {
+		return err
+	}
+
+	// The chunk must be on disk before the sector table refers to it
+	if file, ok := region.writer.(interface { Sync() error }); ok {
+		err = file.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	index := locationIndex(chunkX, chunkZ)
+	location := uint32(firstSector) << 8 | uint32(sectorCount)
+
+	entry := make([]byte, 4)
+	binary.BigEndian.PutUint32(entry, location)
+	_, err = region.writer.WriteAt(entry, int64(index) * 4)
+	if err != nil {
+		return err
+	}
+
+	region.locations[index] = location
+
+	// The second sector of the table holds the time each chunk was last saved
+	binary.BigEndian.PutUint32(entry, uint32(time.Now().Unix()))
+	_, err = region.writer.WriteAt(entry, SectorSize + int64(index) * 4)
+	return err
+}
+
+// Encodes the chunk and stores it in the region, at the position given by its coordinates.
+func (region *Region) WriteChunk(chunk *Chunk) error {
+	data, err := chunk.Encode()
+	if err != nil {
+		return err
+	}
+
+	return region.WriteChunkData(chunk.X, chunk.Z, data)
+}
+
+// The first of the given number of consecutive sectors that no chunk uses, which may be past the end of the file.
+// The sectors of the chunk being replaced count as used, as they must stay intact until the table no longer refers to them.
+// Must be called with the lock held.
+func (region *Region) freeSectors(count int) int {
+	// The sector table itself
+	isUsed := []bool { true, true }
+
+	for _, location := range region.locations {
+		start := int(location >> 8)
+		end := start + int(location & 0xff)
+
+		for len(isUsed) < end {
+			isUsed = append(isUsed, false)
+		}
+
+		for i := start; i < end; i++ {
+			isUsed[i] = true
+		}
+	}
+
+	run := 0
+	for i := range isUsed {
+		if isUsed[i] {
+			run = 0
+			continue
+		}
+
+		run++
+		if run == count {
+			return i - count + 1
+		}
+	}
+
+	// The free sectors at the end of the file, if any, are extended
+	return len(isUsed) - run
+}
@@ -0,0 +1,388 @@
+package anvil
+
+import "os"
+import "bytes"
+import "testing"
+import "io/ioutil"
+import "path/filepath"
+import "compress/zlib"
+import "encoding/binary"
+import "github.com/davidcallanan/go-mcp/nbt"
+
+// Ids are indices into the list. Knows every block in the fixture but minecraft:unknown_block.
+type testPalette []BlockState
+
+func (palette testPalette) BlockStateId(state BlockState, protocol uint) (uint32, bool) {
+	for i, known := range palette {
+		if known.String() == state.String() {
+			return uint32(i), true
+		}
+	}
+
+	return 0, false
+}
+
+func (palette testPalette) BlockState(id uint32, protocol uint) (BlockState, bool) {
+	if int(id) >= len(palette) {
+		return BlockState {}, false
+	}
+
+	return palette[id], true
+}
+
+var wools = []string {
+	"white", "orange", "magenta", "light_blue", "yellow", "lime", "pink", "gray",
+	"light_gray", "cyan", "purple", "blue", "brown", "green", "red", "black",
+}
+
+// Air, stone, bedrock, the two oak logs, and the wools from 5 onwards.
+var fixturePalette = func() testPalette {
+	palette := testPalette {
+		{ Name: "minecraft:air" },
+		{ Name: "minecraft:stone" },
+		{ Name: "minecraft:bedrock" },
+		{ Name: "minecraft:oak_log", Properties: map[string]string { "axis": "y" } },
+		{ Name: "minecraft:oak_log", Properties: map[string]string { "axis": "x" } },
+	}
+
+	for _, colour := range wools {
+		palette = append(palette, BlockState { Name: "minecraft:" + colour + "_wool" })
+	}
+
+	return palette
+}()
+
+func TestRegionFileName(t *testing.T) {
+	iomap := []struct {
+		x int32
+		z int32
+		name string
+	} {
+		{0, 0, "r.0.0.mca"},
+		{31, 32, "r.0.1.mca"},
+		{-1, -32, "r.-1.-1.mca"},
+		{-33, 100, "r.-2.3.mca"},
+	}
+
+	for i, mapping := range iomap {
+		if name := RegionFileName(mapping.x, mapping.z); name != mapping.name {
+			t.Errorf("Output incorrect for mapping %d: %s", i, name)
+		}
+	}
+}
+
+func TestBlockStateString(t *testing.T) {
+	state := BlockState {
+		Name: "minecraft:oak_stairs",
+		Properties: map[string]string { "waterlogged": "false", "facing": "north", "half": "top", "shape": "straight" },
+	}
+
+	if output := state.String(); output != "minecraft:oak_stairs[facing=north,half=top,shape=straight,waterlogged=false]" {
+		t.Errorf("Output incorrect: %s", output)
+	}
+}
+
+func TestReadRegion(t *testing.T) {
+	region, err := OpenRegion("testdata/r.0.0.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer region.Close()
+
+	// Compressed with zlib by 1.14.4, gzip by 1.16.5 and not at all by 1.15.2, which changes how blocks are packed
+	iomap := []struct {
+		x int32
+		z int32
+		dataVersion int32
+		status string
+	} {
+		{0, 0, 1976, "full"},
+		{1, 0, 2586, "full"},
+		{2, 0, 2230, "features"},
+		{-1, -1, 2230, "full"},
+	}
+
+	for i, mapping := range iomap {
+		chunk, err := region.ReadChunk(mapping.x, mapping.z)
+		if err != nil {
+			t.Errorf("Error for mapping %d: %v", i, err)
+			continue
+		}
+
+		if chunk.DataVersion != mapping.dataVersion || chunk.Status != mapping.status || chunk.X & 31 != mapping.x & 31 {
+			t.Errorf("Chunk incorrect for mapping %d: %d %s %d", i, chunk.DataVersion, chunk.Status, chunk.X)
+		}
+
+		sections, err := chunk.GlobalSections(0x022E, fixturePalette)
+		if err != nil {
+			t.Errorf("Error converting mapping %d: %v", i, err)
+			continue
+		}
+
+		bottom := sections[0]
+		if bottom[0] != 2 || bottom[255] != 2 || bottom[256] != 1 || bottom[14 * 256] != 1 || bottom[15 * 256] != 0 {
+			t.Errorf("Bottom section incorrect for mapping %d", i)
+		}
+
+		// The wool section repeats the 20 entries of its palette, which take 5 bits each
+		pattern := sections[4]
+		for j, block := range pattern {
+			var expected uint32
+			switch index := j % 20; {
+			case index >= 1 && index <= 16:
+				expected = 5 + uint32(index - 1)
+			case index == 17:
+				expected = 3
+			case index == 18:
+				expected = 4
+			}
+
+			if block != expected {
+				t.Errorf("Block %d incorrect for mapping %d: %d", j, i, block)
+				break
+			}
+		}
+
+		// Missing sections and those holding only air are left nil
+		for _, y := range []int {1, 2, 3, 5} {
+			if sections[y] != nil {
+				t.Errorf("Section %d not empty for mapping %d", y, i)
+			}
+		}
+	}
+
+	if region.HasChunk(5, 5) {
+		t.Error("Missing chunk reported as present")
+	}
+
+	if chunk, err := region.ReadChunk(5, 5); chunk != nil || err != nil {
+		t.Errorf("Missing chunk read: %v %v", chunk, err)
+	}
+}
+
+func TestReadMalformedRegion(t *testing.T) {
+	// Claims a chunk in the first sector, where the sector table is
+	header := make([]byte, 8192)
+	header[2] = 1
+	header[3] = 1
+
+	region, err := ReadRegion(bytes.NewReader(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = region.ReadChunk(0, 0)
+	if _, ok := err.(MalformedRegionError); !ok {
+		t.Errorf("Expected MalformedRegionError but instead got: %v", err)
+	}
+
+	// Empty region files are valid, and hold no chunks
+	region, err = ReadRegion(bytes.NewReader(nil))
+	if err != nil || region.HasChunk(0, 0) {
+		t.Errorf("Empty region incorrect: %v", err)
+	}
+}
+
+func TestWriteRegion(t *testing.T) {
+	directory, err := ioutil.TempDir("", "anvil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	fixture, err := ioutil.ReadFile("testdata/r.0.0.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(directory, "r.0.0.mca")
+	err = ioutil.WriteFile(path, fixture, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	region, err := OpenWritableRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A chunk packed across longs, and one that is not, are both changed
+	previousLocations := region.locations
+	for _, x := range []int32 {0, 1} {
+		chunk, err := region.ReadChunk(x, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sections, _ := chunk.GlobalSections(0x022E, fixturePalette)
+		sections[0][5] = 3
+		sections[4] = nil
+		sections[9] = make([]uint32, 4096)
+		sections[9][4095] = 20
+
+		chunk.SetGlobalSections(sections, 0x022E, fixturePalette)
+		err = region.WriteChunk(chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A chunk that was not in the region before
+	chunk := &Chunk { DataVersion: 2230, X: 5, Z: 5, Status: "full" }
+	var sections [SectionCount][]uint32
+	sections[15] = make([]uint32, 4096)
+	sections[15][0] = 1
+	chunk.SetGlobalSections(sections, 0x022E, fixturePalette)
+
+	err = region.WriteChunk(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	region.Close()
+
+	// The previous versions of the chunks are left where they were until the table no longer refers to them
+	for _, x := range []int32 {0, 1} {
+		if region.locations[x] >> 8 == previousLocations[x] >> 8 {
+			t.Errorf("Chunk %d written over itself", x)
+		}
+	}
+
+	region, err = OpenRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer region.Close()
+
+	for _, x := range []int32 {0, 1} {
+		chunk, err := region.ReadChunk(x, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sections, err := chunk.GlobalSections(0x022E, fixturePalette)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sections[0][5] != 3 || sections[0][4] != 2 || sections[0][256] != 1 || sections[4] != nil || sections[9][4095] != 20 {
+			t.Errorf("Chunk %d incorrect", x)
+		}
+
+		// The light of each section is kept
+		data, _ := region.ChunkData(x, 0)
+		var raw struct {
+			Level struct {
+				Sections []struct {
+					Y int8 `nbt:"Y"`
+					SkyLight []byte `nbt:"SkyLight"`
+				} `nbt:"Sections"`
+			} `nbt:"Level"`
+		}
+		nbt.Unmarshal(data, &raw)
+
+		if len(raw.Level.Sections) == 0 || raw.Level.Sections[0].Y != -1 || len(raw.Level.Sections[0].SkyLight) != 2048 {
+			t.Errorf("Light of chunk %d not kept", x)
+		}
+	}
+
+	chunk, err = region.ReadChunk(5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sections, _ = chunk.GlobalSections(0x022E, fixturePalette)
+	if sections[15][0] != 1 || sections[15][1] != 0 || sections[14] != nil {
+		t.Error("New chunk incorrect")
+	}
+
+	// The chunk that was not written is unchanged
+	chunk, err = region.ReadChunk(2, 0)
+	if err != nil || chunk.Status != "features" {
+		t.Errorf("Unchanged chunk incorrect: %v", err)
+	}
+}
+
+func TestWriteNewRegion(t *testing.T) {
+	directory, err := ioutil.TempDir("", "anvil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "r.-1.0.mca")
+	region, err := OpenWritableRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Written twice, so that the second version takes the sectors after the first
+	for _, status := range []string {"features", "full"} {
+		err = region.WriteChunk(&Chunk { DataVersion: 1976, X: -1, Z: 0, Status: status })
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	region.Close()
+
+	region, err = OpenRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer region.Close()
+
+	chunk, err := region.ReadChunk(-1, 0)
+	if err != nil || chunk == nil || chunk.Status != "full" || chunk.X != -1 {
+		t.Errorf("Chunk incorrect: %v %v", chunk, err)
+	}
+
+	if location := region.locations[locationIndex(-1, 0)]; location != 3 << 8 | 1 {
+		t.Errorf("Chunk written to sector %d", location >> 8)
+	}
+
+	err = region.WriteChunk(chunk)
+	if _, ok := err.(ReadOnlyRegionError); !ok {
+		t.Errorf("Expected ReadOnlyRegionError but instead got: %v", err)
+	}
+}
+
+func TestWriteChunkLength(t *testing.T) {
+	directory, err := ioutil.TempDir("", "anvil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "r.0.0.mca")
+	region, err := OpenWritableRegion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("chunk"), 100)
+	err = region.WriteChunkData(0, 0, data)
+	region.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var compressed bytes.Buffer
+	compressor := zlib.NewWriter(&compressed)
+	compressor.Write(data)
+	compressor.Close()
+
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The chunk takes the first sector after the sector table, padded to its end
+	if len(file) != 3 * SectorSize {
+		t.Fatalf("File length incorrect: %d", len(file))
+	}
+
+	header := file[2 * SectorSize:][:5]
+	if length := binary.BigEndian.Uint32(header); length != uint32(compressed.Len() + 1) || header[4] != compressionZlib {
+		t.Errorf("Header incorrect: %d %d, compressed length is %d", length, header[4], compressed.Len())
+	}
+}
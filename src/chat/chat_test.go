@@ -0,0 +1,105 @@
+package chat
+
+import "testing"
+import "reflect"
+import "encoding/json"
+
+func TestFromLegacy(t *testing.T) {
+	component := FromLegacy("§e§lHello, §r§aWorld§x!")
+
+	expected := TextComponent {
+		Extra: []TextComponent {
+			{ Text: "Hello, ", Color: "yellow", Bold: Bool(true) },
+			{ Text: "World§x!", Color: "green" },
+		},
+	}
+
+	if !reflect.DeepEqual(component, expected) {
+		t.Errorf("Output incorrect: %#v", component)
+	}
+
+	if plain := FromLegacy("No codes here"); !reflect.DeepEqual(plain, Text("No codes here")) {
+		t.Errorf("Output incorrect: %#v", plain)
+	}
+}
+
+func TestToLegacy(t *testing.T) {
+	iomap := []struct {
+		input TextComponent
+		output string
+	} {
+		{Text("plain"), "plain"},
+		{FromLegacy("§e§lHello, World!"), "§e§lHello, World!"},
+		{FromLegacy("§lBold§r plain"), "§r§lBold§r plain"},
+		{
+			TextComponent {
+				Text: "a",
+				Color: "red",
+				Extra: []TextComponent {
+					{ Text: "b" },
+					{ Text: "c", Italic: Bool(true) },
+					{ Translate: "chat.type.text", Color: "gold" },
+				},
+			},
+			"§cab§c§oc§6chat.type.text",
+		},
+	}
+
+	for i, mapping := range iomap {
+		output := mapping.input.ToLegacy()
+
+		if output != mapping.output {
+			t.Errorf("Output incorrect for mapping %d: %q", i, output)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	component := TextComponent {
+		Text: "Click",
+		Bold: Bool(false),
+		ClickEvent: &ClickEvent { Action: ClickRunCommand, Value: "/help" },
+		HoverEvent: &HoverEvent { Action: HoverShowText, Value: Text("Shows help") },
+		Extra: []TextComponent {
+			{ Translate: "chat.type.text", With: []TextComponent {Text("a")} },
+		},
+	}
+
+	data, err := json.Marshal(component)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"text":"Click","bold":false,"clickEvent":{"action":"run_command","value":"/help"},` +
+		`"hoverEvent":{"action":"show_text","value":{"text":"Shows help"}},` +
+		`"extra":[{"translate":"chat.type.text","with":[{"text":"a"}]}]}`
+
+	if string(data) != expected {
+		t.Errorf("Output incorrect: %s", data)
+	}
+
+	var parsed TextComponent
+	err = json.Unmarshal(data, &parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(parsed, component) {
+		t.Errorf("Round trip incorrect: %#v", parsed)
+	}
+}
+
+func TestUnmarshalShorthand(t *testing.T) {
+	var parsed TextComponent
+
+	err := json.Unmarshal([]byte(`"hello"`), &parsed)
+	if err != nil || !reflect.DeepEqual(parsed, Text("hello")) {
+		t.Errorf("String shorthand incorrect: %#v %v", parsed, err)
+	}
+
+	err = json.Unmarshal([]byte(`[{"text":"a","color":"red"},"b"]`), &parsed)
+	expected := TextComponent { Text: "a", Color: "red", Extra: []TextComponent {Text("b")} }
+	if err != nil || !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("Array shorthand incorrect: %#v %v", parsed, err)
+	}
+}
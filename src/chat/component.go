@@ -0,0 +1,224 @@
+// Package chat implements the JSON text component format used for chat
+// messages, server descriptions and disconnect reasons, along with
+// conversion to and from legacy § formatting codes.
+package chat
+
+import "encoding/json"
+
+type TextComponent struct {
+	Text string
+	// Translation key, used instead of Text when not empty.
+	Translate string
+	// Arguments substituted into the translation.
+	With []TextComponent
+	// A named color such as "yellow". Hexadecimal colors such as "#ffaa00" require 1.16 or newer.
+	Color string
+	// Formatting is inherited from the parent component when nil.
+	Bold *bool
+	Italic *bool
+	Underlined *bool
+	Strikethrough *bool
+	Obfuscated *bool
+	// Inserted into the chat input when the component is shift-clicked.
+	Insertion string
+	ClickEvent *ClickEvent
+	HoverEvent *HoverEvent
+	// Children inherit the formatting of this component.
+	Extra []TextComponent
+}
+
+type ClickEvent struct {
+	Action ClickAction
+	Value string
+}
+
+type ClickAction string
+const (
+	ClickOpenUrl ClickAction = "open_url"
+	ClickRunCommand ClickAction = "run_command"
+	ClickSuggestCommand ClickAction = "suggest_command"
+	ClickChangePage ClickAction = "change_page"
+)
+
+type HoverEvent struct {
+	Action HoverAction
+	Value TextComponent
+}
+
+type HoverAction string
+const (
+	HoverShowText HoverAction = "show_text"
+	HoverShowItem HoverAction = "show_item"
+	HoverShowEntity HoverAction = "show_entity"
+)
+
+// Creates a plain, unformatted component.
+func Text(text string) TextComponent {
+	return TextComponent {
+		Text: text,
+	}
+}
+
+// Helper for setting the formatting fields.
+func Bool(value bool) *bool {
+	return &value
+}
+
+// Concatenates the text of the component and its children, without any formatting.
+// Translated components are represented by their translation key.
+func (component TextComponent) PlainText() string {
+	text := component.Text
+	if component.Translate != "" {
+		text = component.Translate
+	}
+
+	for _, child := range component.Extra {
+		text += child.PlainText()
+	}
+
+	return text
+}
+
+///////////////////////////////////////
+// JSON
+///////////////////////////////////////
+
+type componentJson struct {
+	Text *string `json:"text,omitempty"`
+	Translate string `json:"translate,omitempty"`
+	With []TextComponent `json:"with,omitempty"`
+	Color string `json:"color,omitempty"`
+	Bold *bool `json:"bold,omitempty"`
+	Italic *bool `json:"italic,omitempty"`
+	Underlined *bool `json:"underlined,omitempty"`
+	Strikethrough *bool `json:"strikethrough,omitempty"`
+	Obfuscated *bool `json:"obfuscated,omitempty"`
+	Insertion string `json:"insertion,omitempty"`
+	ClickEvent *eventJson `json:"clickEvent,omitempty"`
+	HoverEvent *eventJson `json:"hoverEvent,omitempty"`
+	Extra []TextComponent `json:"extra,omitempty"`
+}
+
+type eventJson struct {
+	Action string `json:"action"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (component TextComponent) MarshalJSON() ([]byte, error) {
+	obj := componentJson {
+		Translate: component.Translate,
+		With: component.With,
+		Color: component.Color,
+		Bold: component.Bold,
+		Italic: component.Italic,
+		Underlined: component.Underlined,
+		Strikethrough: component.Strikethrough,
+		Obfuscated: component.Obfuscated,
+		Insertion: component.Insertion,
+		Extra: component.Extra,
+	}
+
+	if component.Translate == "" {
+		// Clients reject components that have neither text nor a translation key
+		text := component.Text
+		obj.Text = &text
+	}
+
+	if component.ClickEvent != nil {
+		value, err := json.Marshal(component.ClickEvent.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		obj.ClickEvent = &eventJson {
+			Action: string(component.ClickEvent.Action),
+			Value: value,
+		}
+	}
+
+	if component.HoverEvent != nil {
+		value, err := json.Marshal(component.HoverEvent.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		obj.HoverEvent = &eventJson {
+			Action: string(component.HoverEvent.Action),
+			Value: value,
+		}
+	}
+
+	return json.Marshal(obj)
+}
+
+// Accepts a component object, a plain string, or an array whose first element is the parent of the rest.
+func (component *TextComponent) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*component = Text(text)
+		return nil
+	}
+
+	var array []TextComponent
+	if json.Unmarshal(data, &array) == nil {
+		if len(array) == 0 {
+			*component = TextComponent {}
+			return nil
+		}
+
+		*component = array[0]
+		component.Extra = append(component.Extra, array[1:]...)
+		return nil
+	}
+
+	var obj componentJson
+	err := json.Unmarshal(data, &obj)
+	if err != nil {
+		return err
+	}
+
+	*component = TextComponent {
+		Translate: obj.Translate,
+		With: obj.With,
+		Color: obj.Color,
+		Bold: obj.Bold,
+		Italic: obj.Italic,
+		Underlined: obj.Underlined,
+		Strikethrough: obj.Strikethrough,
+		Obfuscated: obj.Obfuscated,
+		Insertion: obj.Insertion,
+		Extra: obj.Extra,
+	}
+
+	if obj.Text != nil {
+		component.Text = *obj.Text
+	}
+
+	if obj.ClickEvent != nil {
+		var value string
+		err = json.Unmarshal(obj.ClickEvent.Value, &value)
+		if err != nil {
+			return err
+		}
+
+		component.ClickEvent = &ClickEvent {
+			Action: ClickAction(obj.ClickEvent.Action),
+			Value: value,
+		}
+	}
+
+	if obj.HoverEvent != nil {
+		var value TextComponent
+		err = json.Unmarshal(obj.HoverEvent.Value, &value)
+		if err != nil {
+			return err
+		}
+
+		component.HoverEvent = &HoverEvent {
+			Action: HoverAction(obj.HoverEvent.Action),
+			Value: value,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package chat
+
+import "strings"
+
+// Legacy formatting codes are a section sign followed by one of these characters.
+const sectionSign = '§'
+
+var legacyColors = map[rune]string {
+	'0': "black",
+	'1': "dark_blue",
+	'2': "dark_green",
+	'3': "dark_aqua",
+	'4': "dark_red",
+	'5': "dark_purple",
+	'6': "gold",
+	'7': "gray",
+	'8': "dark_gray",
+	'9': "blue",
+	'a': "green",
+	'b': "aqua",
+	'c': "red",
+	'd': "light_purple",
+	'e': "yellow",
+	'f': "white",
+}
+
+var legacyColorCodes = make(map[string]rune)
+
+func init() {
+	for code, color := range legacyColors {
+		legacyColorCodes[color] = code
+	}
+}
+
+// Flattened formatting of a piece of text.
+type legacyStyle struct {
+	color string
+	obfuscated bool
+	bold bool
+	strikethrough bool
+	underlined bool
+	italic bool
+}
+
+func (style legacyStyle) applyTo(component *TextComponent) {
+	component.Color = style.color
+
+	if style.obfuscated {
+		component.Obfuscated = Bool(true)
+	}
+	if style.bold {
+		component.Bold = Bool(true)
+	}
+	if style.strikethrough {
+		component.Strikethrough = Bool(true)
+	}
+	if style.underlined {
+		component.Underlined = Bool(true)
+	}
+	if style.italic {
+		component.Italic = Bool(true)
+	}
+}
+
+func (style legacyStyle) codes() string {
+	var builder strings.Builder
+
+	if code, ok := legacyColorCodes[style.color]; ok {
+		// Color codes also reset any formatting
+		builder.WriteRune(sectionSign)
+		builder.WriteRune(code)
+	} else {
+		builder.WriteString("§r")
+	}
+
+	if style.obfuscated {
+		builder.WriteString("§k")
+	}
+	if style.bold {
+		builder.WriteString("§l")
+	}
+	if style.strikethrough {
+		builder.WriteString("§m")
+	}
+	if style.underlined {
+		builder.WriteString("§n")
+	}
+	if style.italic {
+		builder.WriteString("§o")
+	}
+
+	return builder.String()
+}
+
+// Converts text containing legacy formatting codes, such as "§e§lHello", into a component.
+// Unrecognized codes are kept as literal text.
+func FromLegacy(text string) TextComponent {
+	if !strings.ContainsRune(text, sectionSign) {
+		return Text(text)
+	}
+
+	root := Text("")
+	var style legacyStyle
+	var segment strings.Builder
+
+	flush := func() {
+		if segment.Len() == 0 {
+			return
+		}
+
+		child := Text(segment.String())
+		style.applyTo(&child)
+		root.Extra = append(root.Extra, child)
+		segment.Reset()
+	}
+
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != sectionSign || i + 1 >= len(runes) {
+			segment.WriteRune(runes[i])
+			continue
+		}
+
+		code := []rune(strings.ToLower(string(runes[i + 1])))[0]
+		nextStyle := style
+
+		if color, ok := legacyColors[code]; ok {
+			nextStyle = legacyStyle { color: color }
+		} else {
+			switch code {
+			case 'k':
+				nextStyle.obfuscated = true
+			case 'l':
+				nextStyle.bold = true
+			case 'm':
+				nextStyle.strikethrough = true
+			case 'n':
+				nextStyle.underlined = true
+			case 'o':
+				nextStyle.italic = true
+			case 'r':
+				nextStyle = legacyStyle {}
+			default:
+				segment.WriteRune(runes[i])
+				continue
+			}
+		}
+
+		flush()
+		style = nextStyle
+		i++
+	}
+
+	flush()
+	return root
+}
+
+// Converts the component into text with legacy formatting codes, as understood by clients before 1.7.
+// Click and hover events cannot be represented and are dropped, as are hexadecimal colors.
+func (component TextComponent) ToLegacy() string {
+	var builder strings.Builder
+	var current legacyStyle
+	component.writeLegacy(&builder, legacyStyle {}, &current)
+	return builder.String()
+}
+
+func (component TextComponent) writeLegacy(builder *strings.Builder, inherited legacyStyle, current *legacyStyle) {
+	style := inherited
+
+	if component.Color != "" {
+		style.color = component.Color
+	}
+	if component.Obfuscated != nil {
+		style.obfuscated = *component.Obfuscated
+	}
+	if component.Bold != nil {
+		style.bold = *component.Bold
+	}
+	if component.Strikethrough != nil {
+		style.strikethrough = *component.Strikethrough
+	}
+	if component.Underlined != nil {
+		style.underlined = *component.Underlined
+	}
+	if component.Italic != nil {
+		style.italic = *component.Italic
+	}
+
+	text := component.Text
+	if component.Translate != "" {
+		text = component.Translate
+	}
+
+	if text != "" {
+		if style != *current {
+			builder.WriteString(style.codes())
+			*current = style
+		}
+
+		builder.WriteString(text)
+	}
+
+	for _, child := range component.Extra {
+		child.writeLegacy(builder, style, current)
+	}
+}
@@ -0,0 +1,148 @@
+package javaio
+
+import "testing"
+import "bufio"
+import "bytes"
+import "io/ioutil"
+import "compress/zlib"
+
+// Packet id 0x01 followed by a ping payload of 0x0102030405060708
+var pingBody = []byte {0x01, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+var compressedStatusContext = ClientContext {
+	Protocol: 0x0286,
+	State: StateStatus,
+	CompressionThreshold: 256,
+}
+
+func zlibCompress(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestParseCompressedBelowThreshold(t *testing.T) {
+	frame := []byte {0x0a, 0x00}
+	frame = append(frame, pingBody...)
+
+	result, err := ParseServerboundPacketCompressed(bufio.NewReader(bytes.NewReader(frame)), compressedStatusContext, StateStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ping, ok := result.(Packet_0051_Ping)
+	if !ok {
+		t.Fatalf("Expected Packet_0051_Ping but instead got: %#v", result)
+	}
+
+	if ping.Payload != 0x0102030405060708 {
+		t.Errorf("Payload incorrect: %x", ping.Payload)
+	}
+}
+
+func TestParseCompressedAboveThreshold(t *testing.T) {
+	compressed := zlibCompress(t, pingBody)
+	frame := []byte {byte(1 + len(compressed)), byte(len(pingBody))}
+	frame = append(frame, compressed...)
+
+	result, err := ParseServerboundPacketCompressed(bufio.NewReader(bytes.NewReader(frame)), compressedStatusContext, StateStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ping, ok := result.(Packet_0051_Ping)
+	if !ok {
+		t.Fatalf("Expected Packet_0051_Ping but instead got: %#v", result)
+	}
+
+	if ping.Payload != 0x0102030405060708 {
+		t.Errorf("Payload incorrect: %x", ping.Payload)
+	}
+}
+
+func TestParseCompressedMalformed(t *testing.T) {
+	compressed := zlibCompress(t, pingBody)
+
+	iemap := [][]byte {
+		append([]byte {byte(1 + len(compressed)), byte(len(pingBody) + 1)}, compressed...), // Declared length too long
+		append([]byte {byte(1 + len(compressed)), byte(len(pingBody))}, compressed[:len(compressed) - 4]...), // Frame ended abruptly
+		{0x03, 0x09, 0x00, 0x00}, // Not zlib data
+	}
+
+	for i, mappingInput := range iemap {
+		_, err := ParseServerboundPacketCompressed(bufio.NewReader(bytes.NewReader(mappingInput)), compressedStatusContext, StateStatus)
+
+		if _, ok := err.(MalformedPacketError); !ok {
+			t.Errorf("Expected MalformedPacketError for mapping %d but instead got: %v", i, err)
+		}
+	}
+}
+
+func emitCompressedPong(t *testing.T, threshold int32) []byte {
+	ctx := compressedStatusContext
+	ctx.CompressionThreshold = threshold
+
+	var buf bytes.Buffer
+	EmitClientboundPacketCompressed(Packet_0051_Pong { Payload: 0x0102030405060708 }, ctx, bufio.NewWriter(&buf))
+	return buf.Bytes()
+}
+
+func TestEmitCompressedBelowThreshold(t *testing.T) {
+	output := emitCompressedPong(t, 256)
+	expected := append([]byte {0x0a, 0x00}, pingBody...)
+
+	if !bytes.Equal(output, expected) {
+		t.Errorf("Output incorrect: %x", output)
+	}
+}
+
+func TestEmitCompressedAboveThreshold(t *testing.T) {
+	output := emitCompressedPong(t, 0)
+
+	if int(output[0]) != len(output) - 1 {
+		t.Fatalf("Packet length incorrect: %x", output)
+	}
+
+	if int(output[1]) != len(pingBody) {
+		t.Fatalf("Data length incorrect: %x", output)
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(output[2:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(body, pingBody) {
+		t.Errorf("Decompressed body incorrect: %x", body)
+	}
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	for _, threshold := range []int32 {0, 1, 9, 10, 256} {
+		output := emitCompressedPong(t, threshold)
+
+		result, err := ParseServerboundPacketCompressed(bufio.NewReader(bytes.NewReader(output)), compressedStatusContext, StateStatus)
+		if err != nil {
+			t.Errorf("Threshold %d: %v", threshold, err)
+			continue
+		}
+
+		// Pong and ping share the same id and layout, so the emitted pong parses as a ping
+		if ping, ok := result.(Packet_0051_Ping); !ok || ping.Payload != 0x0102030405060708 {
+			t.Errorf("Threshold %d: output incorrect: %#v", threshold, result)
+		}
+	}
+}
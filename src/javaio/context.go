@@ -3,9 +3,13 @@ package javaio
 type ClientContext struct {
 	Protocol uint
 	State State
+	// Packets whose uncompressed size reaches this threshold are sent zlib-compressed.
+	// A negative value means compression has not been enabled for this connection.
+	CompressionThreshold int32
 }
 
 var InitialClientContext = ClientContext {
 	Protocol: 0,
 	State: StateDeterminingProtocol,
+	CompressionThreshold: -1,
 }
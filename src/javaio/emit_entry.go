@@ -1,24 +1,243 @@
 package javaio
 
+import "fmt"
 import "bytes"
 import "bufio"
+import "compress/zlib"
 
-/**  Serverbound packet emission is not implemented.  **/
+// Serverbound
+
+func EmitServerboundPacketUncompressed(packet interface{}, ctx ClientContext, output *bufio.Writer) error {
+	if ctx.State == StatePreNetty {
+		data, ok := packet.(Packet_002E_StatusRequest)
+		if !ok {
+			return InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted before the netty rewrite", packet) }
+		}
+
+		err := Write_002E_StatusRequest(data, output)
+		if err != nil {
+			return err
+		}
+
+		return output.Flush()
+	} else if ctx.State == StateVeryPreNetty {
+		data, ok := packet.(VeryLegacyStatusRequest)
+		if !ok {
+			return InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted before the netty rewrite", packet) }
+		}
+
+		err := WriteVeryLegacyStatusRequest(data, output)
+		if err != nil {
+			return err
+		}
+
+		return output.Flush()
+	}
+
+	body, err := encodeServerboundPacket(packet, ctx)
+	if err != nil {
+		return err
+	}
+
+	err = writeUncompressedFrame(body, output)
+	if err != nil {
+		return err
+	}
+
+	return output.Flush()
+}
+
+func EmitServerboundPacketCompressed(packet interface{}, ctx ClientContext, output *bufio.Writer) error {
+	if ctx.State == StatePreNetty || ctx.State == StateVeryPreNetty {
+		return InvalidPacketForStateError { "Packet compression is not available before the netty rewrite" }
+	}
+
+	body, err := encodeServerboundPacket(packet, ctx)
+	if err != nil {
+		return err
+	}
+
+	err = writeCompressedFrame(body, ctx.CompressionThreshold, output)
+	if err != nil {
+		return err
+	}
+
+	return output.Flush()
+}
+
+// Encodes the packet id followed by the packet data, without any framing.
+func encodeServerboundPacket(packet interface{}, ctx ClientContext) ([]byte, error) {
+	var kind PacketKind
+	var err error
+	var packetIdBuf bytes.Buffer
+	var dataBuf bytes.Buffer
+	packetIdWriter := bufio.NewWriter(&packetIdBuf)
+	dataWriter := bufio.NewWriter(&dataBuf)
+
+	switch ctx.State {
+	case StateHandshaking:
+		switch packet := packet.(type) {
+		case Handshake:
+			kind = PacketKindHandshake
+			err = EmitHandshake(packet, dataWriter)
+		default:
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in handshaking state", packet) }
+		}
+	case StateStatus:
+		switch packet := packet.(type) {
+		case Packet_0051_StatusRequest:
+			kind = PacketKindStatusRequest
+			err = Write_0051_StatusRequest(packet, dataWriter)
+		case Packet_0051_Ping:
+			kind = PacketKindPing
+			err = Write_0051_Ping(packet, dataWriter)
+		default:
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in status state", packet) }
+		}
+	case StateLogin:
+		switch packet := packet.(type) {
+		case LoginStart:
+			kind = PacketKindLoginStart
+			err = EmitLoginStart(packet, dataWriter)
+		case EncryptionResponse:
+			kind = PacketKindEncryptionResponse
+			err = EmitEncryptionResponse(packet, dataWriter)
+		default:
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in login state", packet) }
+		}
+	case StatePlay:
+		switch packet := packet.(type) {
+		case KeepAlive:
+			kind = PacketKindKeepAlive
+			err = WriteKeepAlive(packet, dataWriter)
+		case Packet_PlayerPosSb:
+			kind = PacketKindPlayerPosition
+			err = Write_PlayerPosSb(packet, dataWriter)
+		case Packet_PlayerLookSb:
+			kind = PacketKindPlayerLook
+			err = Write_PlayerLookSb(packet, dataWriter)
+		case Packet_PlayerPosAndLookSb:
+			kind = PacketKindPlayerPositionAndLook
+			err = Write_PlayerPosAndLookSb(packet, dataWriter)
+		case ChatMessageSb:
+			kind = PacketKindChatMessage
+			err = WriteChatMessageSb(packet, dataWriter)
+		case TabCompleteSb:
+			kind = PacketKindTabComplete
+			err = WriteTabCompleteSb(packet, dataWriter)
+		case TeleportConfirm:
+			kind = PacketKindTeleportConfirm
+			err = WriteTeleportConfirm(packet, dataWriter)
+		case ClientSettings:
+			kind = PacketKindClientSettings
+			err = WriteClientSettings(packet, dataWriter)
+		case PlayerAbilitiesSb:
+			kind = PacketKindPlayerAbilities
+			err = WritePlayerAbilitiesSb(packet, dataWriter)
+		case EntityAction:
+			kind = PacketKindEntityAction
+			err = WriteEntityAction(packet, dataWriter)
+		case HeldItemChange:
+			kind = PacketKindHeldItemChange
+			err = WriteHeldItemChange(packet, dataWriter)
+		default:
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in play state (likely because not implemented)", packet) }
+		}
+	default:
+		err = InvalidPacketForStateError { "State does not match one of non-invalid predefined enum types" }
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	packetId, err := LookupPacketId(ctx.Protocol, ctx.State, DirectionServerbound, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WriteVarInt(packetId, packetIdWriter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dataWriter.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	err = packetIdWriter.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(packetIdBuf.Bytes(), dataBuf.Bytes()...), nil
+}
 
 // Clientbound
 
-func EmitClientboundPacketUncompressed(packet interface{}, ctx ClientContext, output *bufio.Writer) {
+func EmitClientboundPacketUncompressed(packet interface{}, ctx ClientContext, output *bufio.Writer) error {
 	if ctx.State == StatePreNetty {
-		Write_002E_StatusResponse(packet.(Packet_002E_StatusResponse), output)
-		output.Flush()
-		return
+		data, ok := packet.(Packet_002E_StatusResponse)
+		if !ok {
+			return InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted before the netty rewrite", packet) }
+		}
+
+		err := Write_002E_StatusResponse(data, output)
+		if err != nil {
+			return err
+		}
+
+		return output.Flush()
 	} else if ctx.State == StateVeryPreNetty {
-		WriteVeryLegacyStatusResponse(packet.(VeryLegacyStatusResponse), output)
-		output.Flush()
-		return
+		data, ok := packet.(VeryLegacyStatusResponse)
+		if !ok {
+			return InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted before the netty rewrite", packet) }
+		}
+
+		err := WriteVeryLegacyStatusResponse(data, output)
+		if err != nil {
+			return err
+		}
+
+		return output.Flush()
+	}
+
+	body, err := encodeClientboundPacket(packet, ctx)
+	if err != nil {
+		return err
+	}
+
+	err = writeUncompressedFrame(body, output)
+	if err != nil {
+		return err
+	}
+
+	return output.Flush()
+}
+
+func EmitClientboundPacketCompressed(packet interface{}, ctx ClientContext, output *bufio.Writer) error {
+	if ctx.State == StatePreNetty || ctx.State == StateVeryPreNetty {
+		return InvalidPacketForStateError { "Packet compression is not available before the netty rewrite" }
 	}
-	
-	var packetId int32 = -1
+
+	body, err := encodeClientboundPacket(packet, ctx)
+	if err != nil {
+		return err
+	}
+
+	err = writeCompressedFrame(body, ctx.CompressionThreshold, output)
+	if err != nil {
+		return err
+	}
+
+	return output.Flush()
+}
+
+// Encodes the packet id followed by the packet data, without any framing.
+func encodeClientboundPacket(packet interface{}, ctx ClientContext) ([]byte, error) {
+	var kind PacketKind
+	var err error
 	var packetIdBuf bytes.Buffer
 	var dataBuf bytes.Buffer
 	packetIdWriter := bufio.NewWriter(&packetIdBuf)
@@ -26,82 +245,210 @@ func EmitClientboundPacketUncompressed(packet interface{}, ctx ClientContext, ou
 
 	switch ctx.State {
 	case StateHandshaking:
-		panic("Packet cannot be emitted in handshaking state")
+		err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in handshaking state", packet) }
 	case StateStatus:
 		switch packet := packet.(type) {
 		case Packet_0051_StatusResponse:
-			packetId = 0x00
-			Write_0051_StatusResponse(packet, dataWriter)
+			kind = PacketKindStatusResponse
+			err = Write_0051_StatusResponse(packet, dataWriter)
 		case Packet_0051_Pong:
-			packetId = 0x01
-			Write_0051_Pong(packet, dataWriter)
+			kind = PacketKindPong
+			err = Write_0051_Pong(packet, dataWriter)
 		default:
-			panic("Packet cannot be emitted in status state")
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in status state", packet) }
 		}
 	case StateLogin:
 		switch packet := packet.(type) {
+		case Disconnect:
+			kind = PacketKindDisconnect
+			err = WriteDisconnect(packet, dataWriter)
+		case EncryptionRequest:
+			kind = PacketKindEncryptionRequest
+			err = EmitEncryptionRequest(packet, dataWriter)
 		case LoginSuccess:
-			packetId = 0x02
-			EmitLoginSuccess(packet, dataWriter)	
+			kind = PacketKindLoginSuccess
+			err = EmitLoginSuccess(packet, dataWriter)
+		case SetCompression:
+			kind = PacketKindSetCompression
+			err = EmitSetCompression(packet, dataWriter)
 		default:
-			panic("Packet cannot be emitted in login state")
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in login state", packet) }
 		}
 	case StatePlay:
 		switch packet := packet.(type) {
 		case KeepAlive:
-			packetId = int32(PacketId_KeepAlive(ctx.Protocol))
-			WriteKeepAlive(packet, dataWriter)
+			kind = PacketKindKeepAlive
+			err = WriteKeepAlive(packet, dataWriter)
+		case Disconnect:
+			kind = PacketKindDisconnect
+			err = WriteDisconnect(packet, dataWriter)
 		case JoinGame:
-			packetId = int32(PacketId_JoinGame(ctx.Protocol))
-			WriteJoinGame(packet, ctx, dataWriter)	
+			kind = PacketKindJoinGame
+			err = WriteJoinGame(packet, ctx, dataWriter)
 		case CompassPosition:
-			packetId = int32(PacketId_CompassPosition(ctx.Protocol))
-			WriteCompassPosition(packet, dataWriter)
+			kind = PacketKindCompassPosition
+			err = WriteCompassPosition(packet, dataWriter)
+		case BlockChange:
+			kind = PacketKindBlockChange
+			err = WriteBlockChange(packet, dataWriter)
 		case PlayerPositionAndLook:
-			packetId = int32(PacketId_PlayerPositionAndLook(ctx.Protocol))
-			WritePlayerPositionAndLook(packet, dataWriter)
+			kind = PacketKindPlayerPositionAndLook
+			err = WritePlayerPositionAndLook(packet, dataWriter)
 		case ChunkData:
-			packetId = int32(PacketId_ChunkData(ctx.Protocol))
-			WriteChunkData(packet, ctx, dataWriter)
+			kind = PacketKindChunkData
+			err = WriteChunkData(packet, ctx, dataWriter)
 		case PlayerInfoAdd:
-			packetId = int32(PacketId_PlayerInfo(ctx.Protocol))
-			WritePlayerInfoAdd(packet, dataWriter)
+			kind = PacketKindPlayerInfo
+			err = WritePlayerInfoAdd(packet, dataWriter)
+		case PlayerInfoUpdateLatency:
+			kind = PacketKindPlayerInfo
+			err = WritePlayerInfoUpdateLatency(packet, dataWriter)
+		case PlayerInfoRemove:
+			kind = PacketKindPlayerInfo
+			err = WritePlayerInfoRemove(packet, dataWriter)
 		case Packet_SpawnPlayer:
-			packetId = int32(PacketId_SpawnPlayer(ctx.Protocol))
-			Write_SpawnPlayer(packet, ctx, dataWriter)
+			kind = PacketKindSpawnPlayer
+			err = Write_SpawnPlayer(packet, ctx, dataWriter)
+		case SpawnLivingEntity:
+			kind = PacketKindSpawnLivingEntity
+			err = WriteSpawnLivingEntity(packet, ctx, dataWriter)
 		case Packet_EntityTranslate:
-			packetId = int32(PacketId_EntityTranslate(ctx.Protocol))
-			Write_EntityTranslate(packet, dataWriter)
+			kind = PacketKindEntityTranslate
+			err = Write_EntityTranslate(packet, dataWriter)
 		case Packet_EntityVelocity:
-			packetId = int32(PacketId_EntityVelocity(ctx.Protocol))
-			Write_EntityVelocity(packet, dataWriter)
+			kind = PacketKindEntityVelocity
+			err = Write_EntityVelocity(packet, dataWriter)
+		case UpdateLight:
+			kind = PacketKindUpdateLight
+			err = WriteUpdateLight(packet, dataWriter)
+		case DestroyEntities:
+			kind = PacketKindDestroyEntities
+			err = WriteDestroyEntities(packet, dataWriter)
+		case ChatMessage:
+			kind = PacketKindChatMessage
+			err = WriteChatMessage(packet, dataWriter)
+		case TabComplete:
+			kind = PacketKindTabComplete
+			err = WriteTabComplete(packet, dataWriter)
+		case DeclareCommands:
+			kind = PacketKindDeclareCommands
+			err = WriteDeclareCommands(packet, dataWriter)
+		case EntityRelativeMove:
+			kind = PacketKindEntityRelativeMove
+			err = WriteEntityRelativeMove(packet, dataWriter)
+		case EntityLook:
+			kind = PacketKindEntityLook
+			err = WriteEntityLook(packet, dataWriter)
+		case EntityHeadLook:
+			kind = PacketKindEntityHeadLook
+			err = WriteEntityHeadLook(packet, dataWriter)
+		case EntityTeleport:
+			kind = PacketKindEntityTeleport
+			err = WriteEntityTeleport(packet, dataWriter)
+		case UnloadChunk:
+			kind = PacketKindUnloadChunk
+			err = WriteUnloadChunk(packet, dataWriter)
+		case UpdateViewPosition:
+			kind = PacketKindUpdateViewPosition
+			err = WriteUpdateViewPosition(packet, dataWriter)
+		case UpdateViewDistance:
+			kind = PacketKindUpdateViewDistance
+			err = WriteUpdateViewDistance(packet, dataWriter)
 		default:
-			panic("Packet cannot be emitted in play state (likely because not implemented)")
+			err = InvalidPacketForStateError { fmt.Sprintf("%T cannot be emitted in play state (likely because not implemented)", packet) }
 		}
 	default:
-		panic("State does not match one of non-invalid predefined enum types")
+		err = InvalidPacketForStateError { "State does not match one of non-invalid predefined enum types" }
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	packetId, err := LookupPacketId(ctx.Protocol, ctx.State, DirectionClientbound, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WriteVarInt(packetId, packetIdWriter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dataWriter.Flush()
+	if err != nil {
+		return nil, err
 	}
-	
-	if packetId == -1 {
-		panic("Internal package bug: packet id was not set while preparing to emit a packet")
+
+	err = packetIdWriter.Flush()
+	if err != nil {
+		return nil, err
 	}
 
-	WriteVarInt(packetId, packetIdWriter)
-	dataWriter.Flush()
-	packetIdWriter.Flush()
-	length := packetIdBuf.Len() + dataBuf.Len()
+	return append(packetIdBuf.Bytes(), dataBuf.Bytes()...), nil
+}
+
+func writeUncompressedFrame(body []byte, output *bufio.Writer) (err error) {
+	length := len(body)
 	lengthInt32 := int32(length)
 
 	if length > int(lengthInt32) {
-		panic("Emitted packet data was too large to hold its size in VarInt")
+		err = FieldOutOfRangeError { "Emitted packet data was too large to hold its size in VarInt" }
+		return
 	}
 
-	WriteVarInt(lengthInt32, output)
-	output.Write(packetIdBuf.Bytes())
-	output.Write(dataBuf.Bytes())
-	output.Flush()
+	err = WriteVarInt(lengthInt32, output)
+	if err != nil {
+		return
+	}
+
+	_, err = output.Write(body)
+	return
 }
 
-func EmitClientboundPacketCompressed(packet interface{}, ctx ClientContext, output *bufio.Writer) {
-	panic("EmicClientboundPacketCompressed not implemented")
+func writeCompressedFrame(body []byte, threshold int32, output *bufio.Writer) (err error) {
+	var frameBuf bytes.Buffer
+	frameWriter := bufio.NewWriter(&frameBuf)
+
+	if threshold < 0 || len(body) < int(threshold) {
+		// A data length of zero tells the receiver that the body is not compressed
+		err = WriteVarInt(0, frameWriter)
+		if err != nil {
+			return
+		}
+
+		_, err = frameWriter.Write(body)
+		if err != nil {
+			return
+		}
+	} else {
+		err = WriteVarInt(int32(len(body)), frameWriter) // potentially unsafe cast
+		if err != nil {
+			return
+		}
+
+		err = frameWriter.Flush()
+		if err != nil {
+			return
+		}
+
+		zlibWriter := zlib.NewWriter(&frameBuf)
+		_, err = zlibWriter.Write(body)
+		if err != nil {
+			return
+		}
+
+		err = zlibWriter.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	err = frameWriter.Flush()
+	if err != nil {
+		return
+	}
+
+	err = writeUncompressedFrame(frameBuf.Bytes(), output)
+	return
 }
@@ -0,0 +1,92 @@
+package javaio
+
+import "bufio"
+import "bytes"
+import "errors"
+import "strings"
+import "testing"
+
+func TestEmitInvalidPacketForState(t *testing.T) {
+	iomap := []struct {
+		state State
+		packet interface{}
+	} {
+		{StateHandshaking, KeepAlive { Payload: 1 }},
+		{StateStatus, LoginSuccess { Username: "Notch" }},
+		{StateLogin, JoinGame { Gamemode: GamemodeCreative, Dimension: DimensionOverworld }},
+		{StatePlay, Packet_0051_Pong { Payload: 1 }},
+		{StatePreNetty, KeepAlive { Payload: 1 }},
+		{StateVeryPreNetty, KeepAlive { Payload: 1 }},
+	}
+
+	for i, mapping := range iomap {
+		var buf bytes.Buffer
+		ctx := ClientContext { State: mapping.state, Protocol: 0x022E, CompressionThreshold: -1 }
+
+		err := EmitClientboundPacketUncompressed(mapping.packet, ctx, bufio.NewWriter(&buf))
+		if _, ok := err.(InvalidPacketForStateError); !ok {
+			t.Errorf("Expected InvalidPacketForStateError for mapping %d but instead got: %v", i, err)
+		}
+
+		if buf.Len() != 0 {
+			t.Errorf("Output written for mapping %d", i)
+		}
+	}
+
+	ctx := ClientContext { State: StatePreNetty, Protocol: 0x002E }
+	err := EmitClientboundPacketCompressed(Packet_002E_StatusResponse {}, ctx, bufio.NewWriter(&bytes.Buffer {}))
+	if _, ok := err.(InvalidPacketForStateError); !ok {
+		t.Errorf("Expected InvalidPacketForStateError for compressed pre-netty packet but instead got: %v", err)
+	}
+}
+
+func TestEmitFieldOutOfRange(t *testing.T) {
+	iomap := []struct {
+		state State
+		packet interface{}
+	} {
+		{StateLogin, LoginSuccess { Username: strings.Repeat("a", 17) }},
+		{StatePlay, JoinGame { Gamemode: GamemodeCreative, Dimension: DimensionOverworld, ViewDistance: 33 }},
+		{StatePlay, JoinGame { Dimension: DimensionOverworld }},
+		{StatePlay, ChunkData { Sections: [][]uint32 {make([]uint32, 100)} }},
+		{StatePlay, UpdateLight { SkyLight: [LightSectionCount][]byte {1: make([]byte, 10)} }},
+	}
+
+	for i, mapping := range iomap {
+		ctx := ClientContext { State: mapping.state, Protocol: 0x022E, CompressionThreshold: -1 }
+
+		err := EmitClientboundPacketUncompressed(mapping.packet, ctx, bufio.NewWriter(&bytes.Buffer {}))
+		if _, ok := err.(FieldOutOfRangeError); !ok {
+			t.Errorf("Expected FieldOutOfRangeError for mapping %d but instead got: %v", i, err)
+		}
+	}
+
+	err := EmitLoginStart(LoginStart { ClientsideUsername: strings.Repeat("é", 16) }, bufio.NewWriter(&bytes.Buffer {}))
+	if err != nil {
+		t.Errorf("Username length should be counted in characters: %v", err)
+	}
+}
+
+type failingWriter struct {}
+
+var errWriteFailed = errors.New("write failed")
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWriteFailed
+}
+
+func TestEmitSurfacesWriteErrors(t *testing.T) {
+	ctx := ClientContext { State: StatePlay, Protocol: 0x022E, CompressionThreshold: 256 }
+	packet := ChunkData { X: 1, Z: 2, IsNew: true, Sections: [][]uint32 {testBlocks()} }
+
+	// The buffer fills up before the packet is complete, so the error surfaces while writing
+	output := bufio.NewWriterSize(failingWriter {}, 16)
+
+	if err := EmitClientboundPacketCompressed(packet, ctx, output); err != errWriteFailed {
+		t.Errorf("Expected write error but instead got: %v", err)
+	}
+
+	if err := EmitClientboundPacketUncompressed(KeepAlive { Payload: 1 }, ctx, bufio.NewWriter(failingWriter {})); err != errWriteFailed {
+		t.Errorf("Expected write error from flush but instead got: %v", err)
+	}
+}
@@ -1,4 +1,40 @@
 package javaio
 
-/**  Serverbound packet emission will not be available for the foreseeable future unless contributed by others.  **/
+import "bufio"
+
 /**  There are no clientbound packets in this state.  **/
+/**  All serverbound packets in this state are implemented.  **/
+
+// Serverbound
+
+func EmitHandshake(handshake Handshake, result *bufio.Writer) (err error) {
+	var nextStateId int32
+
+	switch handshake.NextState {
+	case StateStatus:
+		nextStateId = 1
+	case StateLogin:
+		nextStateId = 2
+	default:
+		err = FieldOutOfRangeError { "Next state of Handshake must be either status or login" }
+		return
+	}
+
+	err = WriteVarInt(handshake.Protocol, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteString(handshake.ServerAddress, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteUShort(handshake.ServerPort, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(nextStateId, result)
+	return
+}
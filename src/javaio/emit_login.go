@@ -1,16 +1,68 @@
 package javaio
 
 import "bufio"
-
-/**  Serverbound packet emission is not implemented.  **/
+import "unicode/utf8"
 
 // Clientbound
 
-func EmitLoginSuccess(loginSuccess LoginSuccess, result *bufio.Writer) {
-	if len(loginSuccess.Username) > 16 {
-		panic("Username of LoginSuccess is too long (must not be over 16 runes)")
+func EmitLoginSuccess(loginSuccess LoginSuccess, result *bufio.Writer) (err error) {
+	if utf8.RuneCountInString(loginSuccess.Username) > 16 {
+		err = FieldOutOfRangeError { "Username of LoginSuccess is too long (must not be over 16 runes)" }
+		return
+	}
+
+	err = WriteString(loginSuccess.Uuid.String(), result)
+	if err != nil {
+		return
+	}
+
+	err = WriteString(loginSuccess.Username, result)
+	return
+}
+
+func EmitSetCompression(setCompression SetCompression, result *bufio.Writer) (err error) {
+	err = WriteVarInt(setCompression.Threshold, result)
+	return
+}
+
+func EmitEncryptionRequest(encryptionRequest EncryptionRequest, result *bufio.Writer) (err error) {
+	if utf8.RuneCountInString(encryptionRequest.ServerId) > 20 {
+		err = FieldOutOfRangeError { "Server id of EncryptionRequest is too long (must not be over 20 runes)" }
+		return
+	}
+
+	err = WriteString(encryptionRequest.ServerId, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteByteArray(encryptionRequest.PublicKey, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteByteArray(encryptionRequest.VerifyToken, result)
+	return
+}
+
+// Serverbound
+
+func EmitLoginStart(loginStart LoginStart, result *bufio.Writer) (err error) {
+	if utf8.RuneCountInString(loginStart.ClientsideUsername) > 16 {
+		err = FieldOutOfRangeError { "Username of LoginStart is too long (must not be over 16 runes)" }
+		return
+	}
+
+	err = WriteString(loginStart.ClientsideUsername, result)
+	return
+}
+
+func EmitEncryptionResponse(encryptionResponse EncryptionResponse, result *bufio.Writer) (err error) {
+	err = WriteByteArray(encryptionResponse.SharedSecret, result)
+	if err != nil {
+		return
 	}
 
-	WriteString(loginSuccess.Uuid.String(), result)
-	WriteString(loginSuccess.Username, result)
+	err = WriteByteArray(encryptionResponse.VerifyToken, result)
+	return
 }
@@ -0,0 +1,105 @@
+package javaio
+
+import "bufio"
+import "crypto/aes"
+import "crypto/cipher"
+
+// Once encryption is enabled, both directions of the stream are encrypted with
+// AES/CFB8, using the shared secret as both the key and the initial vector.
+
+func NewEncryptedReader(underlyingReader *bufio.Reader, sharedSecret []byte) (*bufio.Reader, error) {
+	block, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return bufio.NewReader(cipher.StreamReader {
+		S: NewCFB8Decrypter(block, sharedSecret),
+		R: underlyingReader,
+	}), nil
+}
+
+func NewEncryptedWriter(underlyingWriter *bufio.Writer, sharedSecret []byte) (*bufio.Writer, error) {
+	block, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return bufio.NewWriter(&encryptedWriter {
+		stream: NewCFB8Encrypter(block, sharedSecret),
+		underlyingWriter: underlyingWriter,
+	}), nil
+}
+
+type encryptedWriter struct {
+	stream cipher.Stream
+	underlyingWriter *bufio.Writer
+}
+
+func (w *encryptedWriter) Write(buf []byte) (n int, err error) {
+	encrypted := make([]byte, len(buf))
+	w.stream.XORKeyStream(encrypted, buf)
+
+	n, err = w.underlyingWriter.Write(encrypted)
+	if err != nil {
+		return
+	}
+
+	// The outer writer is only flushed when a packet is complete, so the packet must reach the network now.
+	err = w.underlyingWriter.Flush()
+	return
+}
+
+///////////////////////////////////////
+// CFB8 stream cipher
+///////////////////////////////////////
+
+// The standard library only implements full-block CFB, whereas Minecraft shifts the register one byte at a time.
+
+type cfb8 struct {
+	block cipher.Block
+	register []byte
+	output []byte
+	decrypt bool
+}
+
+func NewCFB8Encrypter(block cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(block, iv, false)
+}
+
+func NewCFB8Decrypter(block cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(block, iv, true)
+}
+
+func newCFB8(block cipher.Block, iv []byte, decrypt bool) *cfb8 {
+	if len(iv) != block.BlockSize() {
+		panic("CFB8 initial vector length must equal the block size")
+	}
+
+	register := make([]byte, len(iv))
+	copy(register, iv)
+
+	return &cfb8 {
+		block: block,
+		register: register,
+		output: make([]byte, len(iv)),
+		decrypt: decrypt,
+	}
+}
+
+func (x *cfb8) XORKeyStream(dst, src []byte) {
+	last := len(x.register) - 1
+
+	for i, in := range src {
+		x.block.Encrypt(x.output, x.register)
+		out := in ^ x.output[0]
+		dst[i] = out
+
+		copy(x.register, x.register[1:])
+		if x.decrypt {
+			x.register[last] = in
+		} else {
+			x.register[last] = out
+		}
+	}
+}
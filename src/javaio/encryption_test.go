@@ -0,0 +1,72 @@
+package javaio
+
+import "testing"
+import "bufio"
+import "bytes"
+import "crypto/aes"
+import "encoding/hex"
+
+func TestCFB8(t *testing.T) {
+	// Test vector from NIST SP 800-38A (CFB8-AES128)
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	iv, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	plaintext, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d")
+	ciphertext, _ := hex.DecodeString("3b79424c9c0dd436bace9e0ed4586a4f32b9")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := make([]byte, len(plaintext))
+	NewCFB8Encrypter(block, iv).XORKeyStream(output, plaintext)
+
+	if !bytes.Equal(output, ciphertext) {
+		t.Errorf("Encrypted output incorrect: %x", output)
+	}
+
+	NewCFB8Decrypter(block, iv).XORKeyStream(output, ciphertext)
+
+	if !bytes.Equal(output, plaintext) {
+		t.Errorf("Decrypted output incorrect: %x", output)
+	}
+}
+
+func TestEncryptedStreamRoundTrip(t *testing.T) {
+	sharedSecret := []byte("0123456789abcdef")
+	var network bytes.Buffer
+
+	output, err := NewEncryptedWriter(bufio.NewWriter(&network), sharedSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ClientContext { Protocol: 0x0286, State: StateStatus }
+
+	for _, payload := range []int64 {1, 2} {
+		err = EmitClientboundPacketUncompressed(Packet_0051_Pong { Payload: payload }, ctx, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if bytes.Contains(network.Bytes(), []byte {0x09, 0x01, 0x00}) {
+		t.Errorf("Output was not encrypted: %x", network.Bytes())
+	}
+
+	input, err := NewEncryptedReader(bufio.NewReader(&network), sharedSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []int64 {1, 2} {
+		result, err := ParseServerboundPacketUncompressed(input, ctx, StateStatus)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ping, ok := result.(Packet_0051_Ping); !ok || ping.Payload != expected {
+			t.Errorf("Expected payload %d but instead got: %#v", expected, result)
+		}
+	}
+}
@@ -34,3 +34,45 @@ const (
 	DimensionNether = iota
 	DimensionEnd = iota
 )
+
+type ChatMode int
+const (
+	ChatModeInvalid = iota
+	ChatModeEnabled = iota
+	ChatModeCommandsOnly = iota
+	ChatModeHidden = iota
+)
+
+type Hand int
+const (
+	HandInvalid = iota
+	HandLeft = iota
+	HandRight = iota
+)
+
+// Sent by the client in the entity action packet.
+type PlayerAction int
+const (
+	PlayerActionInvalid = iota
+	PlayerActionStartSneaking = iota
+	PlayerActionStopSneaking = iota
+	PlayerActionLeaveBed = iota
+	PlayerActionStartSprinting = iota
+	PlayerActionStopSprinting = iota
+	PlayerActionStartHorseJump = iota
+	PlayerActionStopHorseJump = iota
+	PlayerActionOpenHorseInventory = iota
+	PlayerActionStartElytraFlying = iota
+)
+
+// Where a chat message is shown.
+type ChatPosition int
+const (
+	ChatPositionInvalid = iota
+	// The chat box, hidden when chat is disabled in the client settings.
+	ChatPositionChat = iota
+	// The chat box, hidden only when chat is fully disabled.
+	ChatPositionSystem = iota
+	// Above the hotbar.
+	ChatPositionActionBar = iota
+)
@@ -17,3 +17,21 @@ type UnsupportedPayloadError struct {
 func (err UnsupportedPayloadError) Error() string {
 	return fmt.Sprintf("Unsupported payload: %s", err.details)	
 }
+
+// Returned when a packet is emitted in a state, or direction, that it does not belong to.
+type InvalidPacketForStateError struct {
+	details string
+}
+
+func (err InvalidPacketForStateError) Error() string {
+	return fmt.Sprintf("Invalid packet for state: %s", err.details)
+}
+
+// Returned when a field of a packet being emitted cannot be represented on the wire.
+type FieldOutOfRangeError struct {
+	details string
+}
+
+func (err FieldOutOfRangeError) Error() string {
+	return fmt.Sprintf("Field out of range: %s", err.details)
+}
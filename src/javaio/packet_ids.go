@@ -0,0 +1,286 @@
+package javaio
+
+import "fmt"
+import "strconv"
+import "strings"
+
+type Direction int
+const (
+	DirectionInvalid = iota
+	DirectionClientbound = iota
+	DirectionServerbound = iota
+)
+
+func (direction Direction) String() string {
+	switch direction {
+	case DirectionClientbound:
+		return "clientbound"
+	case DirectionServerbound:
+		return "serverbound"
+	default:
+		return "invalid"
+	}
+}
+
+// Identifies a packet independently of the id it has on the wire, which changes between versions.
+type PacketKind string
+const (
+	PacketKindHandshake PacketKind = "handshake"
+	PacketKindStatusRequest PacketKind = "status_request"
+	PacketKindStatusResponse PacketKind = "status_response"
+	PacketKindPing PacketKind = "ping"
+	PacketKindPong PacketKind = "pong"
+	PacketKindLoginStart PacketKind = "login_start"
+	PacketKindEncryptionRequest PacketKind = "encryption_request"
+	PacketKindEncryptionResponse PacketKind = "encryption_response"
+	PacketKindLoginSuccess PacketKind = "login_success"
+	PacketKindSetCompression PacketKind = "set_compression"
+	PacketKindKeepAlive PacketKind = "keep_alive"
+	PacketKindJoinGame PacketKind = "join_game"
+	PacketKindCompassPosition PacketKind = "compass_position"
+	PacketKindPlayerPositionAndLook PacketKind = "player_position_and_look"
+	PacketKindPlayerPosition PacketKind = "player_position"
+	PacketKindPlayerLook PacketKind = "player_look"
+	PacketKindChunkData PacketKind = "chunk_data"
+	PacketKindPlayerInfo PacketKind = "player_info"
+	PacketKindSpawnPlayer PacketKind = "spawn_player"
+	PacketKindSpawnLivingEntity PacketKind = "spawn_living_entity"
+	PacketKindEntityTranslate PacketKind = "entity_translate"
+	PacketKindEntityVelocity PacketKind = "entity_velocity"
+	PacketKindUpdateLight PacketKind = "update_light"
+	PacketKindDestroyEntities PacketKind = "destroy_entities"
+	PacketKindDisconnect PacketKind = "disconnect"
+	PacketKindEntityRelativeMove PacketKind = "entity_relative_move"
+	PacketKindEntityLook PacketKind = "entity_look"
+	PacketKindEntityHeadLook PacketKind = "entity_head_look"
+	PacketKindEntityTeleport PacketKind = "entity_teleport"
+	PacketKindTeleportConfirm PacketKind = "teleport_confirm"
+	PacketKindClientSettings PacketKind = "client_settings"
+	PacketKindPlayerAbilities PacketKind = "player_abilities"
+	PacketKindEntityAction PacketKind = "entity_action"
+	PacketKindHeldItemChange PacketKind = "held_item_change"
+	PacketKindChatMessage PacketKind = "chat_message"
+	PacketKindTabComplete PacketKind = "tab_complete"
+	PacketKindDeclareCommands PacketKind = "declare_commands"
+	PacketKindBlockChange PacketKind = "block_change"
+	PacketKindUnloadChunk PacketKind = "unload_chunk"
+	PacketKindUpdateViewPosition PacketKind = "update_view_position"
+	PacketKindUpdateViewDistance PacketKind = "update_view_distance"
+)
+
+// Returned when a packet does not exist, or is not known to exist, in a particular version.
+type UnknownPacketError struct {
+	details string
+}
+
+func (err UnknownPacketError) Error() string {
+	return fmt.Sprintf("Unknown packet: %s", err.details)
+}
+
+// Looks up the wire id of a packet kind.
+func LookupPacketId(protocol uint, state State, direction Direction, kind PacketKind) (int32, error) {
+	for _, entry := range packetIds {
+		if entry.matches(protocol, state, direction) && entry.kind == kind {
+			return entry.id, nil
+		}
+	}
+
+	return 0, UnknownPacketError { fmt.Sprintf("No %s %s packet in version %04X", direction, kind, protocol) }
+}
+
+// Looks up the packet kind of a wire id.
+func LookupPacketKind(protocol uint, state State, direction Direction, id int32) (PacketKind, error) {
+	for _, entry := range packetIds {
+		if entry.matches(protocol, state, direction) && entry.id == id {
+			return entry.kind, nil
+		}
+	}
+
+	return "", UnknownPacketError { fmt.Sprintf("No %s packet with id 0x%02X in version %04X", direction, id, protocol) }
+}
+
+///////////////////////////////////////
+// Table
+///////////////////////////////////////
+
+type packetIdEntry struct {
+	firstProtocol uint
+	lastProtocol uint
+	state State
+	direction Direction
+	kind PacketKind
+	id int32
+}
+
+func (entry packetIdEntry) matches(protocol uint, state State, direction Direction) bool {
+	return protocol >= entry.firstProtocol && protocol <= entry.lastProtocol && state == entry.state && direction == entry.direction
+}
+
+var packetIds = parsePacketIdTable(packetIdTable)
+
+var packetIdTableStates = map[string]State {
+	"handshaking": StateHandshaking,
+	"status": StateStatus,
+	"login": StateLogin,
+	"play": StatePlay,
+}
+
+var packetIdTableDirections = map[string]Direction {
+	"clientbound": DirectionClientbound,
+	"serverbound": DirectionServerbound,
+}
+
+// The table is part of the source, so any mistake in it is a bug in this package.
+func parsePacketIdTable(table string) []packetIdEntry {
+	var entries []packetIdEntry
+
+	for lineNumber, line := range strings.Split(table, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		invalid := func(reason string) {
+			panic(fmt.Sprintf("Internal package bug: packet id table line %d: %s", lineNumber, reason))
+		}
+
+		if len(fields) != 6 {
+			invalid("expected 6 fields")
+		}
+
+		firstProtocol, err := strconv.ParseUint(fields[0], 16, 16)
+		if err != nil {
+			invalid("invalid first version")
+		}
+
+		lastProtocol, err := strconv.ParseUint(fields[1], 16, 16)
+		if err != nil {
+			invalid("invalid last version")
+		}
+
+		state, ok := packetIdTableStates[fields[2]]
+		if !ok {
+			invalid("invalid state")
+		}
+
+		direction, ok := packetIdTableDirections[fields[3]]
+		if !ok {
+			invalid("invalid direction")
+		}
+
+		id, err := strconv.ParseInt(fields[5], 0, 32)
+		if err != nil {
+			invalid("invalid id")
+		}
+
+		entries = append(entries, packetIdEntry {
+			firstProtocol: uint(firstProtocol),
+			lastProtocol: uint(lastProtocol),
+			state: state,
+			direction: direction,
+			kind: PacketKind(fields[4]),
+			id: int32(id),
+		})
+	}
+
+	return entries
+}
+
+// Versions are inclusive and use the numbering described in docs/protocol_versions.md.
+// Packets missing from this table cannot be emitted or parsed in that version.
+const packetIdTable = `
+# first last  state        direction    kind                      id
+
+# The handshake is parsed before the version is known
+0000  FFFF  handshaking  serverbound  handshake                 0x00
+
+0051  FFFF  status       clientbound  status_response           0x00
+0051  FFFF  status       clientbound  pong                      0x01
+0051  FFFF  status       serverbound  status_request            0x00
+0051  FFFF  status       serverbound  ping                      0x01
+
+0051  FFFF  login        clientbound  disconnect                0x00
+0051  FFFF  login        clientbound  encryption_request        0x01
+0051  FFFF  login        clientbound  login_success             0x02
+0080  FFFF  login        clientbound  set_compression           0x03
+0051  FFFF  login        serverbound  login_start               0x00
+0051  FFFF  login        serverbound  encryption_response       0x01
+
+# 1.14 to 1.14.4
+022E  0285  play         clientbound  spawn_living_entity       0x03
+022E  0285  play         clientbound  spawn_player              0x05
+022E  0285  play         clientbound  block_change              0x0B
+022E  0285  play         clientbound  chat_message              0x0E
+022E  0285  play         clientbound  tab_complete              0x10
+022E  0285  play         clientbound  declare_commands          0x11
+022E  0285  play         clientbound  disconnect                0x1A
+022E  0285  play         clientbound  unload_chunk              0x1D
+022E  0285  play         clientbound  keep_alive                0x20
+022E  0285  play         clientbound  chunk_data                0x21
+022E  0285  play         clientbound  update_light              0x24
+022E  0285  play         clientbound  join_game                 0x25
+022E  0285  play         clientbound  entity_relative_move      0x28
+022E  0285  play         clientbound  entity_translate          0x29
+022E  0285  play         clientbound  entity_look               0x2A
+022E  0285  play         clientbound  player_info               0x33
+022E  0285  play         clientbound  player_position_and_look  0x35
+022E  0285  play         clientbound  destroy_entities          0x37
+022E  0285  play         clientbound  entity_head_look          0x3B
+022E  0285  play         clientbound  update_view_position      0x40
+022E  0285  play         clientbound  update_view_distance      0x41
+022E  0285  play         clientbound  entity_velocity           0x45
+022E  0285  play         clientbound  compass_position          0x4D
+022E  0285  play         clientbound  entity_teleport           0x56
+022E  0285  play         serverbound  teleport_confirm          0x00
+022E  0285  play         serverbound  chat_message              0x03
+022E  0285  play         serverbound  client_settings           0x05
+022E  0285  play         serverbound  tab_complete              0x06
+022E  0285  play         serverbound  keep_alive                0x0F
+022E  0285  play         serverbound  player_position           0x11
+022E  0285  play         serverbound  player_position_and_look  0x12
+022E  0285  play         serverbound  player_look               0x13
+022E  0285  play         serverbound  player_abilities          0x19
+022E  0285  play         serverbound  entity_action             0x1B
+022E  0285  play         serverbound  held_item_change          0x23
+
+# 1.15 to 1.15.2, starting from an approximation within the 1.15 snapshots
+0286  0293  play         clientbound  spawn_living_entity       0x03
+0286  0293  play         clientbound  spawn_player              0x05
+0286  0293  play         clientbound  block_change              0x0C
+0286  0293  play         clientbound  chat_message              0x0F
+0286  0293  play         clientbound  tab_complete              0x11
+0286  0293  play         clientbound  declare_commands          0x12
+0286  0293  play         clientbound  disconnect                0x1B
+0286  0293  play         clientbound  unload_chunk              0x1E
+0286  0293  play         clientbound  keep_alive                0x21
+0286  0293  play         clientbound  chunk_data                0x22
+0286  0293  play         clientbound  update_light              0x25
+0286  0293  play         clientbound  join_game                 0x26
+0286  0293  play         clientbound  entity_relative_move      0x29
+0286  0293  play         clientbound  entity_translate          0x2A
+0286  0293  play         clientbound  entity_look               0x2B
+0286  0293  play         clientbound  player_info               0x34
+0286  0293  play         clientbound  player_position_and_look  0x36
+0286  0293  play         clientbound  destroy_entities          0x38
+0286  0293  play         clientbound  entity_head_look          0x3C
+0286  0293  play         clientbound  update_view_position      0x41
+0286  0293  play         clientbound  update_view_distance      0x42
+0286  0293  play         clientbound  entity_velocity           0x46
+0286  0293  play         clientbound  compass_position          0x4E
+0286  0293  play         clientbound  entity_teleport           0x57
+0286  0293  play         serverbound  teleport_confirm          0x00
+0286  0293  play         serverbound  chat_message              0x03
+0286  0293  play         serverbound  client_settings           0x05
+0286  0293  play         serverbound  tab_complete              0x06
+0286  0293  play         serverbound  keep_alive                0x0F
+0286  0293  play         serverbound  player_position           0x11
+0286  0293  play         serverbound  player_position_and_look  0x12
+0286  0293  play         serverbound  player_look               0x13
+0286  0293  play         serverbound  player_abilities          0x19
+0286  0293  play         serverbound  entity_action             0x1B
+0286  0293  play         serverbound  held_item_change          0x23
+`
@@ -0,0 +1,71 @@
+package javaio
+
+import "testing"
+
+func TestLookupPacketId(t *testing.T) {
+	iomap := []struct {
+		protocol uint
+		state State
+		direction Direction
+		kind PacketKind
+		id int32
+	} {
+		{0x0000, StateHandshaking, DirectionServerbound, PacketKindHandshake, 0x00},
+		{0x0051, StateStatus, DirectionClientbound, PacketKindPong, 0x01},
+		{0x0323, StateLogin, DirectionClientbound, PacketKindSetCompression, 0x03},
+		{0x022E, StatePlay, DirectionClientbound, PacketKindChunkData, 0x21},
+		{0x0243, StatePlay, DirectionClientbound, PacketKindKeepAlive, 0x20},
+		{0x028E, StatePlay, DirectionClientbound, PacketKindChunkData, 0x22},
+		{0x028E, StatePlay, DirectionServerbound, PacketKindPlayerPositionAndLook, 0x12},
+		{0x022E, StatePlay, DirectionClientbound, PacketKindEntityTeleport, 0x56},
+		{0x028E, StatePlay, DirectionClientbound, PacketKindEntityHeadLook, 0x3C},
+		{0x0290, StatePlay, DirectionClientbound, PacketKindDeclareCommands, 0x12},
+		{0x0290, StatePlay, DirectionServerbound, PacketKindTabComplete, 0x06},
+		{0x022E, StatePlay, DirectionClientbound, PacketKindBlockChange, 0x0B},
+		{0x022E, StatePlay, DirectionClientbound, PacketKindUnloadChunk, 0x1D},
+		{0x0290, StatePlay, DirectionClientbound, PacketKindSpawnLivingEntity, 0x03},
+		{0x028E, StatePlay, DirectionClientbound, PacketKindUnloadChunk, 0x1E},
+		{0x022E, StatePlay, DirectionClientbound, PacketKindUpdateViewPosition, 0x40},
+		{0x0290, StatePlay, DirectionClientbound, PacketKindUpdateViewDistance, 0x42},
+	}
+
+	for i, mapping := range iomap {
+		id, err := LookupPacketId(mapping.protocol, mapping.state, mapping.direction, mapping.kind)
+		if err != nil || id != mapping.id {
+			t.Errorf("Output incorrect for mapping %d: 0x%02X %v", i, id, err)
+		}
+
+		kind, err := LookupPacketKind(mapping.protocol, mapping.state, mapping.direction, mapping.id)
+		if err != nil || kind != mapping.kind {
+			t.Errorf("Reverse output incorrect for mapping %d: %s %v", i, kind, err)
+		}
+	}
+}
+
+func TestLookupUnknownPacket(t *testing.T) {
+	iemap := []struct {
+		protocol uint
+		state State
+		direction Direction
+		kind PacketKind
+	} {
+		// 1.13.2 and 1.16 are not supported in play
+		{0x0185, StatePlay, DirectionClientbound, PacketKindJoinGame},
+		{0x0286 + 0x0100, StatePlay, DirectionClientbound, PacketKindJoinGame},
+		// Set Compression was introduced in 1.8
+		{0x0055, StateLogin, DirectionClientbound, PacketKindSetCompression},
+		{0x028E, StatePlay, DirectionServerbound, PacketKindJoinGame},
+	}
+
+	for i, mapping := range iemap {
+		_, err := LookupPacketId(mapping.protocol, mapping.state, mapping.direction, mapping.kind)
+		if _, ok := err.(UnknownPacketError); !ok {
+			t.Errorf("Expected UnknownPacketError for mapping %d but instead got: %v", i, err)
+		}
+	}
+
+	err := EmitClientboundPacketUncompressed(KeepAlive {}, ClientContext { Protocol: 0x0185, State: StatePlay }, nil)
+	if _, ok := err.(UnknownPacketError); !ok {
+		t.Errorf("Expected UnknownPacketError from emit but instead got: %v", err)
+	}
+}
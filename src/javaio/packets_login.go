@@ -9,8 +9,23 @@ type LoginSuccess struct {
 	Username string
 }
 
+type SetCompression struct {
+	Threshold int32
+}
+
+type EncryptionRequest struct {
+	ServerId string
+	PublicKey []byte
+	VerifyToken []byte
+}
+
 // Serverbound
 
 type LoginStart struct {
 	ClientsideUsername string
 }
+
+type EncryptionResponse struct {
+	SharedSecret []byte
+	VerifyToken []byte
+}
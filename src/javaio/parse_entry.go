@@ -1,9 +1,118 @@
 package javaio
 
+import "io"
 import "fmt"
+import "bytes"
 import "bufio"
+import "compress/zlib"
 
-/**  Clientbound entry is not implemented.  **/
+// Clientbound
+
+func ParseClientboundPacketUncompressed(data *bufio.Reader, ctx ClientContext, state State) (result interface{}, err error) {
+	if state == StatePreNetty {
+		result, err = Read_002E_StatusResponse(data)
+		return
+	} else if state == StateVeryPreNetty {
+		result, err = ReadVeryLegacyStatusResponse(data)
+		return
+	}
+
+	body, err := readFrame(data)
+	if err != nil {
+		return
+	}
+
+	result, err = parseClientboundPacketBody(body, ctx, state)
+	return
+}
+
+func ParseClientboundPacketCompressed(data *bufio.Reader, ctx ClientContext, state State) (result interface{}, err error) {
+	body, err := readCompressedFrame(data)
+	if err != nil {
+		return
+	}
+
+	result, err = parseClientboundPacketBody(body, ctx, state)
+	return
+}
+
+// Parses the packet id followed by the packet data, without any framing.
+func parseClientboundPacketBody(data *bufio.Reader, ctx ClientContext, state State) (result interface{}, err error) {
+	packetId, err := ReadVarInt(data)
+	if err != nil {
+		return
+	}
+
+	kind, err := LookupPacketKind(ctx.Protocol, state, DirectionClientbound, packetId)
+	if err != nil {
+		return
+	}
+
+	switch kind {
+	case PacketKindStatusResponse:
+		result, err = Read_0051_StatusResponse(data)
+	case PacketKindPong:
+		result, err = Read_0051_Pong(data)
+	case PacketKindDisconnect:
+		result, err = ReadDisconnect(data)
+	case PacketKindEncryptionRequest:
+		result, err = ParseEncryptionRequest(data)
+	case PacketKindLoginSuccess:
+		result, err = ParseLoginSuccess(data)
+	case PacketKindSetCompression:
+		result, err = ParseSetCompression(data)
+	case PacketKindKeepAlive:
+		result, err = ReadKeepAlive(data)
+	case PacketKindJoinGame:
+		result, err = ReadJoinGame(data, ctx)
+	case PacketKindCompassPosition:
+		result, err = ReadCompassPosition(data)
+	case PacketKindBlockChange:
+		result, err = ReadBlockChange(data)
+	case PacketKindPlayerPositionAndLook:
+		result, err = ReadPlayerPositionAndLook(data)
+	case PacketKindChunkData:
+		result, err = ReadChunkData(data, ctx)
+	case PacketKindPlayerInfo:
+		result, err = ReadPlayerInfo(data)
+	case PacketKindSpawnPlayer:
+		result, err = Read_SpawnPlayer(data, ctx)
+	case PacketKindSpawnLivingEntity:
+		result, err = ReadSpawnLivingEntity(data, ctx)
+	case PacketKindEntityTranslate:
+		result, err = Read_EntityTranslate(data)
+	case PacketKindEntityVelocity:
+		result, err = Read_EntityVelocity(data)
+	case PacketKindUpdateLight:
+		result, err = ReadUpdateLight(data)
+	case PacketKindDestroyEntities:
+		result, err = ReadDestroyEntities(data)
+	case PacketKindChatMessage:
+		result, err = ReadChatMessage(data)
+	case PacketKindTabComplete:
+		result, err = ReadTabComplete(data)
+	case PacketKindDeclareCommands:
+		result, err = ReadDeclareCommands(data)
+	case PacketKindEntityRelativeMove:
+		result, err = ReadEntityRelativeMove(data)
+	case PacketKindEntityLook:
+		result, err = ReadEntityLook(data)
+	case PacketKindEntityHeadLook:
+		result, err = ReadEntityHeadLook(data)
+	case PacketKindEntityTeleport:
+		result, err = ReadEntityTeleport(data)
+	case PacketKindUnloadChunk:
+		result, err = ReadUnloadChunk(data)
+	case PacketKindUpdateViewPosition:
+		result, err = ReadUpdateViewPosition(data)
+	case PacketKindUpdateViewDistance:
+		result, err = ReadUpdateViewDistance(data)
+	default:
+		err = UnsupportedPayloadError { fmt.Sprintf("Parsing %s packets is not implemented", kind) }
+	}
+
+	return
+}
 
 // Serverbound
 
@@ -17,7 +126,10 @@ func ParseServerboundPacketUncompressed(data *bufio.Reader, ctx ClientContext, s
 		// A hack is used to determine if only 1 byte is available.
 		
 		// Block until first byte is received so following check works correctly.
-		_, _ = data.Peek(1) 
+		_, err = data.Peek(1)
+		if err != nil {
+			return
+		}
 
 		// This check breaks the intuitive purity contract.
 		// This changes the contract to take into account the
@@ -81,61 +193,148 @@ func ParseServerboundPacketUncompressed(data *bufio.Reader, ctx ClientContext, s
 		return
 	}
 	
-	length, err := ReadVarInt(data)
+	body, err := readFrame(data)
 	if err != nil {
 		return
 	}
 
-	// Ensure one does not read past the length of the packet
-	data = newReaderSlice(data, int(length))
+	result, err = parseServerboundPacketBody(body, ctx, state)
+	return
+}
+
+func ParseServerboundPacketCompressed(data *bufio.Reader, ctx ClientContext, state State) (result interface{}, err error) {
+	body, err := readCompressedFrame(data)
+	if err != nil {
+		return
+	}
 
+	result, err = parseServerboundPacketBody(body, ctx, state)
+	return
+}
+
+// Parses the packet id followed by the packet data, without any framing.
+func parseServerboundPacketBody(data *bufio.Reader, ctx ClientContext, state State) (result interface{}, err error) {
 	packetId, err := ReadVarInt(data)
 	if err != nil {
 		return
 	}
 
-	switch state {
-	case StateHandshaking:
-		switch packetId {
-		case 0:
-			result, err = ParseHandshake(data)
-		default:
-			err = UnsupportedPayloadError { fmt.Sprintf("Unrecognized packet id %d", packetId) }
-		}
-	case StateStatus:
-		switch packetId {
-		case 0:
-			result, err = Read_0051_StatusRequest(data)
-		case 1:
-			result, err = Read_0051_Ping(data)
-		default:
-			err = UnsupportedPayloadError { fmt.Sprintf("Unrecognized packet id %d", packetId) }
-		}
-	case StateLogin:
-		switch packetId {
-		case 0:
-			result, err = ParseLoginStart(data)
-		default:
-			err = UnsupportedPayloadError { fmt.Sprintf("Unrecognized packet id %d", packetId) }
-		}
-	case StatePlay:
-		switch packetId {
-		case int32(PacketId_PlayerPosSb(ctx.Protocol)):
-			result, err = Read_PlayerPosSb(data)
-		case int32(PacketId_PlayerLookSb(ctx.Protocol)):
-			result, err = Read_PlayerLookSb(data)
-		case int32(PacketId_PlayerPosAndLookSb(ctx.Protocol)):
-			result, err = Read_PlayerPosAndLookSb(data)
-		default:
-			err = UnsupportedPayloadError { fmt.Sprintf("Unrecognized packet id %d", packetId) }
-		}
+	kind, err := LookupPacketKind(ctx.Protocol, state, DirectionServerbound, packetId)
+	if err != nil {
+		return
+	}
+
+	switch kind {
+	case PacketKindHandshake:
+		result, err = ParseHandshake(data)
+	case PacketKindStatusRequest:
+		result, err = Read_0051_StatusRequest(data)
+	case PacketKindPing:
+		result, err = Read_0051_Ping(data)
+	case PacketKindLoginStart:
+		result, err = ParseLoginStart(data)
+	case PacketKindEncryptionResponse:
+		result, err = ParseEncryptionResponse(data)
+	case PacketKindKeepAlive:
+		result, err = ReadKeepAlive(data)
+	case PacketKindPlayerPosition:
+		result, err = Read_PlayerPosSb(data)
+	case PacketKindPlayerLook:
+		result, err = Read_PlayerLookSb(data)
+	case PacketKindPlayerPositionAndLook:
+		result, err = Read_PlayerPosAndLookSb(data)
+	case PacketKindChatMessage:
+		result, err = ReadChatMessageSb(data)
+	case PacketKindTabComplete:
+		result, err = ReadTabCompleteSb(data)
+	case PacketKindTeleportConfirm:
+		result, err = ReadTeleportConfirm(data)
+	case PacketKindClientSettings:
+		result, err = ReadClientSettings(data)
+	case PacketKindPlayerAbilities:
+		result, err = ReadPlayerAbilitiesSb(data)
+	case PacketKindEntityAction:
+		result, err = ReadEntityAction(data)
+	case PacketKindHeldItemChange:
+		result, err = ReadHeldItemChange(data)
 	default:
-		panic("State does not match one of non-invalid predefined enum values")
+		err = UnsupportedPayloadError { fmt.Sprintf("Parsing %s packets is not implemented", kind) }
 	}
 
 	return
 }
 
-func ParseServerboundPacketCompressed(data *bufio.Reader, state State) (result interface{}, err error) {
-	panic("ParseServerboundPacketCompressed not implemented")
+// The largest uncompressed packet the vanilla server is willing to accept.
+const maxUncompressedLength = 2097152
+
+// Reads an entire frame and returns a reader over its contents.
+// Without compression, the contents are the packet id and packet data.
+// The whole frame is consumed even if the packet is not parsed, or only partially parsed, so the next frame is read correctly.
+// The error of the stream, such as io.EOF, is returned as-is when the stream ends before the frame begins.
+func readFrame(data *bufio.Reader) (frame *bufio.Reader, err error) {
+	_, err = data.Peek(1)
+	if err != nil {
+		return
+	}
+
+	packetLength, err := ReadVarInt(data)
+	if err != nil {
+		return
+	}
+
+	if packetLength < 0 || packetLength > maxUncompressedLength {
+		err = MalformedPacketError { fmt.Sprintf("Invalid packet length %d", packetLength) }
+		return
+	}
+
+	buf := make([]byte, packetLength)
+	_, readErr := io.ReadFull(data, buf)
+	if readErr != nil {
+		err = MalformedPacketError { "Packet ended abruptly" }
+		return
+	}
+
+	frame = bufio.NewReader(bytes.NewReader(buf))
+	return
+}
+
+// Reads an entire compressed frame and returns a reader over the packet id and packet data.
+func readCompressedFrame(data *bufio.Reader) (body *bufio.Reader, err error) {
+	frameReader, err := readFrame(data)
+	if err != nil {
+		return
+	}
+
+	dataLength, err := ReadVarInt(frameReader)
+	if err != nil {
+		return
+	}
+
+	if dataLength == 0 {
+		// Packet was below the compression threshold and was sent as-is
+		body = frameReader
+		return
+	}
+
+	if dataLength < 0 || dataLength > maxUncompressedLength {
+		err = MalformedPacketError { fmt.Sprintf("Invalid uncompressed data length %d", dataLength) }
+		return
+	}
+
+	zlibReader, zlibErr := zlib.NewReader(frameReader)
+	if zlibErr != nil {
+		err = MalformedPacketError { "Compressed data has an invalid zlib header" }
+		return
+	}
+	defer zlibReader.Close()
+
+	uncompressed := make([]byte, dataLength)
+	_, readErr := io.ReadFull(zlibReader, uncompressed)
+	if readErr != nil {
+		err = MalformedPacketError { "Compressed data was shorter than its declared length" }
+		return
+	}
+
+	body = bufio.NewReader(bytes.NewReader(uncompressed))
+	return
 }
@@ -1,8 +1,69 @@
 package javaio
 
 import "bufio"
+import "github.com/google/uuid"
 
-/**  Clientbound parsing will not be available for the foreseeable future unless contributed by others.  **/
+// Clientbound
+
+func ParseEncryptionRequest(data *bufio.Reader) (result EncryptionRequest, err error) {
+	serverId, err := ReadString(data, 20)
+	if err != nil {
+		return
+	}
+
+	publicKey, err := ReadByteArray(data, 1024)
+	if err != nil {
+		return
+	}
+
+	verifyToken, err := ReadByteArray(data, 1024)
+	if err != nil {
+		return
+	}
+
+	result = EncryptionRequest {
+		ServerId: serverId,
+		PublicKey: publicKey,
+		VerifyToken: verifyToken,
+	}
+	return
+}
+
+func ParseLoginSuccess(data *bufio.Reader) (result LoginSuccess, err error) {
+	uuidString, err := ReadString(data, 36)
+	if err != nil {
+		return
+	}
+
+	playerUuid, parseErr := uuid.Parse(uuidString)
+	if parseErr != nil {
+		err = MalformedPacketError { "Login success contains invalid UUID" }
+		return
+	}
+
+	username, err := ReadString(data, 16)
+	if err != nil {
+		return
+	}
+
+	result = LoginSuccess {
+		Uuid: playerUuid,
+		Username: username,
+	}
+	return
+}
+
+func ParseSetCompression(data *bufio.Reader) (result SetCompression, err error) {
+	threshold, err := ReadVarInt(data)
+	if err != nil {
+		return
+	}
+
+	result = SetCompression {
+		Threshold: threshold,
+	}
+	return
+}
 
 // Serverbound
 
@@ -18,3 +79,23 @@ func ParseLoginStart(data *bufio.Reader) (result LoginStart, err error) {
 	}
 	return
 }
+
+func ParseEncryptionResponse(data *bufio.Reader) (result EncryptionResponse, err error) {
+	// Both fields are encrypted with the server's RSA key, so their length is the key size.
+	// Vanilla servers use 1024-bit keys, but keys of up to 4096 bits are accepted here.
+	sharedSecret, err := ReadByteArray(data, 512)
+	if err != nil {
+		return
+	}
+
+	verifyToken, err := ReadByteArray(data, 512)
+	if err != nil {
+		return
+	}
+
+	result = EncryptionResponse {
+		SharedSecret: sharedSecret,
+		VerifyToken: verifyToken,
+	}
+	return
+}
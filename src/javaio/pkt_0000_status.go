@@ -1,8 +1,11 @@
 package javaio
 
+import "fmt"
 import "bufio"
 import "strconv"
+import "strings"
 import "unicode/utf16"
+import "github.com/davidcallanan/go-mcp/chat"
 
 // TODO: figure out the protocol version number when this packet was introduced.
 
@@ -10,25 +13,106 @@ type VeryLegacyStatusRequest struct {
 }
 
 type VeryLegacyStatusResponse struct {
-	// Color-coding is not supported. Description is treated as plain-text. Section character must not be used, otherwise there will be undefined behaviour.
-	Description string
+	// Color-coding is not supported by these clients, so only the plain text of the description is sent.
+	Description chat.TextComponent
 	MaxPlayers int
 	OnlinePlayers int
 }
 
-func WriteVeryLegacyStatusResponse(status VeryLegacyStatusResponse, stream *bufio.Writer) {
+func WriteVeryLegacyStatusResponse(status VeryLegacyStatusResponse, stream *bufio.Writer) (err error) {
 	packetId := byte(0xff)
-	description := utf16.Encode([]rune(status.Description))
+	// The section character delimits the fields of this packet, so it must not appear in the description
+	plainDescription := strings.ReplaceAll(status.Description.PlainText(), "§", "")
+	description := utf16.Encode([]rune(plainDescription))
 	maxPlayers := utf16.Encode([]rune(strconv.Itoa(status.MaxPlayers)))
 	onlinePlayers := utf16.Encode([]rune(strconv.Itoa(status.OnlinePlayers)))
 	dataLength := int16(len(description) + 1 + len(onlinePlayers) + 1 + len(maxPlayers)) // potentially unsafe cast?
 	delimeter := []byte { 0x00, 0xa7 }
 
-	stream.WriteByte(packetId)
-	WriteShort(dataLength, stream)
-	WriteUTF16(description, stream)
-	stream.Write(delimeter)
-	WriteUTF16(onlinePlayers, stream)
-	stream.Write(delimeter)
-	WriteUTF16(maxPlayers, stream)
+	err = stream.WriteByte(packetId)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(dataLength, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(description, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(delimeter)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(onlinePlayers, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(delimeter)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(maxPlayers, stream)
+	return
+}
+
+func WriteVeryLegacyStatusRequest(_ VeryLegacyStatusRequest, stream *bufio.Writer) (err error) {
+	packetId := byte(0xfe)
+	err = stream.WriteByte(packetId)
+	return
+}
+
+func ReadVeryLegacyStatusResponse(stream *bufio.Reader) (result VeryLegacyStatusResponse, err error) {
+	packetId, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	if packetId != 0xff {
+		err = MalformedPacketError { fmt.Sprintf("Unexpected legacy packet id %d", packetId) }
+		return
+	}
+
+	dataLength, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	data, err := ReadUTF16(stream, int(dataLength))
+	if err != nil {
+		return
+	}
+
+	// The description cannot contain the delimeter, so the last two fields are found by splitting from the right
+	fields := strings.Split(string(utf16.Decode(data)), "§")
+	if len(fields) < 3 {
+		err = MalformedPacketError { "Legacy status response has too few fields" }
+		return
+	}
+
+	onlinePlayers, convErr := strconv.Atoi(fields[len(fields) - 2])
+	if convErr != nil {
+		err = MalformedPacketError { "Legacy status response has invalid online player count" }
+		return
+	}
+
+	maxPlayers, convErr := strconv.Atoi(fields[len(fields) - 1])
+	if convErr != nil {
+		err = MalformedPacketError { "Legacy status response has invalid max player count" }
+		return
+	}
+
+	result = VeryLegacyStatusResponse {
+		Description: chat.Text(strings.Join(fields[:len(fields) - 2], "§")),
+		MaxPlayers: maxPlayers,
+		OnlinePlayers: onlinePlayers,
+	}
+	return
 }
@@ -1,8 +1,11 @@
 package javaio
 
+import "fmt"
 import "bufio"
 import "strconv"
+import "strings"
 import "unicode/utf16"
+import "github.com/davidcallanan/go-mcp/chat"
 
 type Packet_002E_StatusRequest struct {
 }
@@ -10,32 +13,145 @@ type Packet_002E_StatusRequest struct {
 type Packet_002E_StatusResponse struct {
 	Protocol int
 	Version string
-	Description string
+	// Sent using legacy formatting codes.
+	Description chat.TextComponent
 	MaxPlayers int
 	OnlinePlayers int
 }
 
-func Write_002E_StatusResponse(status Packet_002E_StatusResponse, stream *bufio.Writer) {
+func Write_002E_StatusResponse(status Packet_002E_StatusResponse, stream *bufio.Writer) (err error) {
 	packetId := byte(0xff)
 	protocol := utf16.Encode([]rune(strconv.Itoa(status.Protocol)))
 	version := utf16.Encode([]rune(status.Version))
-	description := utf16.Encode([]rune(status.Description))
+	description := utf16.Encode([]rune(status.Description.ToLegacy()))
 	maxPlayers := utf16.Encode([]rune(strconv.Itoa(status.MaxPlayers)))
 	onlinePlayers := utf16.Encode([]rune(strconv.Itoa(status.OnlinePlayers)))
 	dataLength := int16(3 + len(protocol) + 1 + len(version) + 1 + len(description) + 1 + len(onlinePlayers) + 1 + len(maxPlayers)) // potentially unsafe cast?
 	magic := []byte { 0x00, 0xa7, 0x00, 0x31, 0x00, 0x00 }
 	delimeter := []byte { 0x00, 0x00 }
 
-	stream.WriteByte(packetId)
-	WriteShort(dataLength, stream)
-	stream.Write(magic)
-	WriteUTF16(protocol, stream)
-	stream.Write(delimeter)
-	WriteUTF16(version, stream)
-	stream.Write(delimeter)
-	WriteUTF16(description, stream)
-	stream.Write(delimeter)
-	WriteUTF16(onlinePlayers, stream)
-	stream.Write(delimeter)
-	WriteUTF16(maxPlayers, stream)
+	err = stream.WriteByte(packetId)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(dataLength, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(magic)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(protocol, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(delimeter)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(version, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(delimeter)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(description, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(delimeter)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(onlinePlayers, stream)
+	if err != nil {
+		return
+	}
+
+	_, err = stream.Write(delimeter)
+	if err != nil {
+		return
+	}
+
+	err = WriteUTF16(maxPlayers, stream)
+	return
+}
+
+func Write_002E_StatusRequest(_ Packet_002E_StatusRequest, stream *bufio.Writer) (err error) {
+	packetId := byte(0xfe)
+	payload := byte(0x01)
+	err = stream.WriteByte(packetId)
+	if err != nil {
+		return
+	}
+
+	err = stream.WriteByte(payload)
+	return
+}
+
+func Read_002E_StatusResponse(stream *bufio.Reader) (result Packet_002E_StatusResponse, err error) {
+	packetId, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	if packetId != 0xff {
+		err = MalformedPacketError { fmt.Sprintf("Unexpected legacy packet id %d", packetId) }
+		return
+	}
+
+	dataLength, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	data, err := ReadUTF16(stream, int(dataLength))
+	if err != nil {
+		return
+	}
+
+	fields := strings.Split(string(utf16.Decode(data)), "\x00")
+	if len(fields) != 6 || fields[0] != "§1" {
+		err = MalformedPacketError { "Legacy status response has unexpected format" }
+		return
+	}
+
+	protocol, convErr := strconv.Atoi(fields[1])
+	if convErr != nil {
+		err = MalformedPacketError { "Legacy status response has invalid protocol" }
+		return
+	}
+
+	onlinePlayers, convErr := strconv.Atoi(fields[4])
+	if convErr != nil {
+		err = MalformedPacketError { "Legacy status response has invalid online player count" }
+		return
+	}
+
+	maxPlayers, convErr := strconv.Atoi(fields[5])
+	if convErr != nil {
+		err = MalformedPacketError { "Legacy status response has invalid max player count" }
+		return
+	}
+
+	result = Packet_002E_StatusResponse {
+		Protocol: protocol,
+		Version: fields[2],
+		Description: chat.FromLegacy(fields[3]),
+		MaxPlayers: maxPlayers,
+		OnlinePlayers: onlinePlayers,
+	}
+	return
 }
@@ -1,8 +1,10 @@
 package javaio
 
 import "bufio"
+import "strings"
 import "encoding/json"
 import "encoding/base64"
+import "github.com/davidcallanan/go-mcp/chat"
 
 // Types
 
@@ -12,7 +14,7 @@ type Packet_0051_StatusRequest struct {
 type Packet_0051_StatusResponse struct {
 	Protocol int32
 	Version string
-	Description string
+	Description chat.TextComponent
 	FaviconPng []byte
 	MaxPlayers int
 	OnlinePlayers int
@@ -51,12 +53,59 @@ func Read_0051_Ping(stream *bufio.Reader) (result Packet_0051_Ping, err error) {
 	return
 }
 
+func Read_0051_StatusResponse(stream *bufio.Reader) (result Packet_0051_StatusResponse, err error) {
+	jsonString, err := ReadString(stream, 32767)
+	if err != nil {
+		return
+	}
+
+	var jsonObj statusJson
+	jsonErr := json.Unmarshal([]byte(jsonString), &jsonObj)
+	if jsonErr != nil {
+		err = MalformedPacketError { "Status response contains invalid JSON" }
+		return
+	}
+
+	result = Packet_0051_StatusResponse {
+		Protocol: jsonObj.Version.Protocol,
+		Version: jsonObj.Version.Name,
+		Description: jsonObj.Description,
+		MaxPlayers: jsonObj.Players.Max,
+		OnlinePlayers: jsonObj.Players.Online,
+		PlayerSample: make([]Packet_0051_StatusResponse_Player, len(jsonObj.Players.Sample)),
+	}
+
+	for i, p := range jsonObj.Players.Sample {
+		result.PlayerSample[i] = Packet_0051_StatusResponse_Player {
+			Name: p.Name,
+			Uuid: p.Uuid,
+		}
+	}
+
+	const faviconPrefix = "data:image/png;base64,"
+	if strings.HasPrefix(jsonObj.FaviconPng, faviconPrefix) {
+		result.FaviconPng, _ = base64.StdEncoding.DecodeString(jsonObj.FaviconPng[len(faviconPrefix):])
+	}
+
+	return
+}
+
+func Read_0051_Pong(stream *bufio.Reader) (result Packet_0051_Pong, err error) {
+	payload, err := ReadLong(stream)
+	if err != nil {
+		return
+	}
+
+	result = Packet_0051_Pong {
+		Payload: payload,
+	}
+	return
+}
+
 // Write
 
 type statusJson struct {
-	Description struct {
-		Text string `json:"text"`
-	} `json:"description"`
+	Description chat.TextComponent `json:"description"`
 	FaviconPng string `json:"favicon,omitempty"`
 	Version struct {
 		Name string `json:"name"`
@@ -74,10 +123,10 @@ type statusJsonPlayer struct {
 	Uuid string `json:"id"`
 }
 
-func Write_0051_StatusResponse(status Packet_0051_StatusResponse, stream *bufio.Writer) {
+func Write_0051_StatusResponse(status Packet_0051_StatusResponse, stream *bufio.Writer) (err error) {
 	// Generate JSON
 	jsonObj := statusJson {}
-	jsonObj.Description.Text = status.Description
+	jsonObj.Description = status.Description
 	jsonObj.Version.Name = status.Version
 	jsonObj.Version.Protocol = status.Protocol
 	jsonObj.Players.Max = status.MaxPlayers
@@ -95,13 +144,26 @@ func Write_0051_StatusResponse(status Packet_0051_StatusResponse, stream *bufio.
 	jsonBytes, err := json.Marshal(jsonObj)
 
 	if err != nil {
-		panic(err)
+		return
 	}
 
 	// Emit packet
-	WriteString(string(jsonBytes), stream)
+	err = WriteString(string(jsonBytes), stream)
+	return
 }
 
-func Write_0051_Pong(pong Packet_0051_Pong, stream *bufio.Writer) {
-	WriteLong(pong.Payload, stream)
+func Write_0051_Pong(pong Packet_0051_Pong, stream *bufio.Writer) (err error) {
+	err = WriteLong(pong.Payload, stream)
+	return
+}
+
+func Write_0051_StatusRequest(_ Packet_0051_StatusRequest, stream *bufio.Writer) (err error) {
+	// No fields
+
+	return
+}
+
+func Write_0051_Ping(ping Packet_0051_Ping, stream *bufio.Writer) (err error) {
+	err = WriteLong(ping.Payload, stream)
+	return
 }
@@ -0,0 +1,169 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+// Light is sent for the section below the world, the 16 sections of the world, and the section above it.
+const LightSectionCount = 18
+
+// Each section holds 4096 light levels of 4 bits each, in the same order as the blocks of a chunk section.
+const LightArrayLength = 2048
+
+type UpdateLight struct {
+	ChunkX int32
+	ChunkZ int32
+	// Indexed from the section below the world upwards.
+	// Nil arrays are not sent, which leaves the light of that section unchanged on the client.
+	SkyLight [LightSectionCount][]byte
+	BlockLight [LightSectionCount][]byte
+}
+
+func WriteUpdateLight(data UpdateLight, stream *bufio.Writer) (err error) {
+	skyLightMask, emptySkyLightMask, err := lightMasks(data.SkyLight)
+	if err != nil {
+		return
+	}
+
+	blockLightMask, emptyBlockLightMask, err := lightMasks(data.BlockLight)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.ChunkX, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.ChunkZ, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(skyLightMask, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(blockLightMask, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(emptySkyLightMask, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(emptyBlockLightMask, stream)
+	if err != nil {
+		return
+	}
+
+	for i, light := range data.SkyLight {
+		if skyLightMask & (1 << i) != 0 {
+			err = WriteByteArray(light, stream)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	for i, light := range data.BlockLight {
+		if blockLightMask & (1 << i) != 0 {
+			err = WriteByteArray(light, stream)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// Sections that are completely dark are marked in the empty mask instead of being sent.
+func lightMasks(sections [LightSectionCount][]byte) (mask int32, emptyMask int32, err error) {
+	for i, light := range sections {
+		if light == nil {
+			continue
+		}
+
+		if len(light) != LightArrayLength {
+			err = FieldOutOfRangeError { "Light arrays must be exactly 2048 bytes long" }
+			return
+		}
+
+		isEmpty := true
+		for _, b := range light {
+			if b != 0 {
+				isEmpty = false
+				break
+			}
+		}
+
+		if isEmpty {
+			emptyMask |= 1 << i
+		} else {
+			mask |= 1 << i
+		}
+	}
+
+	return
+}
+
+func ReadUpdateLight(stream *bufio.Reader) (result UpdateLight, err error) {
+	chunkX, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	chunkZ, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	var masks [4]int32
+	for i := range masks {
+		masks[i], err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+	}
+
+	skyLight, err := readLightArrays(stream, masks[0], masks[2])
+	if err != nil {
+		return
+	}
+
+	blockLight, err := readLightArrays(stream, masks[1], masks[3])
+	if err != nil {
+		return
+	}
+
+	result = UpdateLight {
+		ChunkX: chunkX,
+		ChunkZ: chunkZ,
+		SkyLight: skyLight,
+		BlockLight: blockLight,
+	}
+	return
+}
+
+func readLightArrays(stream *bufio.Reader, mask int32, emptyMask int32) (result [LightSectionCount][]byte, err error) {
+	for i := range result {
+		if mask & (1 << i) != 0 {
+			result[i], err = ReadByteArray(stream, LightArrayLength)
+			if err != nil {
+				return
+			}
+
+			if len(result[i]) != LightArrayLength {
+				err = MalformedPacketError { fmt.Sprintf("Invalid light array length %d", len(result[i])) }
+				return
+			}
+		} else if emptyMask & (1 << i) != 0 {
+			result[i] = make([]byte, LightArrayLength)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,25 @@
+package javaio
+
+import "bufio"
+
+// Changes the view distance the client was given in the join game packet.
+type UpdateViewDistance struct {
+	ViewDistance int32
+}
+
+func WriteUpdateViewDistance(data UpdateViewDistance, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.ViewDistance, stream)
+	return
+}
+
+func ReadUpdateViewDistance(stream *bufio.Reader) (result UpdateViewDistance, err error) {
+	viewDistance, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	result = UpdateViewDistance {
+		ViewDistance: viewDistance,
+	}
+	return
+}
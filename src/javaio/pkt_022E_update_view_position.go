@@ -0,0 +1,38 @@
+package javaio
+
+import "bufio"
+
+// Moves the centre of the chunks the client keeps loaded, which it needs to do before it accepts chunks further away.
+// Sent whenever the player crosses into a different chunk.
+type UpdateViewPosition struct {
+	ChunkX int32
+	ChunkZ int32
+}
+
+func WriteUpdateViewPosition(data UpdateViewPosition, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.ChunkX, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.ChunkZ, stream)
+	return
+}
+
+func ReadUpdateViewPosition(stream *bufio.Reader) (result UpdateViewPosition, err error) {
+	chunkX, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	chunkZ, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	result = UpdateViewPosition {
+		ChunkX: chunkX,
+		ChunkZ: chunkZ,
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package javaio
+
+import "bufio"
+
+// Changes a single block in a chunk the client has loaded.
+type BlockChange struct {
+	Location BlockPosition
+	// The id of the block state in the global palette.
+	BlockState int32
+}
+
+func WriteBlockChange(data BlockChange, stream *bufio.Writer) (err error) {
+	err = WriteBlockPos(data.Location, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.BlockState, stream)
+	return
+}
+
+func ReadBlockChange(stream *bufio.Reader) (result BlockChange, err error) {
+	location, err := ReadBlockPos(stream)
+	if err != nil {
+		return
+	}
+
+	blockState, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	result = BlockChange {
+		Location: location,
+		BlockState: blockState,
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package javaio
+
+import "fmt"
+import "bufio"
+import "unicode/utf8"
+import "encoding/json"
+import "github.com/davidcallanan/go-mcp/chat"
+
+// The longest chat message the client is allowed to send.
+const MaxChatMessageLength = 256
+
+type ChatMessage struct {
+	Message chat.TextComponent
+	Position ChatPosition
+}
+
+func WriteChatMessage(data ChatMessage, stream *bufio.Writer) (err error) {
+	var position byte
+	switch data.Position {
+	case ChatPositionChat:
+		position = 0
+	case ChatPositionSystem:
+		position = 1
+	case ChatPositionActionBar:
+		position = 2
+	default:
+		err = FieldOutOfRangeError { "Chat position does not match one of non-invalid predefined enum types" }
+		return
+	}
+
+	message, err := json.Marshal(data.Message)
+	if err != nil {
+		return
+	}
+
+	err = WriteString(string(message), stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(position, stream)
+	return
+}
+
+func ReadChatMessage(stream *bufio.Reader) (result ChatMessage, err error) {
+	messageJson, err := ReadString(stream, 262144)
+	if err != nil {
+		return
+	}
+
+	var message chat.TextComponent
+	jsonErr := json.Unmarshal([]byte(messageJson), &message)
+	if jsonErr != nil {
+		err = MalformedPacketError { fmt.Sprintf("Invalid chat message: %s", jsonErr) }
+		return
+	}
+
+	positionId, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	var position ChatPosition
+	switch positionId {
+	case 0:
+		position = ChatPositionChat
+	case 1:
+		position = ChatPositionSystem
+	case 2:
+		position = ChatPositionActionBar
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized chat position %d", positionId) }
+		return
+	}
+
+	result = ChatMessage {
+		Message: message,
+		Position: position,
+	}
+	return
+}
+
+// Sent by the client when the player sends a chat message or command.
+// Commands start with a slash.
+type ChatMessageSb struct {
+	Message string
+}
+
+func WriteChatMessageSb(data ChatMessageSb, stream *bufio.Writer) (err error) {
+	if utf8.RuneCountInString(data.Message) > MaxChatMessageLength {
+		err = FieldOutOfRangeError { fmt.Sprintf("Chat messages cannot be longer than %d characters", MaxChatMessageLength) }
+		return
+	}
+
+	err = WriteString(data.Message, stream)
+	return
+}
+
+func ReadChatMessageSb(stream *bufio.Reader) (result ChatMessageSb, err error) {
+	message, err := ReadString(stream, MaxChatMessageLength)
+	if err != nil {
+		return
+	}
+
+	result = ChatMessageSb {
+		Message: message,
+	}
+	return
+}
@@ -1,7 +1,10 @@
 package javaio
 
+import "fmt"
 import "bufio"
 import "bytes"
+import "github.com/davidcallanan/go-mcp/nbt"
+import "github.com/davidcallanan/go-mcp/registry"
 
 type ChunkData struct {
 	X int32
@@ -10,24 +13,21 @@ type ChunkData struct {
 	Sections [][]uint32
 }
 
-func PacketId_ChunkData(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x22
-	} else {
-		// 1.14
-		return 0x21
+func WriteChunkData(chunk ChunkData, ctx ClientContext, result *bufio.Writer) (err error) {
+	// Set biome to void for the time being
+	var biome int32
+	if chunk.IsNew {
+		biome, err = registry.Biomes.LookupId("minecraft:the_void", ctx.Protocol)
+		if err != nil {
+			return
+		}
 	}
-	// todo older versions
-}
 
-func WriteChunkData(chunk ChunkData, ctx ClientContext, result *bufio.Writer) {
 	sectionMask := int32(0)
 
 	for i, section := range chunk.Sections {
-		if i >= 8 {
-			return
+		if i >= 16 {
+			break
 		}
 
 		if len(section) != 0 {
@@ -35,43 +35,41 @@ func WriteChunkData(chunk ChunkData, ctx ClientContext, result *bufio.Writer) {
 		}
 	}
 
-	WriteInt(chunk.X, result)
-	WriteInt(chunk.Z, result)
-	WriteBool(chunk.IsNew, result)
-	WriteVarInt(sectionMask, result)
+	err = WriteInt(chunk.X, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteInt(chunk.Z, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(chunk.IsNew, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(sectionMask, result)
+	if err != nil {
+		return
+	}
 	
-	// Hacked in NBT for heightmaps
-	// Maybe we have to send the length as well?
-	result.Write([]byte {
-		10, // Compound start
-		0,  // Length of compound name (1/2)
-		0,  // Length of compound name (2/2)
-		12, // Start long array
-		0,  // Length of array name (1/2)
-		15, // Length of array name (2/2)
+	err = nbt.NewEncoder(result).Encode(chunkHeightmaps {
+		MotionBlocking: encodeHeightmap(chunk.Sections, ctx.Protocol),
 	})
-	result.Write([]byte("MOTION_BLOCKING")) // Array name
-	WriteInt(36, result) // Length of array
-	for i := 0; i < 288 / 9; i++ {
-		// arbitrary value for the heightmap
-		result.WriteByte(0b00100000)
-		result.WriteByte(0b00010000)
-		result.WriteByte(0b00001000)
-		result.WriteByte(0b00000100)
-		result.WriteByte(0b00000010)
-		result.WriteByte(0b00000001)
-		result.WriteByte(0b00000000)
-		result.WriteByte(0b10000000)
-		result.WriteByte(0b01000000)
-	}
-	result.WriteByte(0) // Compound end
+	if err != nil {
+		return
+	}
 
 	if ctx.Protocol >= 0x0286 {
 		// 1.15 approximation -- biomes are now added here
 		if chunk.IsNew {
-			// Set biome to void for the time being
 			for i := 0; i < 1024; i++ {
-				WriteInt(127, result)
+				err = WriteInt(biome, result)
+				if err != nil {
+					return
+				}
 			}
 		}
 	}
@@ -80,96 +78,178 @@ func WriteChunkData(chunk ChunkData, ctx ClientContext, result *bufio.Writer) {
 	dataWriter := bufio.NewWriter(&dataBuf)
 	
 	for i, section := range chunk.Sections {
-		if i >= 8 {
+		if i >= 16 {
 			break
 		}
 
 		if len(section) != 0 {
-			EmitChunkSectionData(section, dataWriter)
+			err = EmitChunkSectionData(section, ctx, dataWriter)
+			if err != nil {
+				return
+			}
 		}
 	}
 
 	if ctx.Protocol < 0x0286 {
 		// 1.14 approximation -- biomes are added here in this version
 		if chunk.IsNew {
-			// Set biome to void for the time being
 			for i := 0; i < 256; i++ {
-				WriteInt(127, dataWriter)
+				err = WriteInt(biome, dataWriter)
+				if err != nil {
+					return
+				}
 			}
 		}
 	}
 
-	dataWriter.Flush()
-	WriteVarInt(int32(dataBuf.Len()), result) // potentially unsafe cast
-	result.Write(dataBuf.Bytes())
+	err = dataWriter.Flush()
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(int32(dataBuf.Len()), result) // potentially unsafe cast
+	if err != nil {
+		return
+	}
 
-	WriteVarInt(0, result) // no block entities
+	_, err = result.Write(dataBuf.Bytes())
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(0, result) // no block entities
+	return
 }
 
-func EmitChunkSectionData(blocks []uint32, result *bufio.Writer) {
-	const bitsPerBlock = 14
+func ReadChunkData(stream *bufio.Reader, ctx ClientContext) (result ChunkData, err error) {
+	x, err := ReadInt(stream)
+	if err != nil {
+		return
+	}
+
+	z, err := ReadInt(stream)
+	if err != nil {
+		return
+	}
+
+	isNew, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	sectionMask, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	// The reader is reused by the decoder because it is already a bufio.Reader,
+	// so no bytes past the end of the heightmaps are consumed.
+	var heightmaps chunkHeightmaps
+	nbtErr := nbt.NewDecoder(stream).Decode(&heightmaps)
+	if nbtErr != nil {
+		err = MalformedPacketError { fmt.Sprintf("Invalid heightmaps: %s", nbtErr) }
+		return
+	}
+
+	if ctx.Protocol >= 0x0286 && isNew {
+		// 1.15 approximation -- biomes are sent here
+		for i := 0; i < 1024; i++ {
+			_, err = ReadInt(stream)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	dataLength, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if dataLength < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid chunk data length %d", dataLength) }
+		return
+	}
+
+	data := newReaderSlice(stream, int(dataLength))
+	var sections [][]uint32
+
+	for i := 0; i < 16; i++ {
+		if sectionMask & (1 << i) == 0 {
+			continue
+		}
+
+		for len(sections) < i {
+			sections = append(sections, nil)
+		}
+
+		var section []uint32
+		section, err = ReadChunkSectionData(data, ctx)
+		if err != nil {
+			return
+		}
 
-	WriteShort(4096, result) // block count
-	WriteUByte(bitsPerBlock, result)
-	// No palette because bits per block is full (can be optimized in future)
+		sections = append(sections, section)
+	}
 
-	if len(blocks) != 4096 {
-		panic("There must be exactly 4096 blocks in each chunk section")
+	if ctx.Protocol < 0x0286 && isNew {
+		// 1.14 approximation -- biomes are sent at the end of the data
+		for i := 0; i < 256; i++ {
+			_, err = ReadInt(data)
+			if err != nil {
+				return
+			}
+		}
 	}
 
-	bitLength := len(blocks) * bitsPerBlock
-	length := (bitLength + bitLength % 64) / 64
+	blockEntityCount, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
 
-	WriteVarInt(int32(length), result)
+	if blockEntityCount != 0 {
+		err = UnsupportedPayloadError { "Block entities are not supported" }
+		return
+	}
 
-	currLong := uint64(0)
-	start := uint64(0)
+	result = ChunkData {
+		X: x,
+		Z: z,
+		IsNew: isNew,
+		Sections: sections,
+	}
+	return
+}
 
-	for _, block := range blocks {
-		// var b2 uint64 = uint64(block) & ((1 << bitsPerBlock) - 1)
-		// var b uint64
+type chunkHeightmaps struct {
+	MotionBlocking []int64 `nbt:"MOTION_BLOCKING"`
+}
 
-		// for i := 0; i < bitsPerBlock; i++ {
-		// 	b2 |= (b2 >> (bitsPerBlock - 1 - i)) & 1
-		// }
+// Computes the height above the highest non-air block of each column, packed as 9-bit values.
+func encodeHeightmap(sections [][]uint32, protocol uint) []int64 {
+	heights := make([]uint32, 256)
 
-		var b uint64 = uint64(block) & ((1 << bitsPerBlock) - 1)
-		currLong |= b << start
+	for i := range heights {
+		for sectionY := len(sections) - 1; sectionY >= 0 && heights[i] == 0; sectionY-- {
+			section := sections[sectionY]
+			if len(section) != 4096 {
+				continue
+			}
 
-		if start + bitsPerBlock >= 64 {
-			WriteULong(currLong, result)
-			currLong = 0
-			currLong |= b >> (64 - start)
-			start += bitsPerBlock
-			start -= 64
-		} else {
-			start += bitsPerBlock
+			for y := 15; y >= 0; y-- {
+				if section[y * 256 + i] != 0 {
+					heights[i] = uint32(sectionY * 16 + y + 1)
+					break
+				}
+			}
 		}
 	}
 
-	// currLong := uint64(0)
-	// currLongBit := uint64(0)
-
-	// for _, block := range blocks {
-	// 	// take each bit from the block
-	// 	for i := 0; i < bitsPerBlock; i++ {
-	// 		// extract bit
-	// 		bit := (block >> i) & 1
-	// 		// save bit into long
-	// 		currLong |= uint64(bit) << currLongBit
-	// 		currLongBit++
-	// 		// when long is filled, emit and reset
-	// 		if currLongBit == 64 {
-	// 			EmitLong(int64(currLong), result) // potentially unsafe cast
-	// 			fmt.Printf("%064b\n", currLong)
-	// 			currLong = 0
-	// 			currLongBit = 0
-	// 		}
-	// 	}
-	// }
-
-	// // ensure no leftover bits
-	// if currLongBit > 0 {
-	// 	panic("Shouldn't reach this point")
-	// }
+	longs := packValues(heights, 9, protocol < nonSpanningPackingProtocol)
+	result := make([]int64, len(longs))
+	for i, long := range longs {
+		result[i] = int64(long)
+	}
+
+	return result
 }
@@ -0,0 +1,147 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+// Bits of ClientSettings.DisplayedSkinParts.
+const (
+	SkinPartCape = 0x01
+	SkinPartJacket = 0x02
+	SkinPartLeftSleeve = 0x04
+	SkinPartRightSleeve = 0x08
+	SkinPartLeftPantsLeg = 0x10
+	SkinPartRightPantsLeg = 0x20
+	SkinPartHat = 0x40
+)
+
+// Sent by the client after joining, and again whenever the player changes these settings.
+type ClientSettings struct {
+	// Such as "en_us".
+	Locale string
+	// In chunks, as chosen by the player, which may be more than the server is willing to send.
+	ViewDistance uint8
+	ChatMode ChatMode
+	ChatColors bool
+	DisplayedSkinParts uint8
+	MainHand Hand
+}
+
+func WriteClientSettings(data ClientSettings, stream *bufio.Writer) (err error) {
+	var chatModeId int32
+	switch data.ChatMode {
+	case ChatModeEnabled:
+		chatModeId = 0
+	case ChatModeCommandsOnly:
+		chatModeId = 1
+	case ChatModeHidden:
+		chatModeId = 2
+	default:
+		err = FieldOutOfRangeError { "Chat mode does not match one of non-invalid predefined enum types" }
+		return
+	}
+
+	var mainHandId int32
+	switch data.MainHand {
+	case HandLeft:
+		mainHandId = 0
+	case HandRight:
+		mainHandId = 1
+	default:
+		err = FieldOutOfRangeError { "Main hand does not match one of non-invalid predefined enum types" }
+		return
+	}
+
+	err = WriteString(data.Locale, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.ViewDistance, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(chatModeId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.ChatColors, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.DisplayedSkinParts, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(mainHandId, stream)
+	return
+}
+
+func ReadClientSettings(stream *bufio.Reader) (result ClientSettings, err error) {
+	locale, err := ReadString(stream, 16)
+	if err != nil {
+		return
+	}
+
+	viewDistance, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	chatModeId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	var chatMode ChatMode
+	switch chatModeId {
+	case 0:
+		chatMode = ChatModeEnabled
+	case 1:
+		chatMode = ChatModeCommandsOnly
+	case 2:
+		chatMode = ChatModeHidden
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized chat mode id %d", chatModeId) }
+		return
+	}
+
+	chatColors, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	displayedSkinParts, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	mainHandId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	var mainHand Hand
+	switch mainHandId {
+	case 0:
+		mainHand = HandLeft
+	case 1:
+		mainHand = HandRight
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized hand id %d", mainHandId) }
+		return
+	}
+
+	result = ClientSettings {
+		Locale: locale,
+		ViewDistance: viewDistance,
+		ChatMode: chatMode,
+		ChatColors: chatColors,
+		DisplayedSkinParts: displayedSkinParts,
+		MainHand: mainHand,
+	}
+	return
+}
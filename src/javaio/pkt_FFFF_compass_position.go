@@ -8,19 +8,19 @@ type CompassPosition struct {
 	Location BlockPosition
 }
 
-func WriteCompassPosition(compassPosition CompassPosition, stream *bufio.Writer) {
-	WriteBlockPos(compassPosition.Location, stream)
+func WriteCompassPosition(compassPosition CompassPosition, stream *bufio.Writer) (err error) {
+	err = WriteBlockPos(compassPosition.Location, stream)
+	return
 }
 
-func PacketId_CompassPosition(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x4e
-	} else {
-		// 1.14
-		return 0x4d
+func ReadCompassPosition(stream *bufio.Reader) (result CompassPosition, err error) {
+	location, err := ReadBlockPos(stream)
+	if err != nil {
+		return
 	}
-	// older versions not supported
-	return -1
+
+	result = CompassPosition {
+		Location: location,
+	}
+	return
 }
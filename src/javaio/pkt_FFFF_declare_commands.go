@@ -0,0 +1,491 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+type CommandNodeType int
+const (
+	CommandNodeTypeInvalid = iota
+	CommandNodeTypeRoot = iota
+	CommandNodeTypeLiteral = iota
+	CommandNodeTypeArgument = iota
+)
+
+const (
+	commandNodeTypeMask = 0x03
+	commandNodeExecutable = 0x04
+	commandNodeHasRedirect = 0x08
+	commandNodeHasSuggestionsType = 0x10
+)
+
+// Describes every command the player may use, so that the client can highlight and complete them.
+type DeclareCommands struct {
+	Nodes []CommandNode
+	RootIndex int32
+}
+
+// A node of the command graph, as used by Brigadier. Children and redirects are indices into DeclareCommands.Nodes.
+type CommandNode struct {
+	Type CommandNodeType
+	// Whether the command may end at this node.
+	IsExecutable bool
+	Children []int32
+	HasRedirect bool
+	Redirect int32
+	// Only for literal and argument nodes.
+	Name string
+	// Only for argument nodes, such as "brigadier:integer".
+	Parser string
+	// Only for the parsers that have properties: NumberParserProperties, StringParserProperties,
+	// EntityParserProperties, ScoreHolderParserProperties or RangeParserProperties. Otherwise nil.
+	Properties interface{}
+	// Such as "minecraft:ask_server" to have the client ask the server for suggestions. Empty for none.
+	SuggestionsType string
+}
+
+// For brigadier:double, brigadier:float and brigadier:integer.
+type NumberParserProperties struct {
+	HasMin bool
+	Min float64
+	HasMax bool
+	Max float64
+}
+
+type StringParserKind int
+const (
+	StringParserKindInvalid = iota
+	// A single word of letters, digits and the characters _-.+
+	StringParserKindSingleWord = iota
+	// A single word, or a phrase in double quotes.
+	StringParserKindQuotablePhrase = iota
+	// The rest of the command.
+	StringParserKindGreedyPhrase = iota
+)
+
+// For brigadier:string.
+type StringParserProperties struct {
+	Kind StringParserKind
+}
+
+// For minecraft:entity.
+type EntityParserProperties struct {
+	IsSingle bool
+	IsPlayersOnly bool
+}
+
+// For minecraft:score_holder.
+type ScoreHolderParserProperties struct {
+	AllowMultiple bool
+}
+
+// For minecraft:range.
+type RangeParserProperties struct {
+	AllowDecimals bool
+}
+
+func WriteDeclareCommands(data DeclareCommands, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(int32(len(data.Nodes)), stream)
+	if err != nil {
+		return
+	}
+
+	for _, node := range data.Nodes {
+		err = writeCommandNode(node, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	err = WriteVarInt(data.RootIndex, stream)
+	return
+}
+
+func writeCommandNode(node CommandNode, stream *bufio.Writer) (err error) {
+	var flags byte
+	switch node.Type {
+	case CommandNodeTypeRoot:
+		flags = 0
+	case CommandNodeTypeLiteral:
+		flags = 1
+	case CommandNodeTypeArgument:
+		flags = 2
+	default:
+		err = FieldOutOfRangeError { "Command node type does not match one of non-invalid predefined enum types" }
+		return
+	}
+
+	if node.IsExecutable {
+		flags |= commandNodeExecutable
+	}
+	if node.HasRedirect {
+		flags |= commandNodeHasRedirect
+	}
+	if node.Type == CommandNodeTypeArgument && node.SuggestionsType != "" {
+		flags |= commandNodeHasSuggestionsType
+	}
+
+	err = WriteUByte(flags, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(int32(len(node.Children)), stream)
+	if err != nil {
+		return
+	}
+
+	for _, child := range node.Children {
+		err = WriteVarInt(child, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	if node.HasRedirect {
+		err = WriteVarInt(node.Redirect, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	if node.Type == CommandNodeTypeRoot {
+		return
+	}
+
+	err = WriteString(node.Name, stream)
+	if err != nil {
+		return
+	}
+
+	if node.Type == CommandNodeTypeLiteral {
+		return
+	}
+
+	err = WriteString(node.Parser, stream)
+	if err != nil {
+		return
+	}
+
+	err = writeParserProperties(node.Parser, node.Properties, stream)
+	if err != nil {
+		return
+	}
+
+	if node.SuggestionsType != "" {
+		err = WriteString(node.SuggestionsType, stream)
+	}
+
+	return
+}
+
+func writeParserProperties(parser string, properties interface{}, stream *bufio.Writer) (err error) {
+	switch parser {
+	case "brigadier:double", "brigadier:float", "brigadier:integer":
+		number, _ := properties.(NumberParserProperties)
+
+		var flags byte
+		if number.HasMin {
+			flags |= 0x01
+		}
+		if number.HasMax {
+			flags |= 0x02
+		}
+
+		err = WriteUByte(flags, stream)
+		if err != nil {
+			return
+		}
+
+		if number.HasMin {
+			err = writeParserNumber(parser, number.Min, stream)
+			if err != nil {
+				return
+			}
+		}
+
+		if number.HasMax {
+			err = writeParserNumber(parser, number.Max, stream)
+		}
+
+		return
+	case "brigadier:string":
+		stringProperties, _ := properties.(StringParserProperties)
+
+		var kind int32
+		switch stringProperties.Kind {
+		case StringParserKindSingleWord:
+			kind = 0
+		case StringParserKindQuotablePhrase:
+			kind = 1
+		case StringParserKindGreedyPhrase:
+			kind = 2
+		default:
+			err = FieldOutOfRangeError { "String parser kind does not match one of non-invalid predefined enum types" }
+			return
+		}
+
+		err = WriteVarInt(kind, stream)
+		return
+	case "minecraft:entity":
+		entity, _ := properties.(EntityParserProperties)
+
+		var flags byte
+		if entity.IsSingle {
+			flags |= 0x01
+		}
+		if entity.IsPlayersOnly {
+			flags |= 0x02
+		}
+
+		err = WriteUByte(flags, stream)
+		return
+	case "minecraft:score_holder":
+		scoreHolder, _ := properties.(ScoreHolderParserProperties)
+
+		var flags byte
+		if scoreHolder.AllowMultiple {
+			flags |= 0x01
+		}
+
+		err = WriteUByte(flags, stream)
+		return
+	case "minecraft:range":
+		rangeProperties, _ := properties.(RangeParserProperties)
+		err = WriteBool(rangeProperties.AllowDecimals, stream)
+		return
+	default:
+		// The remaining parsers have no properties
+		return
+	}
+}
+
+func writeParserNumber(parser string, value float64, stream *bufio.Writer) error {
+	switch parser {
+	case "brigadier:double":
+		return WriteDouble(value, stream)
+	case "brigadier:float":
+		return WriteFloat(float32(value), stream)
+	default:
+		return WriteInt(int32(value), stream)
+	}
+}
+
+func ReadDeclareCommands(stream *bufio.Reader) (result DeclareCommands, err error) {
+	count, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if count < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid command node count %d", count) }
+		return
+	}
+
+	nodes := make([]CommandNode, 0)
+
+	for i := int32(0); i < count; i++ {
+		var node CommandNode
+		node, err = readCommandNode(stream)
+		if err != nil {
+			return
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	rootIndex, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if rootIndex < 0 || rootIndex >= count {
+		err = MalformedPacketError { fmt.Sprintf("Root node index %d out of range", rootIndex) }
+		return
+	}
+
+	result = DeclareCommands {
+		Nodes: nodes,
+		RootIndex: rootIndex,
+	}
+	return
+}
+
+func readCommandNode(stream *bufio.Reader) (result CommandNode, err error) {
+	flags, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	switch flags & commandNodeTypeMask {
+	case 0:
+		result.Type = CommandNodeTypeRoot
+	case 1:
+		result.Type = CommandNodeTypeLiteral
+	case 2:
+		result.Type = CommandNodeTypeArgument
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized command node type in flags 0x%02X", flags) }
+		return
+	}
+
+	result.IsExecutable = flags & commandNodeExecutable != 0
+	result.HasRedirect = flags & commandNodeHasRedirect != 0
+
+	childCount, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if childCount < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid command node child count %d", childCount) }
+		return
+	}
+
+	result.Children = make([]int32, 0)
+	for i := int32(0); i < childCount; i++ {
+		var child int32
+		child, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		result.Children = append(result.Children, child)
+	}
+
+	if result.HasRedirect {
+		result.Redirect, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+	}
+
+	if result.Type == CommandNodeTypeRoot {
+		return
+	}
+
+	result.Name, err = ReadString(stream, 32767)
+	if err != nil {
+		return
+	}
+
+	if result.Type == CommandNodeTypeLiteral {
+		return
+	}
+
+	result.Parser, err = ReadString(stream, 32767)
+	if err != nil {
+		return
+	}
+
+	result.Properties, err = readParserProperties(result.Parser, stream)
+	if err != nil {
+		return
+	}
+
+	if flags & commandNodeHasSuggestionsType != 0 {
+		result.SuggestionsType, err = ReadString(stream, 32767)
+	}
+
+	return
+}
+
+func readParserProperties(parser string, stream *bufio.Reader) (result interface{}, err error) {
+	switch parser {
+	case "brigadier:double", "brigadier:float", "brigadier:integer":
+		var flags byte
+		flags, err = ReadUByte(stream)
+		if err != nil {
+			return
+		}
+
+		var number NumberParserProperties
+		number.HasMin = flags & 0x01 != 0
+		number.HasMax = flags & 0x02 != 0
+
+		if number.HasMin {
+			number.Min, err = readParserNumber(parser, stream)
+			if err != nil {
+				return
+			}
+		}
+
+		if number.HasMax {
+			number.Max, err = readParserNumber(parser, stream)
+			if err != nil {
+				return
+			}
+		}
+
+		result = number
+		return
+	case "brigadier:string":
+		var kind int32
+		kind, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		switch kind {
+		case 0:
+			result = StringParserProperties { Kind: StringParserKindSingleWord }
+		case 1:
+			result = StringParserProperties { Kind: StringParserKindQuotablePhrase }
+		case 2:
+			result = StringParserProperties { Kind: StringParserKindGreedyPhrase }
+		default:
+			err = MalformedPacketError { fmt.Sprintf("Unrecognized string parser kind %d", kind) }
+		}
+
+		return
+	case "minecraft:entity":
+		var flags byte
+		flags, err = ReadUByte(stream)
+		if err != nil {
+			return
+		}
+
+		result = EntityParserProperties {
+			IsSingle: flags & 0x01 != 0,
+			IsPlayersOnly: flags & 0x02 != 0,
+		}
+		return
+	case "minecraft:score_holder":
+		var flags byte
+		flags, err = ReadUByte(stream)
+		if err != nil {
+			return
+		}
+
+		result = ScoreHolderParserProperties {
+			AllowMultiple: flags & 0x01 != 0,
+		}
+		return
+	case "minecraft:range":
+		var allowDecimals bool
+		allowDecimals, err = ReadBool(stream)
+		if err != nil {
+			return
+		}
+
+		result = RangeParserProperties {
+			AllowDecimals: allowDecimals,
+		}
+		return
+	default:
+		// Assumed to have no properties, which holds for every other parser in 1.14 and 1.15
+		return
+	}
+}
+
+func readParserNumber(parser string, stream *bufio.Reader) (float64, error) {
+	switch parser {
+	case "brigadier:double":
+		return ReadDouble(stream)
+	case "brigadier:float":
+		value, err := ReadFloat(stream)
+		return float64(value), err
+	default:
+		value, err := ReadInt(stream)
+		return float64(value), err
+	}
+}
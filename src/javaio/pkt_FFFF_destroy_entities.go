@@ -0,0 +1,53 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+type DestroyEntities struct {
+	EntityIds []int32
+}
+
+func WriteDestroyEntities(data DestroyEntities, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(int32(len(data.EntityIds)), stream) // potentially unsafe cast?
+	if err != nil {
+		return
+	}
+
+	for _, entityId := range data.EntityIds {
+		err = WriteVarInt(entityId, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func ReadDestroyEntities(stream *bufio.Reader) (result DestroyEntities, err error) {
+	count, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if count < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid entity count %d", count) }
+		return
+	}
+
+	entityIds := make([]int32, 0)
+
+	for i := int32(0); i < count; i++ {
+		var entityId int32
+		entityId, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		entityIds = append(entityIds, entityId)
+	}
+
+	result = DestroyEntities {
+		EntityIds: entityIds,
+	}
+	return
+}
@@ -0,0 +1,40 @@
+package javaio
+
+import "fmt"
+import "bufio"
+import "encoding/json"
+import "github.com/davidcallanan/go-mcp/chat"
+
+// Sent in both the login and play states, right before the server closes the connection.
+type Disconnect struct {
+	Reason chat.TextComponent
+}
+
+func WriteDisconnect(data Disconnect, stream *bufio.Writer) (err error) {
+	reason, err := json.Marshal(data.Reason)
+	if err != nil {
+		return
+	}
+
+	err = WriteString(string(reason), stream)
+	return
+}
+
+func ReadDisconnect(stream *bufio.Reader) (result Disconnect, err error) {
+	reasonJson, err := ReadString(stream, 262144)
+	if err != nil {
+		return
+	}
+
+	var reason chat.TextComponent
+	jsonErr := json.Unmarshal([]byte(reasonJson), &reason)
+	if jsonErr != nil {
+		err = MalformedPacketError { fmt.Sprintf("Invalid disconnect reason: %s", jsonErr) }
+		return
+	}
+
+	result = Disconnect {
+		Reason: reason,
+	}
+	return
+}
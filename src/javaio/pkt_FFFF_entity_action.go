@@ -0,0 +1,102 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+// Sent by the client when the player sneaks, sprints, leaves a bed, and so on.
+type EntityAction struct {
+	// Always the entity id of the player itself.
+	EntityId int32
+	Action PlayerAction
+	// From 0 to 100, only used when starting a horse jump.
+	JumpBoost int32
+}
+
+func WriteEntityAction(data EntityAction, stream *bufio.Writer) (err error) {
+	var actionId int32
+	switch data.Action {
+	case PlayerActionStartSneaking:
+		actionId = 0
+	case PlayerActionStopSneaking:
+		actionId = 1
+	case PlayerActionLeaveBed:
+		actionId = 2
+	case PlayerActionStartSprinting:
+		actionId = 3
+	case PlayerActionStopSprinting:
+		actionId = 4
+	case PlayerActionStartHorseJump:
+		actionId = 5
+	case PlayerActionStopHorseJump:
+		actionId = 6
+	case PlayerActionOpenHorseInventory:
+		actionId = 7
+	case PlayerActionStartElytraFlying:
+		actionId = 8
+	default:
+		err = FieldOutOfRangeError { "Action does not match one of non-invalid predefined enum types" }
+		return
+	}
+
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(actionId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.JumpBoost, stream)
+	return
+}
+
+func ReadEntityAction(stream *bufio.Reader) (result EntityAction, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	actionId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	var action PlayerAction
+	switch actionId {
+	case 0:
+		action = PlayerActionStartSneaking
+	case 1:
+		action = PlayerActionStopSneaking
+	case 2:
+		action = PlayerActionLeaveBed
+	case 3:
+		action = PlayerActionStartSprinting
+	case 4:
+		action = PlayerActionStopSprinting
+	case 5:
+		action = PlayerActionStartHorseJump
+	case 6:
+		action = PlayerActionStopHorseJump
+	case 7:
+		action = PlayerActionOpenHorseInventory
+	case 8:
+		action = PlayerActionStartElytraFlying
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized entity action id %d", actionId) }
+		return
+	}
+
+	jumpBoost, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	result = EntityAction {
+		EntityId: entityId,
+		Action: action,
+		JumpBoost: jumpBoost,
+	}
+	return
+}
@@ -12,27 +12,87 @@ type Packet_EntityTranslate struct {
 	OnGround bool
 }
 
-func PacketId_EntityTranslate(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x2A
-	} else {
-		// 1.14
-		return 0x29
+func Write_EntityTranslate(data Packet_EntityTranslate, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
 	}
-	// todo: older versions
-	return -1
+
+	err = WriteShort(data.DeltaX, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.DeltaY, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.DeltaZ, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Yaw, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Pitch, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
 }
 
-func Write_EntityTranslate(data Packet_EntityTranslate, stream *bufio.Writer) {
-	WriteVarInt(data.EntityId, stream)
-	WriteShort(data.DeltaX, stream)
-	WriteShort(data.DeltaY, stream)
-	WriteShort(data.DeltaZ, stream)
-	WriteUByte(data.Yaw, stream)
-	WriteUByte(data.Pitch, stream)
-	WriteBool(data.OnGround, stream)
+func Read_EntityTranslate(stream *bufio.Reader) (result Packet_EntityTranslate, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	deltaX, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	deltaY, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	deltaZ, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	yaw, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	pitch, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	onGround, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	result = Packet_EntityTranslate {
+		EntityId: entityId,
+		DeltaX: deltaX,
+		DeltaY: deltaY,
+		DeltaZ: deltaZ,
+		Yaw: yaw,
+		Pitch: pitch,
+		OnGround: onGround,
+	}
+	return
 }
 
 type Packet_EntityVelocity struct {
@@ -42,22 +102,214 @@ type Packet_EntityVelocity struct {
 	Z int16
 }
 
-func PacketId_EntityVelocity(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x46
-	} else {
-		// 1.14
-		return 0x45
+func Write_EntityVelocity(data Packet_EntityVelocity, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.X, stream)
+	if err != nil {
+		return
 	}
-	// todo: older versions
-	return -1
+
+	err = WriteShort(data.Y, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.Z, stream)
+	return
+}
+
+func Read_EntityVelocity(stream *bufio.Reader) (result Packet_EntityVelocity, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	x, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	y, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	z, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	result = Packet_EntityVelocity {
+		EntityId: entityId,
+		X: x,
+		Y: y,
+		Z: z,
+	}
+	return
 }
 
-func Write_EntityVelocity(data Packet_EntityVelocity, stream *bufio.Writer) {
-	WriteVarInt(data.EntityId, stream)
-	WriteShort(data.X, stream)
-	WriteShort(data.Y, stream)
-	WriteShort(data.Z, stream)
+// Like Packet_EntityTranslate, for an entity that has moved without turning.
+type EntityRelativeMove struct {
+	EntityId int32
+	DeltaX int16
+	DeltaY int16
+	DeltaZ int16
+	OnGround bool
+}
+
+func WriteEntityRelativeMove(data EntityRelativeMove, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.DeltaX, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.DeltaY, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteShort(data.DeltaZ, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
+}
+
+func ReadEntityRelativeMove(stream *bufio.Reader) (result EntityRelativeMove, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	deltaX, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	deltaY, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	deltaZ, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	onGround, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	result = EntityRelativeMove {
+		EntityId: entityId,
+		DeltaX: deltaX,
+		DeltaY: deltaY,
+		DeltaZ: deltaZ,
+		OnGround: onGround,
+	}
+	return
+}
+
+// Like Packet_EntityTranslate, for an entity that has turned without moving.
+type EntityLook struct {
+	EntityId int32
+	Yaw uint8
+	Pitch uint8
+	OnGround bool
+}
+
+func WriteEntityLook(data EntityLook, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Yaw, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Pitch, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
+}
+
+func ReadEntityLook(stream *bufio.Reader) (result EntityLook, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	yaw, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	pitch, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	onGround, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	result = EntityLook {
+		EntityId: entityId,
+		Yaw: yaw,
+		Pitch: pitch,
+		OnGround: onGround,
+	}
+	return
+}
+
+// Turns the head of an entity independently of its body.
+type EntityHeadLook struct {
+	EntityId int32
+	HeadYaw uint8
+}
+
+func WriteEntityHeadLook(data EntityHeadLook, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.HeadYaw, stream)
+	return
+}
+
+func ReadEntityHeadLook(stream *bufio.Reader) (result EntityHeadLook, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	headYaw, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	result = EntityHeadLook {
+		EntityId: entityId,
+		HeadYaw: headYaw,
+	}
+	return
 }
@@ -0,0 +1,97 @@
+package javaio
+
+import "bufio"
+
+// Moves an entity to an absolute position, for moves too far to describe with a relative move.
+type EntityTeleport struct {
+	EntityId int32
+	X float64
+	Y float64
+	Z float64
+	Yaw uint8
+	Pitch uint8
+	OnGround bool
+}
+
+func WriteEntityTeleport(data EntityTeleport, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.X, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Y, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Z, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Yaw, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Pitch, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
+}
+
+func ReadEntityTeleport(stream *bufio.Reader) (result EntityTeleport, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	x, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	y, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	z, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	yaw, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	pitch, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	onGround, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	result = EntityTeleport {
+		EntityId: entityId,
+		X: x,
+		Y: y,
+		Z: z,
+		Yaw: yaw,
+		Pitch: pitch,
+		OnGround: onGround,
+	}
+	return
+}
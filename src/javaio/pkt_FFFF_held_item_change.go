@@ -0,0 +1,37 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+// Sent by the client when the player selects a different hotbar slot.
+type HeldItemChange struct {
+	// From 0 to 8.
+	Slot int16
+}
+
+func WriteHeldItemChange(data HeldItemChange, stream *bufio.Writer) (err error) {
+	if data.Slot < 0 || data.Slot > 8 {
+		err = FieldOutOfRangeError { fmt.Sprintf("Hotbar slot %d does not exist", data.Slot) }
+		return
+	}
+
+	err = WriteShort(data.Slot, stream)
+	return
+}
+
+func ReadHeldItemChange(stream *bufio.Reader) (result HeldItemChange, err error) {
+	slot, err := ReadShort(stream)
+	if err != nil {
+		return
+	}
+
+	if slot < 0 || slot > 8 {
+		err = MalformedPacketError { fmt.Sprintf("Hotbar slot %d does not exist", slot) }
+		return
+	}
+
+	result = HeldItemChange {
+		Slot: slot,
+	}
+	return
+}
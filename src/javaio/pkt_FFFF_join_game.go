@@ -1,5 +1,6 @@
 package javaio
 
+import "fmt"
 import "bufio"
 
 // TODO: figure out prot version
@@ -14,21 +15,11 @@ type JoinGame struct {
 	EnableRespawnScreen bool
 }
 
-func PacketId_JoinGame(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x26
-	} else {
-		// 1.14
-		return 0x25
-	}
-	// older versions not supported
-	return -1
-}
-
-func WriteJoinGame(data JoinGame, ctx ClientContext, stream *bufio.Writer) {
-	WriteInt(data.EntityId, stream)
+func WriteJoinGame(data JoinGame, ctx ClientContext, stream *bufio.Writer) (err error) {
+	err = WriteInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
 
 	var gamemode byte
 	switch data.Gamemode {
@@ -41,7 +32,8 @@ func WriteJoinGame(data JoinGame, ctx ClientContext, stream *bufio.Writer) {
 	case GamemodeSpectator:
 		gamemode = 3
 	default:
-		panic("Gamemode does not match one of non-invalid predefined enum types")
+		err = FieldOutOfRangeError { "Gamemode does not match one of non-invalid predefined enum types" }
+		return
 	}
 
 	if data.Hardcore {
@@ -49,7 +41,10 @@ func WriteJoinGame(data JoinGame, ctx ClientContext, stream *bufio.Writer) {
 		gamemode |= 0x8
 	}	
 
-	WriteUByte(gamemode, stream)
+	err = WriteUByte(gamemode, stream)
+	if err != nil {
+		return
+	}
 
 	var dimension int32
 	switch data.Dimension {
@@ -60,32 +55,151 @@ func WriteJoinGame(data JoinGame, ctx ClientContext, stream *bufio.Writer) {
 	case DimensionEnd:
 		dimension = 1
 	default:
-		panic("Dimension does not match one of non-invalid predefined enum types")
+		err = FieldOutOfRangeError { "Dimension does not match one of non-invalid predefined enum types" }
+		return
 	}
 
-	WriteInt(dimension, stream)
+	err = WriteInt(dimension, stream)
+	if err != nil {
+		return
+	}
 
 	if ctx.Protocol > 0x0286 { // approximation
 		// only neccessary in 1.15
 		var hashedSeed int64 = 0 // seems kind of useless, maybe used for biome interpolation
-		WriteLong(hashedSeed, stream)
+		err = WriteLong(hashedSeed, stream)
+		if err != nil {
+			return
+		}
 	}
 	
 	var maxPlayers byte = 0 // no longer utilized by client
-	WriteUByte(maxPlayers, stream)
+	err = WriteUByte(maxPlayers, stream)
+	if err != nil {
+		return
+	}
 
 	var levelType string = "default" // seems kind of useless
-	WriteString(levelType, stream)
+	err = WriteString(levelType, stream)
+	if err != nil {
+		return
+	}
 
 	if data.ViewDistance > 32 {
-		panic("View distance must not be greater than 32")
+		err = FieldOutOfRangeError { "View distance must not be greater than 32" }
+		return
+	}
+
+	err = WriteVarInt(data.ViewDistance, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.ReducedDebugInfo, stream)
+	if err != nil {
+		return
+	}
+
+	if ctx.Protocol > 0x0286 { // approximation
+		// only available in 1.15
+		err = WriteBool(data.EnableRespawnScreen, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func ReadJoinGame(stream *bufio.Reader, ctx ClientContext) (result JoinGame, err error) {
+	entityId, err := ReadInt(stream)
+	if err != nil {
+		return
+	}
+
+	gamemodeId, err := ReadUByte(stream)
+	if err != nil {
+		return
 	}
 
-	WriteVarInt(data.ViewDistance, stream)
-	WriteBool(data.ReducedDebugInfo, stream)
+	var gamemode Gamemode
+	switch gamemodeId & 0x7 {
+	case 0:
+		gamemode = GamemodeSurvival
+	case 1:
+		gamemode = GamemodeCreative
+	case 2:
+		gamemode = GamemodeAdventure
+	case 3:
+		gamemode = GamemodeSpectator
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized gamemode id %d", gamemodeId) }
+		return
+	}
+
+	dimensionId, err := ReadInt(stream)
+	if err != nil {
+		return
+	}
+
+	var dimension Dimension
+	switch dimensionId {
+	case 0:
+		dimension = DimensionOverworld
+	case -1:
+		dimension = DimensionNether
+	case 1:
+		dimension = DimensionEnd
+	default:
+		err = MalformedPacketError { fmt.Sprintf("Unrecognized dimension id %d", dimensionId) }
+		return
+	}
 
+	if ctx.Protocol > 0x0286 { // approximation
+		// only neccessary in 1.15
+		_, err = ReadLong(stream) // hashed seed
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = ReadUByte(stream) // max players
+	if err != nil {
+		return
+	}
+
+	_, err = ReadString(stream, 16) // level type
+	if err != nil {
+		return
+	}
+
+	viewDistance, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	reducedDebugInfo, err := ReadBool(stream)
+	if err != nil {
+		return
+	}
+
+	enableRespawnScreen := false
 	if ctx.Protocol > 0x0286 { // approximation
 		// only available in 1.15
-		WriteBool(data.EnableRespawnScreen, stream)
+		enableRespawnScreen, err = ReadBool(stream)
+		if err != nil {
+			return
+		}
+	}
+
+	result = JoinGame {
+		EntityId: entityId,
+		Gamemode: gamemode,
+		Hardcore: gamemodeId & 0x8 != 0,
+		Dimension: dimension,
+		ViewDistance: viewDistance,
+		ReducedDebugInfo: reducedDebugInfo,
+		EnableRespawnScreen: enableRespawnScreen,
 	}
+	return
 }
@@ -8,18 +8,19 @@ type KeepAlive struct {
 	Payload int64
 }
 
-func PacketId_KeepAlive(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x21
-	} else {
-		// 1.14
-		return 0x20
-	}
-	// todo older versions
+func WriteKeepAlive(data KeepAlive, stream *bufio.Writer) (err error) {
+	err = WriteLong(data.Payload, stream)
+	return
 }
 
-func WriteKeepAlive(data KeepAlive, stream *bufio.Writer) {
-	WriteLong(data.Payload, stream)
+func ReadKeepAlive(stream *bufio.Reader) (result KeepAlive, err error) {
+	payload, err := ReadLong(stream)
+	if err != nil {
+		return
+	}
+
+	result = KeepAlive {
+		Payload: payload,
+	}
+	return
 }
@@ -0,0 +1,77 @@
+package javaio
+
+import "bufio"
+
+// Sent by the client when the player starts or stops flying.
+// The client sends all of its abilities, but only IsFlying is meant to be changed by it.
+type PlayerAbilitiesSb struct {
+	IsInvulnerable bool
+	IsFlying bool
+	AllowFlying bool
+	IsCreative bool
+	FlyingSpeed float32
+	WalkingSpeed float32
+}
+
+const (
+	abilityInvulnerable = 0x01
+	abilityFlying = 0x02
+	abilityAllowFlying = 0x04
+	abilityCreative = 0x08
+)
+
+func WritePlayerAbilitiesSb(data PlayerAbilitiesSb, stream *bufio.Writer) (err error) {
+	var flags byte
+	if data.IsInvulnerable {
+		flags |= abilityInvulnerable
+	}
+	if data.IsFlying {
+		flags |= abilityFlying
+	}
+	if data.AllowFlying {
+		flags |= abilityAllowFlying
+	}
+	if data.IsCreative {
+		flags |= abilityCreative
+	}
+
+	err = WriteUByte(flags, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteFloat(data.FlyingSpeed, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteFloat(data.WalkingSpeed, stream)
+	return
+}
+
+func ReadPlayerAbilitiesSb(stream *bufio.Reader) (result PlayerAbilitiesSb, err error) {
+	flags, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	flyingSpeed, err := ReadFloat(stream)
+	if err != nil {
+		return
+	}
+
+	walkingSpeed, err := ReadFloat(stream)
+	if err != nil {
+		return
+	}
+
+	result = PlayerAbilitiesSb {
+		IsInvulnerable: flags & abilityInvulnerable != 0,
+		IsFlying: flags & abilityFlying != 0,
+		AllowFlying: flags & abilityAllowFlying != 0,
+		IsCreative: flags & abilityCreative != 0,
+		FlyingSpeed: flyingSpeed,
+		WalkingSpeed: walkingSpeed,
+	}
+	return
+}
@@ -1,5 +1,6 @@
 package javaio
 
+import "fmt"
 import "bufio"
 import "github.com/google/uuid"
 
@@ -7,37 +8,267 @@ type PlayerInfoAdd struct {
 	Players []PlayerInfo
 }
 
+// Updates the latency shown in the tab list.
+type PlayerInfoUpdateLatency struct {
+	Players []PlayerLatency
+}
+
+type PlayerLatency struct {
+	Uuid uuid.UUID
+	// In milliseconds.
+	Ping int32
+}
+
+// Removes players from the tab list.
+type PlayerInfoRemove struct {
+	Uuids []uuid.UUID
+}
+
 type PlayerInfo struct {
 	Uuid uuid.UUID
 	Username string
 	Ping int32
 }
 
-func PacketId_PlayerInfo(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x34
-	} else {
-		// 1.14
-		return 0x33
+func WritePlayerInfoAdd(data PlayerInfoAdd, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(0, stream) // action 0: add players
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(int32(len(data.Players)), stream) // potentially unsafe cast?
+	if err != nil {
+		return
+	}
+
+	for _, player := range data.Players {
+		err = WriteUuidBin(player.Uuid, stream)
+		if err != nil {
+			return
+		}
+
+		err = WriteString(player.Username, stream)
+		if err != nil {
+			return
+		}
+
+		err = WriteVarInt(0, stream) // property count; no properties for now
+		if err != nil {
+			return
+		}
+
+		err = WriteVarInt(0, stream) // gamemode survival; not worried about this for now
+		if err != nil {
+			return
+		}
+
+		err = WriteVarInt(player.Ping, stream)
+		if err != nil {
+			return
+		}
+
+		err = WriteBool(false, stream) // has display name; false for now
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Reads a PlayerInfoAdd or a PlayerInfoRemove, depending on the action of the packet.
+func ReadPlayerInfo(stream *bufio.Reader) (result interface{}, err error) {
+	action, err := ReadVarInt(stream)
+	if err != nil {
+		return
 	}
-	// todo older versions
+
+	switch action {
+	case 0:
+		result, err = readPlayerInfoAdd(stream)
+	case 2:
+		result, err = readPlayerInfoUpdateLatency(stream)
+	case 4:
+		result, err = readPlayerInfoRemove(stream)
+	default:
+		err = UnsupportedPayloadError { fmt.Sprintf("Player info action %d is not supported", action) }
+	}
+
+	return
 }
 
-func WritePlayerInfoAdd(data PlayerInfoAdd, stream *bufio.Writer) {
-	// WARNING: Do not use negative numbers for ping!
-	// VarInt does not work correctly with negative numbers yet
-	
-	WriteVarInt(0, stream) // action 0: add players
-	WriteVarInt(int32(len(data.Players)), stream) // potentially unsafe cast?
+func readPlayerInfoAdd(stream *bufio.Reader) (result PlayerInfoAdd, err error) {
+	count, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if count < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid player count %d", count) }
+		return
+	}
+
+	players := make([]PlayerInfo, 0)
+
+	for i := int32(0); i < count; i++ {
+		var player PlayerInfo
+		player.Uuid, err = ReadUuidBin(stream)
+		if err != nil {
+			return
+		}
+
+		player.Username, err = ReadString(stream, 16)
+		if err != nil {
+			return
+		}
+
+		var propertyCount int32
+		propertyCount, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		if propertyCount != 0 {
+			err = UnsupportedPayloadError { "Player properties are not supported" }
+			return
+		}
+
+		_, err = ReadVarInt(stream) // gamemode
+		if err != nil {
+			return
+		}
+
+		player.Ping, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		var hasDisplayName bool
+		hasDisplayName, err = ReadBool(stream)
+		if err != nil {
+			return
+		}
+
+		if hasDisplayName {
+			err = UnsupportedPayloadError { "Player display names are not supported" }
+			return
+		}
+
+		players = append(players, player)
+	}
+
+	result = PlayerInfoAdd {
+		Players: players,
+	}
+	return
+}
+
+func WritePlayerInfoUpdateLatency(data PlayerInfoUpdateLatency, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(2, stream) // action 2: update latency
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(int32(len(data.Players)), stream) // potentially unsafe cast?
+	if err != nil {
+		return
+	}
 
 	for _, player := range data.Players {
-		WriteUuidBin(player.Uuid, stream)
-		WriteString(player.Username, stream)
-		WriteVarInt(0, stream) // property count; no properties for now
-		WriteVarInt(0, stream) // gamemode survival; not worried about this for now
-		WriteVarInt(player.Ping, stream)
-		WriteBool(false, stream) // has display name; false for now
+		err = WriteUuidBin(player.Uuid, stream)
+		if err != nil {
+			return
+		}
+
+		err = WriteVarInt(player.Ping, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func readPlayerInfoUpdateLatency(stream *bufio.Reader) (result PlayerInfoUpdateLatency, err error) {
+	count, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if count < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid player count %d", count) }
+		return
+	}
+
+	players := make([]PlayerLatency, 0)
+
+	for i := int32(0); i < count; i++ {
+		var player PlayerLatency
+		player.Uuid, err = ReadUuidBin(stream)
+		if err != nil {
+			return
+		}
+
+		player.Ping, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		players = append(players, player)
+	}
+
+	result = PlayerInfoUpdateLatency {
+		Players: players,
+	}
+	return
+}
+
+func WritePlayerInfoRemove(data PlayerInfoRemove, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(4, stream) // action 4: remove players
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(int32(len(data.Uuids)), stream) // potentially unsafe cast?
+	if err != nil {
+		return
+	}
+
+	for _, playerUuid := range data.Uuids {
+		err = WriteUuidBin(playerUuid, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func readPlayerInfoRemove(stream *bufio.Reader) (result PlayerInfoRemove, err error) {
+	count, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if count < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid player count %d", count) }
+		return
+	}
+
+	uuids := make([]uuid.UUID, 0)
+
+	for i := int32(0); i < count; i++ {
+		var playerUuid uuid.UUID
+		playerUuid, err = ReadUuidBin(stream)
+		if err != nil {
+			return
+		}
+
+		uuids = append(uuids, playerUuid)
+	}
+
+	result = PlayerInfoRemove {
+		Uuids: uuids,
 	}
+	return
 }
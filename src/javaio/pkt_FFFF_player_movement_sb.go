@@ -24,46 +24,7 @@ type Packet_PlayerPosAndLookSb struct {
 	OnGround bool
 }
 
-// TODO: player actions: onground, sprint, sneak, etc.
-
-func PacketId_PlayerPosSb(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x11
-	} else {
-		// 1.14
-		return 0x11
-	}
-	// older versions not supported
-	return -1
-}
-
-func PacketId_PlayerLookSb(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x13
-	} else {
-		// 1.14
-		return 0x13
-	}
-	// older versions not supported
-	return -1
-}
-
-func PacketId_PlayerPosAndLookSb(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x12
-	} else {
-		// 1.14
-		return 0x12
-	}
-	// older versions not supported
-	return -1
-}
+// Sneaking and sprinting are sent separately, see EntityAction.
 
 func Read_PlayerPosSb(stream *bufio.Reader) (result Packet_PlayerPosSb, err error) {
 	x, err := ReadDouble(stream)
@@ -160,3 +121,68 @@ func Read_PlayerPosAndLookSb(stream *bufio.Reader) (result Packet_PlayerPosAndLo
 	}
 	return
 }
+
+func Write_PlayerPosSb(data Packet_PlayerPosSb, stream *bufio.Writer) (err error) {
+	err = WriteDouble(data.X, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Y, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Z, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
+}
+
+func Write_PlayerLookSb(data Packet_PlayerLookSb, stream *bufio.Writer) (err error) {
+	err = WriteFloat(data.Yaw, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteFloat(data.Pitch, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
+}
+
+func Write_PlayerPosAndLookSb(data Packet_PlayerPosAndLookSb, stream *bufio.Writer) (err error) {
+	err = WriteDouble(data.X, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Y, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Z, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteFloat(data.Yaw, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteFloat(data.Pitch, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteBool(data.OnGround, stream)
+	return
+}
@@ -17,25 +17,31 @@ type PlayerPositionAndLook struct {
 	IsRelPitch bool
 }
 
-func PacketId_PlayerPositionAndLook(protocol uint) int {
-	// TODO: this is an approximation
-	if protocol >= 0x0286 {
-		// 1.15
-		return 0x36
-	} else {
-		// 1.14
-		return 0x35
-	}
-	// older versions not supported
-	return -1
-}
+func WritePlayerPositionAndLook(data PlayerPositionAndLook, stream *bufio.Writer) (err error) {
+	err = WriteDouble(data.X, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Y, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Z, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteFloat(data.Yaw, stream)
+	if err != nil {
+		return
+	}
 
-func WritePlayerPositionAndLook(data PlayerPositionAndLook, stream *bufio.Writer) {
-	WriteDouble(data.X, stream)
-	WriteDouble(data.Y, stream)
-	WriteDouble(data.Z, stream)
-	WriteFloat(data.Yaw, stream)
-	WriteFloat(data.Pitch, stream)
+	err = WriteFloat(data.Pitch, stream)
+	if err != nil {
+		return
+	}
 
 	var flags byte
 
@@ -49,16 +55,71 @@ func WritePlayerPositionAndLook(data PlayerPositionAndLook, stream *bufio.Writer
 		flags |= 0x04
 	}
 	if data.IsRelYaw {
-		flags |= 0x10
+		flags |= 0x08
 	}
 	if data.IsRelPitch {
-		flags |= 0x08
+		flags |= 0x10
 	}
 
-	WriteUByte(flags, stream)
+	err = WriteUByte(flags, stream)
+	if err != nil {
+		return
+	}
 
 	// Seems pointless for now.
 	// Probably useful for interpolation, etc.
 	var teleportId int32 = 0
-	WriteVarInt(teleportId, stream)
+	err = WriteVarInt(teleportId, stream)
+	return
+}
+
+func ReadPlayerPositionAndLook(stream *bufio.Reader) (result PlayerPositionAndLook, err error) {
+	x, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	y, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	z, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	yaw, err := ReadFloat(stream)
+	if err != nil {
+		return
+	}
+
+	pitch, err := ReadFloat(stream)
+	if err != nil {
+		return
+	}
+
+	flags, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	_, err = ReadVarInt(stream) // teleport id
+	if err != nil {
+		return
+	}
+
+	result = PlayerPositionAndLook {
+		X: x,
+		Y: y,
+		Z: z,
+		Yaw: yaw,
+		Pitch: pitch,
+		IsRelX: flags & 0x01 != 0,
+		IsRelY: flags & 0x02 != 0,
+		IsRelZ: flags & 0x04 != 0,
+		IsRelYaw: flags & 0x08 != 0,
+		IsRelPitch: flags & 0x10 != 0,
+	}
+	return
 }
@@ -0,0 +1,141 @@
+package javaio
+
+import "bufio"
+import "github.com/google/uuid"
+
+// Spawns a mob, such as a pig or a zombie. Players are spawned with Packet_SpawnPlayer instead.
+type SpawnLivingEntity struct {
+	EntityId int32
+	Uuid uuid.UUID
+	// The id of the entity type, which differs between versions.
+	Type int32
+	X float64
+	Y float64
+	Z float64
+	Yaw uint8
+	Pitch uint8
+	// Sometimes documented as the head pitch, but the client turns the head by it like EntityHeadLook.
+	HeadYaw uint8
+	// In units of 1/8000 of a block per tick.
+	VelocityX int16
+	VelocityY int16
+	VelocityZ int16
+}
+
+func WriteSpawnLivingEntity(data SpawnLivingEntity, ctx ClientContext, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUuidBin(data.Uuid, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.Type, stream)
+	if err != nil {
+		return
+	}
+
+	for _, value := range []float64 { data.X, data.Y, data.Z } {
+		err = WriteDouble(value, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, value := range []uint8 { data.Yaw, data.Pitch, data.HeadYaw } {
+		err = WriteUByte(value, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, value := range []int16 { data.VelocityX, data.VelocityY, data.VelocityZ } {
+		err = WriteShort(value, stream)
+		if err != nil {
+			return
+		}
+	}
+
+	if ctx.Protocol < 0x0286 {
+		// Like Packet_SpawnPlayer, entity metadata must be sent before 1.15
+		err = WriteUByte(0xff, stream) // end of entity metadata; no metadata sent for now
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func ReadSpawnLivingEntity(stream *bufio.Reader, ctx ClientContext) (result SpawnLivingEntity, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	entityUuid, err := ReadUuidBin(stream)
+	if err != nil {
+		return
+	}
+
+	entityType, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	var position [3]float64
+	for i := range position {
+		position[i], err = ReadDouble(stream)
+		if err != nil {
+			return
+		}
+	}
+
+	var angles [3]uint8
+	for i := range angles {
+		angles[i], err = ReadUByte(stream)
+		if err != nil {
+			return
+		}
+	}
+
+	var velocity [3]int16
+	for i := range velocity {
+		velocity[i], err = ReadShort(stream)
+		if err != nil {
+			return
+		}
+	}
+
+	if ctx.Protocol < 0x0286 {
+		metadataEnd, readErr := ReadUByte(stream)
+		if readErr != nil {
+			err = readErr
+			return
+		}
+
+		if metadataEnd != 0xff {
+			err = UnsupportedPayloadError { "Entity metadata is not supported" }
+			return
+		}
+	}
+
+	result = SpawnLivingEntity {
+		EntityId: entityId,
+		Uuid: entityUuid,
+		Type: entityType,
+		X: position[0],
+		Y: position[1],
+		Z: position[2],
+		Yaw: angles[0],
+		Pitch: angles[1],
+		HeadYaw: angles[2],
+		VelocityX: velocity[0],
+		VelocityY: velocity[1],
+		VelocityZ: velocity[2],
+	}
+	return
+}
@@ -13,24 +13,112 @@ type Packet_SpawnPlayer struct {
 	Pitch uint8
 }
 
-func PacketId_SpawnPlayer(protocol uint) int32 {
-	// 1.15 and 1.14
-	// todo: older versions not supported
-	return 0x05
-}
+func Write_SpawnPlayer(data Packet_SpawnPlayer, ctx ClientContext, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.EntityId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUuidBin(data.Uuid, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.X, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Y, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteDouble(data.Z, stream)
+	if err != nil {
+		return
+	}
 
-func Write_SpawnPlayer(data Packet_SpawnPlayer, ctx ClientContext, stream *bufio.Writer) {
-	WriteVarInt(data.EntityId, stream)
-	WriteUuidBin(data.Uuid, stream)
-	WriteDouble(data.X, stream)
-	WriteDouble(data.Y, stream)
-	WriteDouble(data.Z, stream)
-	WriteUByte(data.Yaw, stream)
-	WriteUByte(data.Pitch, stream)
+	err = WriteUByte(data.Yaw, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(data.Pitch, stream)
+	if err != nil {
+		return
+	}
 
 	if ctx.Protocol < 0x0286 {
 		// 1.14 approximation
 		// entity metadata must be sent in this version
-		WriteUByte(0xff, stream) // end of entity metadata; no metadata sent for now
+		err = WriteUByte(0xff, stream) // end of entity metadata; no metadata sent for now
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func Read_SpawnPlayer(stream *bufio.Reader, ctx ClientContext) (result Packet_SpawnPlayer, err error) {
+	entityId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	playerUuid, err := ReadUuidBin(stream)
+	if err != nil {
+		return
+	}
+
+	x, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	y, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	z, err := ReadDouble(stream)
+	if err != nil {
+		return
+	}
+
+	yaw, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	pitch, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	if ctx.Protocol < 0x0286 {
+		// 1.14 approximation
+		metadataEnd, readErr := ReadUByte(stream)
+		if readErr != nil {
+			err = readErr
+			return
+		}
+
+		if metadataEnd != 0xff {
+			err = UnsupportedPayloadError { "Entity metadata is not supported" }
+			return
+		}
+	}
+
+	result = Packet_SpawnPlayer {
+		EntityId: entityId,
+		Uuid: playerUuid,
+		X: x,
+		Y: y,
+		Z: z,
+		Yaw: yaw,
+		Pitch: pitch,
 	}
+	return
 }
@@ -0,0 +1,175 @@
+package javaio
+
+import "fmt"
+import "bufio"
+import "encoding/json"
+import "github.com/davidcallanan/go-mcp/chat"
+
+// Sent by the client to ask for suggestions for a partly typed command.
+type TabCompleteSb struct {
+	// Echoed in the answer.
+	TransactionId int32
+	// Everything before the cursor, including the leading slash.
+	Text string
+}
+
+func WriteTabCompleteSb(data TabCompleteSb, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.TransactionId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteString(data.Text, stream)
+	return
+}
+
+func ReadTabCompleteSb(stream *bufio.Reader) (result TabCompleteSb, err error) {
+	transactionId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	text, err := ReadString(stream, 32500)
+	if err != nil {
+		return
+	}
+
+	result = TabCompleteSb {
+		TransactionId: transactionId,
+		Text: text,
+	}
+	return
+}
+
+// Answers a TabCompleteSb. The matches replace the text from Start, which is Length characters long.
+type TabComplete struct {
+	TransactionId int32
+	Start int32
+	Length int32
+	Matches []TabCompleteMatch
+}
+
+type TabCompleteMatch struct {
+	Match string
+	// Shown when hovering over the match, if not nil.
+	Tooltip *chat.TextComponent
+}
+
+func WriteTabComplete(data TabComplete, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.TransactionId, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.Start, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(data.Length, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteVarInt(int32(len(data.Matches)), stream)
+	if err != nil {
+		return
+	}
+
+	for _, match := range data.Matches {
+		err = WriteString(match.Match, stream)
+		if err != nil {
+			return
+		}
+
+		err = WriteBool(match.Tooltip != nil, stream)
+		if err != nil {
+			return
+		}
+
+		if match.Tooltip != nil {
+			var tooltip []byte
+			tooltip, err = json.Marshal(*match.Tooltip)
+			if err != nil {
+				return
+			}
+
+			err = WriteString(string(tooltip), stream)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func ReadTabComplete(stream *bufio.Reader) (result TabComplete, err error) {
+	transactionId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	start, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	length, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	count, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if count < 0 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid match count %d", count) }
+		return
+	}
+
+	matches := make([]TabCompleteMatch, 0)
+
+	for i := int32(0); i < count; i++ {
+		var match TabCompleteMatch
+		match.Match, err = ReadString(stream, 32767)
+		if err != nil {
+			return
+		}
+
+		var hasTooltip bool
+		hasTooltip, err = ReadBool(stream)
+		if err != nil {
+			return
+		}
+
+		if hasTooltip {
+			var tooltipJson string
+			tooltipJson, err = ReadString(stream, 262144)
+			if err != nil {
+				return
+			}
+
+			var tooltip chat.TextComponent
+			jsonErr := json.Unmarshal([]byte(tooltipJson), &tooltip)
+			if jsonErr != nil {
+				err = MalformedPacketError { fmt.Sprintf("Invalid tooltip: %s", jsonErr) }
+				return
+			}
+
+			match.Tooltip = &tooltip
+		}
+
+		matches = append(matches, match)
+	}
+
+	result = TabComplete {
+		TransactionId: transactionId,
+		Start: start,
+		Length: length,
+		Matches: matches,
+	}
+	return
+}
@@ -0,0 +1,25 @@
+package javaio
+
+import "bufio"
+
+// Sent by the client once it has moved to the position of a PlayerPositionAndLook.
+type TeleportConfirm struct {
+	TeleportId int32
+}
+
+func WriteTeleportConfirm(data TeleportConfirm, stream *bufio.Writer) (err error) {
+	err = WriteVarInt(data.TeleportId, stream)
+	return
+}
+
+func ReadTeleportConfirm(stream *bufio.Reader) (result TeleportConfirm, err error) {
+	teleportId, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	result = TeleportConfirm {
+		TeleportId: teleportId,
+	}
+	return
+}
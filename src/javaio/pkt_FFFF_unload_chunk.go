@@ -0,0 +1,37 @@
+package javaio
+
+import "bufio"
+
+// Tells the client to forget a chunk, once it is too far away from the player.
+type UnloadChunk struct {
+	X int32
+	Z int32
+}
+
+func WriteUnloadChunk(data UnloadChunk, stream *bufio.Writer) (err error) {
+	err = WriteInt(data.X, stream)
+	if err != nil {
+		return
+	}
+
+	err = WriteInt(data.Z, stream)
+	return
+}
+
+func ReadUnloadChunk(stream *bufio.Reader) (result UnloadChunk, err error) {
+	x, err := ReadInt(stream)
+	if err != nil {
+		return
+	}
+
+	z, err := ReadInt(stream)
+	if err != nil {
+		return
+	}
+
+	result = UnloadChunk {
+		X: x,
+		Z: z,
+	}
+	return
+}
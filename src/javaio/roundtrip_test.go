@@ -0,0 +1,298 @@
+package javaio
+
+import "io"
+import "bufio"
+import "bytes"
+import "reflect"
+import "testing"
+import "github.com/google/uuid"
+import "github.com/davidcallanan/go-mcp/chat"
+
+var roundTripProtocols = []struct {
+	name string
+	protocol uint
+} {
+	{"1.14", 0x022E},
+	{"1.15", 0x028E},
+}
+
+func testBlocks() []uint32 {
+	blocks := make([]uint32, 4096)
+	for i := range blocks {
+		blocks[i] = uint32(i * 7 % 600)
+	}
+	return blocks
+}
+
+var clientboundRoundTrips = []struct {
+	state State
+	packet interface{}
+} {
+	{StateStatus, Packet_0051_StatusResponse {
+		Protocol: 578,
+		Version: "1.15.2",
+		Description: chat.TextComponent { Text: "Hello", Color: "yellow" },
+		FaviconPng: []byte {0x89, 'P', 'N', 'G'},
+		MaxPlayers: 20,
+		OnlinePlayers: 1,
+		PlayerSample: []Packet_0051_StatusResponse_Player {
+			{ Name: "Notch", Uuid: "069a79f4-44e9-4726-a5be-fca90e38aaf5" },
+		},
+	}},
+	{StateStatus, Packet_0051_Pong { Payload: -12345 }},
+	{StateLogin, EncryptionRequest { ServerId: "", PublicKey: []byte {1, 2, 3}, VerifyToken: []byte {4, 5, 6, 7} }},
+	{StateLogin, LoginSuccess { Uuid: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"), Username: "Notch" }},
+	{StateLogin, SetCompression { Threshold: 256 }},
+	{StateLogin, Disconnect { Reason: chat.TextComponent { Text: "The server is full", Color: "red" } }},
+	{StatePlay, Disconnect { Reason: chat.Text("Kicked by an operator") }},
+	{StatePlay, KeepAlive { Payload: 0x0102030405060708 }},
+	{StatePlay, JoinGame {
+		EntityId: 42,
+		Gamemode: GamemodeCreative,
+		Hardcore: true,
+		Dimension: DimensionNether,
+		ViewDistance: 10,
+		ReducedDebugInfo: true,
+	}},
+	{StatePlay, CompassPosition { Location: BlockPosition { X: -100, Y: 64, Z: 33554431 } }},
+	{StatePlay, BlockChange { Location: BlockPosition { X: -7, Y: 255, Z: 12 }, BlockState: 9 }},
+	{StatePlay, UnloadChunk { X: -3, Z: 1875000 }},
+	{StatePlay, UpdateViewPosition { ChunkX: -3, ChunkZ: 7 }},
+	{StatePlay, UpdateViewDistance { ViewDistance: 12 }},
+	{StatePlay, PlayerPositionAndLook { X: 1.5, Y: 70, Z: -3.25, Yaw: 90, Pitch: -45, IsRelY: true, IsRelYaw: true }},
+	{StatePlay, ChunkData { X: -3, Z: 7, IsNew: true, Sections: [][]uint32 {nil, testBlocks(), nil, testBlocks()} }},
+	{StatePlay, PlayerInfoAdd { Players: []PlayerInfo {
+		{ Uuid: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"), Username: "Notch", Ping: 25 },
+	}}},
+	{StatePlay, Packet_SpawnPlayer {
+		EntityId: 7,
+		Uuid: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"),
+		X: 10, Y: 64, Z: -10,
+		Yaw: 128, Pitch: 64,
+	}},
+	{StatePlay, SpawnLivingEntity {
+		EntityId: 8,
+		Uuid: uuid.MustParse("1f3a6ef2-4b0c-4d5e-9f60-7a8b9c0d1e2f"),
+		Type: 54,
+		X: -3.5, Y: 70, Z: 12.25,
+		Yaw: 32, Pitch: 250, HeadYaw: 16,
+		VelocityX: -400, VelocityY: 0, VelocityZ: 8000,
+	}},
+	{StatePlay, Packet_EntityTranslate { EntityId: 7, DeltaX: -4096, DeltaY: 0, DeltaZ: 4095, Yaw: 1, Pitch: 2, OnGround: true }},
+	{StatePlay, PlayerInfoUpdateLatency { Players: []PlayerLatency {
+		{ Uuid: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"), Ping: 120 },
+	}}},
+	{StatePlay, PlayerInfoRemove { Uuids: []uuid.UUID {uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")} }},
+	{StatePlay, DestroyEntities { EntityIds: []int32 {7, 300000} }},
+	{StatePlay, ChatMessage { Message: chat.TextComponent { Translate: "chat.type.text", With: []chat.TextComponent {chat.Text("Notch"), chat.Text("Hello")} }, Position: ChatPositionChat }},
+	{StatePlay, ChatMessage { Message: chat.Text("Saved the game"), Position: ChatPositionSystem }},
+	{StatePlay, ChatMessage { Message: chat.TextComponent { Text: "Low health", Color: "red" }, Position: ChatPositionActionBar }},
+	{StatePlay, TabComplete { TransactionId: 3, Start: 4, Length: 2, Matches: []TabCompleteMatch {
+		{ Match: "Notch" },
+		{ Match: "jeb_", Tooltip: &chat.TextComponent { Text: "Online", Color: "green" } },
+	}}},
+	{StatePlay, DeclareCommands { RootIndex: 0, Nodes: []CommandNode {
+		{ Type: CommandNodeTypeRoot, Children: []int32 {1, 6} },
+		{ Type: CommandNodeTypeLiteral, Name: "tp", Children: []int32 {2, 3} },
+		{ Type: CommandNodeTypeArgument, Name: "target", Parser: "minecraft:entity", Properties: EntityParserProperties { IsSingle: true }, IsExecutable: true, Children: []int32 {} },
+		{ Type: CommandNodeTypeArgument, Name: "x", Parser: "brigadier:double", Properties: NumberParserProperties { HasMin: true, Min: -3e7, HasMax: true, Max: 3e7 }, Children: []int32 {4} },
+		{ Type: CommandNodeTypeArgument, Name: "count", Parser: "brigadier:integer", Properties: NumberParserProperties { HasMax: true, Max: 64 }, Children: []int32 {5}, SuggestionsType: "minecraft:ask_server" },
+		{ Type: CommandNodeTypeArgument, Name: "message", Parser: "brigadier:string", Properties: StringParserProperties { Kind: StringParserKindGreedyPhrase }, IsExecutable: true, Children: []int32 {} },
+		{ Type: CommandNodeTypeLiteral, Name: "teleport", Children: []int32 {}, HasRedirect: true, Redirect: 1 },
+	}}},
+	{StatePlay, Packet_EntityVelocity { EntityId: 7, X: -8000, Y: 0, Z: 8000 }},
+	{StatePlay, EntityRelativeMove { EntityId: 7, DeltaX: 32767, DeltaY: -32768, DeltaZ: 1, OnGround: true }},
+	{StatePlay, EntityLook { EntityId: 7, Yaw: 255, Pitch: 192 }},
+	{StatePlay, EntityHeadLook { EntityId: 7, HeadYaw: 64 }},
+	{StatePlay, EntityTeleport { EntityId: 7, X: -1e6, Y: 300.5, Z: 0.25, Yaw: 3, Pitch: 4, OnGround: true }},
+	{StatePlay, UpdateLight {
+		ChunkX: -3,
+		ChunkZ: 7,
+		SkyLight: [LightSectionCount][]byte {1: make([]byte, LightArrayLength), 5: bytes.Repeat([]byte {0xff}, LightArrayLength)},
+		BlockLight: [LightSectionCount][]byte {17: bytes.Repeat([]byte {0x12}, LightArrayLength)},
+	}},
+}
+
+var serverboundRoundTrips = []struct {
+	state State
+	packet interface{}
+} {
+	{StateHandshaking, Handshake { Protocol: 578, ServerAddress: "localhost", ServerPort: 25565, NextState: StateLogin }},
+	{StateStatus, Packet_0051_StatusRequest {}},
+	{StateStatus, Packet_0051_Ping { Payload: 99 }},
+	{StateLogin, LoginStart { ClientsideUsername: "Notch" }},
+	{StateLogin, EncryptionResponse { SharedSecret: []byte {1, 2, 3, 4}, VerifyToken: []byte {5, 6} }},
+	{StatePlay, KeepAlive { Payload: -0x0102030405060708 }},
+	{StatePlay, Packet_PlayerPosSb { X: 1, Y: 2, Z: 3, OnGround: true }},
+	{StatePlay, Packet_PlayerLookSb { Yaw: 180, Pitch: -90 }},
+	{StatePlay, Packet_PlayerPosAndLookSb { X: -1, Y: 65.5, Z: 1e6, Yaw: 12, Pitch: 34, OnGround: true }},
+	{StatePlay, ChatMessageSb { Message: "/give @p diamond 64" }},
+	{StatePlay, TabCompleteSb { TransactionId: 3, Text: "/tp No" }},
+	{StatePlay, TeleportConfirm { TeleportId: 300 }},
+	{StatePlay, ClientSettings {
+		Locale: "en_us",
+		ViewDistance: 12,
+		ChatMode: ChatModeCommandsOnly,
+		ChatColors: true,
+		DisplayedSkinParts: SkinPartCape | SkinPartHat,
+		MainHand: HandLeft,
+	}},
+	{StatePlay, PlayerAbilitiesSb { IsFlying: true, AllowFlying: true, IsCreative: true, FlyingSpeed: 0.05, WalkingSpeed: 0.1 }},
+	{StatePlay, EntityAction { EntityId: 7, Action: PlayerActionStartSprinting }},
+	{StatePlay, EntityAction { EntityId: 7, Action: PlayerActionStartHorseJump, JumpBoost: 100 }},
+	{StatePlay, HeldItemChange { Slot: 8 }},
+}
+
+func TestClientboundRoundTrip(t *testing.T) {
+	for _, version := range roundTripProtocols {
+		for _, threshold := range []int32 {-1, 64} {
+			for i, mapping := range clientboundRoundTrips {
+				ctx := ClientContext {
+					Protocol: version.protocol,
+					State: mapping.state,
+					CompressionThreshold: threshold,
+				}
+
+				var buf bytes.Buffer
+				writer := bufio.NewWriter(&buf)
+				var result interface{}
+				var err error
+
+				if threshold < 0 {
+					EmitClientboundPacketUncompressed(mapping.packet, ctx, writer)
+					result, err = ParseClientboundPacketUncompressed(bufio.NewReader(&buf), ctx, mapping.state)
+				} else {
+					EmitClientboundPacketCompressed(mapping.packet, ctx, writer)
+					result, err = ParseClientboundPacketCompressed(bufio.NewReader(&buf), ctx, mapping.state)
+				}
+
+				if err != nil {
+					t.Errorf("%s mapping %d (threshold %d): %v", version.name, i, threshold, err)
+					continue
+				}
+
+				if !reflect.DeepEqual(result, mapping.packet) {
+					t.Errorf("%s mapping %d (threshold %d) incorrect:\n%#v\n%#v", version.name, i, threshold, mapping.packet, result)
+				}
+			}
+		}
+	}
+}
+
+func TestServerboundRoundTrip(t *testing.T) {
+	for _, version := range roundTripProtocols {
+		for _, threshold := range []int32 {-1, 8} {
+			for i, mapping := range serverboundRoundTrips {
+				ctx := ClientContext {
+					Protocol: version.protocol,
+					State: mapping.state,
+					CompressionThreshold: threshold,
+				}
+
+				var buf bytes.Buffer
+				writer := bufio.NewWriter(&buf)
+				var result interface{}
+				var err error
+
+				if threshold < 0 {
+					EmitServerboundPacketUncompressed(mapping.packet, ctx, writer)
+					result, err = ParseServerboundPacketUncompressed(bufio.NewReader(&buf), ctx, mapping.state)
+				} else {
+					EmitServerboundPacketCompressed(mapping.packet, ctx, writer)
+					result, err = ParseServerboundPacketCompressed(bufio.NewReader(&buf), ctx, mapping.state)
+				}
+
+				if err != nil {
+					t.Errorf("%s mapping %d (threshold %d): %v", version.name, i, threshold, err)
+					continue
+				}
+
+				if !reflect.DeepEqual(result, mapping.packet) {
+					t.Errorf("%s mapping %d (threshold %d) incorrect:\n%#v\n%#v", version.name, i, threshold, mapping.packet, result)
+				}
+			}
+		}
+	}
+}
+
+func TestLegacyStatusRoundTrip(t *testing.T) {
+	response := Packet_002E_StatusResponse {
+		Protocol: 51,
+		Version: "1.4.7",
+		Description: chat.FromLegacy("§eHello"),
+		MaxPlayers: 20,
+		OnlinePlayers: 3,
+	}
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	ctx := ClientContext { State: StatePreNetty }
+
+	EmitClientboundPacketUncompressed(response, ctx, writer)
+	result, err := ParseClientboundPacketUncompressed(bufio.NewReader(&buf), ctx, StatePreNetty)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(result, response) {
+		t.Errorf("Output incorrect: %#v", result)
+	}
+
+	veryLegacy := VeryLegacyStatusResponse {
+		Description: chat.Text("Hello"),
+		MaxPlayers: 20,
+		OnlinePlayers: 3,
+	}
+
+	buf.Reset()
+	ctx.State = StateVeryPreNetty
+
+	EmitClientboundPacketUncompressed(veryLegacy, ctx, writer)
+	result, err = ParseClientboundPacketUncompressed(bufio.NewReader(&buf), ctx, StateVeryPreNetty)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(result, veryLegacy) {
+		t.Errorf("Output incorrect: %#v", result)
+	}
+}
+
+func TestParseSkipsUnparsedPackets(t *testing.T) {
+	ctx := ClientContext { Protocol: 0x022E, State: StatePlay, CompressionThreshold: -1 }
+	position := Packet_PlayerPosSb { X: 1, Y: 2, Z: 3, OnGround: true }
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+
+	// A packet that is not known, followed by a packet that is only partially read
+	WriteVarInt(3, writer)
+	writer.Write([]byte {0x7f, 0x01, 0x02})
+	WriteVarInt(2 + 8, writer)
+	writer.Write([]byte {0x11, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+	EmitServerboundPacketUncompressed(position, ctx, writer)
+
+	stream := bufio.NewReader(&buf)
+
+	if _, err := ParseServerboundPacketUncompressed(stream, ctx, StatePlay); err == nil {
+		t.Error("Expected error for unknown packet")
+	}
+
+	if _, err := ParseServerboundPacketUncompressed(stream, ctx, StatePlay); err == nil {
+		t.Error("Expected error for truncated packet")
+	}
+
+	result, err := ParseServerboundPacketUncompressed(stream, ctx, StatePlay)
+	if err != nil || !reflect.DeepEqual(result, position) {
+		t.Errorf("Packet after unparsed packets incorrect: %#v %v", result, err)
+	}
+
+	// The stream ending between packets is not an error in the packet itself
+	if _, err := ParseServerboundPacketUncompressed(stream, ctx, StatePlay); err != io.EOF {
+		t.Errorf("Expected io.EOF but instead got: %v", err)
+	}
+
+	if _, err := ParseServerboundPacketCompressed(stream, ctx, StatePlay); err != io.EOF {
+		t.Errorf("Expected io.EOF but instead got: %v", err)
+	}
+}
@@ -2,7 +2,22 @@ package javaio
 
 import "bufio"
 
-func WriteBlockPos(pos BlockPosition, stream *bufio.Writer) {
+func WriteBlockPos(pos BlockPosition, stream *bufio.Writer) error {
 	var encoded int64 = ((int64(pos.X) & 0x3FFFFFF) << 38) | ((int64(pos.Z) & 0x3FFFFFF) << 12) | (int64(pos.Y) & 0xFFF)
-	WriteLong(encoded, stream)
+	return WriteLong(encoded, stream)
+}
+
+func ReadBlockPos(stream *bufio.Reader) (result BlockPosition, err error) {
+	encoded, err := ReadLong(stream)
+	if err != nil {
+		return
+	}
+
+	// Arithmetic shifts sign-extend each coordinate
+	result = BlockPosition {
+		X: int(encoded >> 38),
+		Y: int(encoded << 52 >> 52),
+		Z: int(encoded << 26 >> 38),
+	}
+	return
 }
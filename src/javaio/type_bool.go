@@ -17,10 +17,10 @@ func ReadBool(stream *bufio.Reader) (result bool, err error) {
 	return
 }
 
-func WriteBool(value bool, stream *bufio.Writer) {
+func WriteBool(value bool, stream *bufio.Writer) error {
 	if (value == true) {
-		WriteUByte(0x01, stream)
+		return WriteUByte(0x01, stream)
 	} else {
-		WriteUByte(0x00, stream)
+		return WriteUByte(0x00, stream)
 	}
 }
@@ -0,0 +1,37 @@
+package javaio
+
+import "io"
+import "bufio"
+
+func ReadByteArray(stream *bufio.Reader, maxLength int) (result []byte, err error) {
+	length, err := ReadVarInt(stream)
+
+	if err != nil {
+		return
+	}
+
+	if length < 0 || int(length) > maxLength {
+		err = MalformedPacketError { "Byte array exceeded max length" }
+		return
+	}
+
+	result = make([]byte, length)
+	_, readErr := io.ReadFull(stream, result)
+
+	if readErr != nil {
+		err = MalformedPacketError { "Byte array ended abruptly" }
+		return
+	}
+
+	return
+}
+
+func WriteByteArray(value []byte, stream *bufio.Writer) error {
+	err := WriteVarInt(int32(len(value)), stream) // potentially unsafe cast
+	if err != nil {
+		return err
+	}
+
+	_, err = stream.Write(value)
+	return err
+}
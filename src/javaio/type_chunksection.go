@@ -0,0 +1,253 @@
+package javaio
+
+import "fmt"
+import "bufio"
+
+const (
+	// Sections with more distinct blocks than an 8-bit palette can hold use the global palette instead.
+	minIndirectBitsPerBlock = 4
+	maxIndirectBitsPerBlock = 8
+)
+
+// 1.16 approximation -- block state values no longer span multiple longs from this version.
+const nonSpanningPackingProtocol = 0x0330
+
+// Number of bits needed to hold any block state id in the global palette.
+func globalBitsPerBlock(protocol uint) uint8 {
+	if protocol >= nonSpanningPackingProtocol {
+		// approximation -- 1.16 has more than 16384 block states
+		return 15
+	}
+
+	return 14
+}
+
+func EmitChunkSectionData(blocks []uint32, ctx ClientContext, result *bufio.Writer) (err error) {
+	if len(blocks) != 4096 {
+		err = FieldOutOfRangeError { "There must be exactly 4096 blocks in each chunk section" }
+		return
+	}
+
+	// Palette indices are assigned in the order blocks first appear
+	var palette []uint32
+	paletteIndices := make(map[uint32]uint32)
+	values := make([]uint32, len(blocks))
+	blockCount := int16(0)
+
+	// Consecutive blocks are usually the same, which avoids most map lookups
+	lastBlock := blocks[0]
+	lastIndex := uint32(0)
+	paletteIndices[lastBlock] = 0
+	palette = append(palette, lastBlock)
+
+	for i, block := range blocks {
+		if block != 0 {
+			// Only air is excluded, as other ids depend on the block registry of the version
+			blockCount++
+		}
+
+		if block != lastBlock && len(palette) <= 1 << maxIndirectBitsPerBlock {
+			// Once the palette is too large the global palette is used and the indices are discarded
+			index, ok := paletteIndices[block]
+			if !ok {
+				index = uint32(len(palette))
+				paletteIndices[block] = index
+				palette = append(palette, block)
+			}
+
+			lastBlock = block
+			lastIndex = index
+		}
+
+		values[i] = lastIndex
+	}
+
+	bitsPerBlock := uint8(minIndirectBitsPerBlock)
+	for len(palette) > 1 << bitsPerBlock {
+		bitsPerBlock++
+	}
+
+	if bitsPerBlock > maxIndirectBitsPerBlock {
+		bitsPerBlock = globalBitsPerBlock(ctx.Protocol)
+		palette = nil
+		values = blocks
+	}
+
+	err = WriteShort(blockCount, result)
+	if err != nil {
+		return
+	}
+
+	err = WriteUByte(bitsPerBlock, result)
+	if err != nil {
+		return
+	}
+
+	if palette != nil {
+		err = WriteVarInt(int32(len(palette)), result)
+		if err != nil {
+			return
+		}
+
+		for _, block := range palette {
+			err = WriteVarInt(int32(block), result)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	longs := packValues(values, bitsPerBlock, ctx.Protocol < nonSpanningPackingProtocol)
+
+	err = WriteVarInt(int32(len(longs)), result)
+	if err != nil {
+		return
+	}
+
+	for _, long := range longs {
+		err = WriteULong(long, result)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Reads a section written with either an indirect palette or the global palette.
+func ReadChunkSectionData(stream *bufio.Reader, ctx ClientContext) (result []uint32, err error) {
+	_, err = ReadShort(stream) // block count
+	if err != nil {
+		return
+	}
+
+	bitsPerBlock, err := ReadUByte(stream)
+	if err != nil {
+		return
+	}
+
+	if bitsPerBlock == 0 || bitsPerBlock > 32 {
+		err = MalformedPacketError { fmt.Sprintf("Invalid bits per block %d", bitsPerBlock) }
+		return
+	}
+
+	var palette []uint32
+	if bitsPerBlock <= maxIndirectBitsPerBlock {
+		var paletteLength int32
+		paletteLength, err = ReadVarInt(stream)
+		if err != nil {
+			return
+		}
+
+		if paletteLength < 0 || paletteLength > 4096 {
+			err = MalformedPacketError { fmt.Sprintf("Invalid palette length %d", paletteLength) }
+			return
+		}
+
+		palette = make([]uint32, paletteLength)
+		for i := range palette {
+			var entry int32
+			entry, err = ReadVarInt(stream)
+			if err != nil {
+				return
+			}
+			palette[i] = uint32(entry)
+		}
+	}
+
+	spanning := ctx.Protocol < nonSpanningPackingProtocol
+
+	length, err := ReadVarInt(stream)
+	if err != nil {
+		return
+	}
+
+	if int(length) != packedLength(4096, bitsPerBlock, spanning) {
+		err = MalformedPacketError { fmt.Sprintf("Invalid data array length %d", length) }
+		return
+	}
+
+	longs := make([]uint64, length)
+	for i := range longs {
+		var value int64
+		value, err = ReadLong(stream)
+		if err != nil {
+			return
+		}
+		longs[i] = uint64(value)
+	}
+
+	result = unpackValues(longs, 4096, bitsPerBlock, spanning)
+
+	if palette != nil {
+		for i, index := range result {
+			if index >= uint32(len(palette)) {
+				err = MalformedPacketError { fmt.Sprintf("Palette index %d out of range", index) }
+				return
+			}
+			result[i] = palette[index]
+		}
+	}
+
+	return
+}
+
+// Number of longs needed to hold the values.
+// Before 1.16 values span long boundaries, and afterwards the leftover bits of each long are padding.
+func packedLength(count int, bitsPerValue uint8, spanning bool) int {
+	if spanning {
+		return (count * int(bitsPerValue) + 63) / 64
+	}
+
+	valuesPerLong := 64 / int(bitsPerValue)
+	return (count + valuesPerLong - 1) / valuesPerLong
+}
+
+func packValues(values []uint32, bitsPerValue uint8, spanning bool) []uint64 {
+	result := make([]uint64, packedLength(len(values), bitsPerValue, spanning))
+	mask := uint64(1) << bitsPerValue - 1
+	valuesPerLong := 64 / int(bitsPerValue)
+
+	for i, value := range values {
+		v := uint64(value) & mask
+
+		if !spanning {
+			result[i / valuesPerLong] |= v << uint(i % valuesPerLong * int(bitsPerValue))
+			continue
+		}
+
+		bit := i * int(bitsPerValue)
+		result[bit / 64] |= v << uint(bit % 64)
+
+		if bit % 64 + int(bitsPerValue) > 64 {
+			result[bit / 64 + 1] |= v >> uint(64 - bit % 64)
+		}
+	}
+
+	return result
+}
+
+func unpackValues(longs []uint64, count int, bitsPerValue uint8, spanning bool) []uint32 {
+	result := make([]uint32, count)
+	mask := uint64(1) << bitsPerValue - 1
+	valuesPerLong := 64 / int(bitsPerValue)
+
+	for i := range result {
+		var v uint64
+
+		if !spanning {
+			v = longs[i / valuesPerLong] >> uint(i % valuesPerLong * int(bitsPerValue))
+		} else {
+			bit := i * int(bitsPerValue)
+			v = longs[bit / 64] >> uint(bit % 64)
+
+			if bit % 64 + int(bitsPerValue) > 64 {
+				v |= longs[bit / 64 + 1] << uint(64 - bit % 64)
+			}
+		}
+
+		result[i] = uint32(v & mask)
+	}
+
+	return result
+}
@@ -0,0 +1,201 @@
+package javaio
+
+import "bufio"
+import "bytes"
+import "reflect"
+import "testing"
+
+var chunkSectionProtocols = []struct {
+	name string
+	protocol uint
+} {
+	{"1.14", 0x022E},
+	{"1.16", 0x0330},
+}
+
+// Stone with a layer of grass on top, the most common kind of section in practice.
+func terrainSection() []uint32 {
+	blocks := make([]uint32, 4096)
+	for i := range blocks {
+		if i < 4096 - 256 {
+			blocks[i] = 1
+		} else {
+			blocks[i] = 9
+		}
+	}
+	return blocks
+}
+
+func sparseSection() []uint32 {
+	blocks := make([]uint32, 4096)
+	blocks[100] = 1
+	blocks[4000] = 10
+	return blocks
+}
+
+func noisySection(distinct int) []uint32 {
+	blocks := make([]uint32, 4096)
+	for i := range blocks {
+		blocks[i] = uint32(i * 7919 % distinct)
+	}
+	return blocks
+}
+
+func emitChunkSection(blocks []uint32, protocol uint) []byte {
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	EmitChunkSectionData(blocks, ClientContext { Protocol: protocol }, writer)
+	writer.Flush()
+	return buf.Bytes()
+}
+
+func TestChunkSectionEncoding(t *testing.T) {
+	iomap := []struct {
+		blocks []uint32
+		blockCount int16
+		bitsPerBlock [2]uint8
+	} {
+		{make([]uint32, 4096), 0, [2]uint8 {4, 4}},
+		{sparseSection(), 2, [2]uint8 {4, 4}},
+		{terrainSection(), 4096, [2]uint8 {4, 4}},
+		{noisySection(17), 4096 - 241, [2]uint8 {5, 5}},
+		{noisySection(256), 4096 - 16, [2]uint8 {8, 8}},
+		{noisySection(257), 4096 - 16, [2]uint8 {14, 15}},
+		{noisySection(4096), 4096 - 1, [2]uint8 {14, 15}},
+	}
+
+	for i, mapping := range iomap {
+		for j, version := range chunkSectionProtocols {
+			data := emitChunkSection(mapping.blocks, version.protocol)
+			stream := bufio.NewReader(bytes.NewReader(data))
+
+			blockCount, _ := ReadShort(stream)
+			bitsPerBlock, _ := ReadUByte(stream)
+
+			if blockCount != mapping.blockCount || bitsPerBlock != mapping.bitsPerBlock[j] {
+				t.Errorf("Header incorrect for mapping %d in %s: count %d bits %d", i, version.name, blockCount, bitsPerBlock)
+			}
+
+			result, err := ReadChunkSectionData(bufio.NewReader(bytes.NewReader(data)), ClientContext { Protocol: version.protocol })
+			if err != nil {
+				t.Errorf("Mapping %d in %s: %v", i, version.name, err)
+				continue
+			}
+
+			if !reflect.DeepEqual(result, mapping.blocks) {
+				t.Errorf("Round trip incorrect for mapping %d in %s", i, version.name)
+			}
+		}
+	}
+}
+
+func TestPackValues(t *testing.T) {
+	values := []uint32 {1, 2, 3, 4, 5}
+
+	// Five 14-bit values take 70 bits, so the fifth value spans two longs
+	spanning := packValues(values, 14, true)
+	if len(spanning) != 2 || spanning[0] >> 56 != 5 & 0xff || spanning[1] != 5 >> 8 {
+		t.Errorf("Spanning output incorrect: %x", spanning)
+	}
+
+	// Only four 14-bit values fit in a long, and the remaining 8 bits are padding
+	padded := packValues(values, 14, false)
+	if len(padded) != 2 || padded[0] >> 56 != 0 || padded[1] != 5 {
+		t.Errorf("Padded output incorrect: %x", padded)
+	}
+
+	for _, spanning := range []bool {true, false} {
+		output := unpackValues(packValues(values, 14, spanning), len(values), 14, spanning)
+		if !reflect.DeepEqual(output, values) {
+			t.Errorf("Round trip incorrect: %v", output)
+		}
+	}
+}
+
+func TestMalformedChunkSection(t *testing.T) {
+	data := emitChunkSection(sparseSection(), 0x022E)
+
+	// Refer to a palette entry that does not exist
+	data[len(data) - 1] = 0x0f
+
+	_, err := ReadChunkSectionData(bufio.NewReader(bytes.NewReader(data)), ClientContext { Protocol: 0x022E })
+	if _, ok := err.(MalformedPacketError); !ok {
+		t.Errorf("Expected MalformedPacketError but instead got: %v", err)
+	}
+}
+
+///////////////////////////////////////
+// Benchmarks
+///////////////////////////////////////
+
+// The encoder of the baseline, which always used the global palette and counted every block as non-air.
+// Kept as it was to compare the size and speed of EmitChunkSectionData against.
+func emitChunkSectionDataBaseline(blocks []uint32, result *bufio.Writer) {
+	const bitsPerBlock = 14
+
+	WriteShort(4096, result) // block count
+	WriteUByte(bitsPerBlock, result)
+
+	bitLength := len(blocks) * bitsPerBlock
+	length := (bitLength + bitLength % 64) / 64
+
+	WriteVarInt(int32(length), result)
+
+	currLong := uint64(0)
+	start := uint64(0)
+
+	for _, block := range blocks {
+		var b uint64 = uint64(block) & ((1 << bitsPerBlock) - 1)
+		currLong |= b << start
+
+		if start + bitsPerBlock >= 64 {
+			WriteULong(currLong, result)
+			currLong = 0
+			currLong |= b >> (64 - start)
+			start += bitsPerBlock
+			start -= 64
+		} else {
+			start += bitsPerBlock
+		}
+	}
+}
+
+var benchmarkSections = []struct {
+	name string
+	blocks []uint32
+} {
+	{"Empty", make([]uint32, 4096)},
+	{"Terrain", terrainSection()},
+	{"Sparse", sparseSection()},
+	{"Noisy200", noisySection(200)},
+	{"Noisy4096", noisySection(4096)},
+}
+
+var benchmarkEncoders = []struct {
+	name string
+	emit func(blocks []uint32, result *bufio.Writer)
+} {
+	{"Current", func(blocks []uint32, result *bufio.Writer) {
+		EmitChunkSectionData(blocks, ClientContext { Protocol: 0x022E }, result)
+	}},
+	{"Baseline", emitChunkSectionDataBaseline},
+}
+
+func BenchmarkEmitChunkSectionData(b *testing.B) {
+	for _, section := range benchmarkSections {
+		for _, encoder := range benchmarkEncoders {
+			b.Run(section.name + "/" + encoder.name, func(b *testing.B) {
+				var buf bytes.Buffer
+				writer := bufio.NewWriter(&buf)
+
+				for i := 0; i < b.N; i++ {
+					buf.Reset()
+					encoder.emit(section.blocks, writer)
+					writer.Flush()
+				}
+
+				b.ReportMetric(float64(buf.Len()), "bytes/section")
+			})
+		}
+	}
+}
@@ -1,14 +1,15 @@
 package javaio
 
+import "io"
 import "math"
 import "bufio"
 
 func ReadDouble(stream *bufio.Reader) (result float64, err error) {
 	const len = 8
 	var bytes [len]byte
-	read, _ := stream.Read(bytes[:])
+	_, readErr := io.ReadFull(stream, bytes[:])
 
-	if read < len {
+	if readErr != nil {
 		err = MalformedPacketError { "Double ended abruptly" }
 		return
 	}
@@ -26,9 +27,9 @@ func ReadDouble(stream *bufio.Reader) (result float64, err error) {
 	return
 }
 
-func WriteDouble(value float64, stream *bufio.Writer) {
+func WriteDouble(value float64, stream *bufio.Writer) error {
 	n := math.Float64bits(value)
-	stream.Write([]byte {
+	_, err := stream.Write([]byte {
 		byte(n >> 56),
 		byte(n >> 48),
 		byte(n >> 40),
@@ -38,4 +39,5 @@ func WriteDouble(value float64, stream *bufio.Writer) {
 		byte(n >> 8),
 		byte(n),
 	})
+	return err
 }
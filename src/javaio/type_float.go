@@ -1,14 +1,15 @@
 package javaio
 
+import "io"
 import "math"
 import "bufio"
 
 func ReadFloat(stream *bufio.Reader) (result float32, err error) {
 	const len = 4
 	var bytes [len]byte
-	read, _ := stream.Read(bytes[:])
+	_, readErr := io.ReadFull(stream, bytes[:])
 
-	if read < len {
+	if readErr != nil {
 		err = MalformedPacketError { "Float ended abruptly" }
 		return
 	}
@@ -22,12 +23,13 @@ func ReadFloat(stream *bufio.Reader) (result float32, err error) {
 	return
 }
 
-func WriteFloat(value float32, stream *bufio.Writer) {
+func WriteFloat(value float32, stream *bufio.Writer) error {
 	n := math.Float32bits(value)
-	stream.Write([]byte {
+	_, err := stream.Write([]byte {
 		byte(n >> 24),
 		byte(n >> 16),
 		byte(n >> 8),
 		byte(n),
 	})
+	return err
 }
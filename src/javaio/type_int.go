@@ -1,12 +1,29 @@
 package javaio
 
+import "io"
 import "bufio"
 
-func WriteInt(value int32, stream *bufio.Writer) {
-	stream.Write([]byte {
+func WriteInt(value int32, stream *bufio.Writer) error {
+	_, err := stream.Write([]byte {
 		byte(value >> 24),
 		byte(value >> 16),
 		byte(value >> 8),
 		byte(value),
 	})
+	return err
+}
+
+func ReadInt(stream *bufio.Reader) (result int32, err error) {
+	const size = 4
+
+	var buf [size]byte
+	_, readErr := io.ReadFull(stream, buf[:])
+
+	if readErr != nil {
+		err = MalformedPacketError { "Int ended abruptly" }
+		return
+	}
+
+	result = int32(buf[0]) << 24 | int32(buf[1]) << 16 | int32(buf[2]) << 8 | int32(buf[3])
+	return
 }
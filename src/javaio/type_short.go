@@ -1,10 +1,27 @@
 package javaio
 
+import "io"
 import "bufio"
 
-func WriteShort(value int16, stream *bufio.Writer) {
-	stream.Write([]byte {
+func WriteShort(value int16, stream *bufio.Writer) error {
+	_, err := stream.Write([]byte {
 		byte(value >> 8),
 		byte(value),
 	})
+	return err
+}
+
+func ReadShort(stream *bufio.Reader) (result int16, err error) {
+	const size = 2
+
+	var buf [size]byte
+	_, readErr := io.ReadFull(stream, buf[:])
+
+	if readErr != nil {
+		err = MalformedPacketError { "Short ended abruptly" }
+		return
+	}
+
+	result = int16(buf[0]) << 8 | int16(buf[1])
+	return
 }
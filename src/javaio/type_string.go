@@ -1,5 +1,6 @@
 package javaio
 
+import "io"
 import "bufio"
 import "unicode/utf8"
 
@@ -11,15 +12,15 @@ func ReadString(stream *bufio.Reader, maxRuneCount int) (result string, err erro
 		return
 	}
 
-	if int(strLength) > maxStrLength {
+	if strLength < 0 || int(strLength) > maxStrLength {
 		err = MalformedPacketError { "String exceeded max rune count" } //*
 		return
 	}
 
 	buf := make([]byte, strLength)
-	n, _ := stream.Read(buf)
+	_, readErr := io.ReadFull(stream, buf)
 	
-	if (n != int(strLength)) {
+	if readErr != nil {
 		err = MalformedPacketError { "String ended abruptly" }
 		return
 	}
@@ -34,16 +35,42 @@ func ReadString(stream *bufio.Reader, maxRuneCount int) (result string, err erro
 	return
 }
 
-func WriteString(value string, stream *bufio.Writer) { // TODO: string length limit
+func WriteString(value string, stream *bufio.Writer) error { // TODO: string length limit
 	// TODO: int32 cast potentially unsafe?
-	WriteVarInt(int32(len(value)), stream)
-	stream.WriteString(value)
+	err := WriteVarInt(int32(len(value)), stream)
+	if err != nil {
+		return err
+	}
+
+	_, err = stream.WriteString(value)
+	return err
 }
 
-func WriteUTF16(value []uint16, stream *bufio.Writer) {
+func WriteUTF16(value []uint16, stream *bufio.Writer) error {
 	// Big-endian
 	for _, char := range value {
-		stream.WriteByte(byte(char >> 8))
-		stream.WriteByte(byte(char))
+		_, err := stream.Write([]byte {byte(char >> 8), byte(char)})
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+func ReadUTF16(stream *bufio.Reader, length int) (result []uint16, err error) {
+	// Big-endian
+	buf := make([]byte, length * 2)
+	_, readErr := io.ReadFull(stream, buf)
+
+	if readErr != nil {
+		err = MalformedPacketError { "UTF-16 string ended abruptly" }
+		return
+	}
+
+	result = make([]uint16, length)
+	for i := range result {
+		result[i] = uint16(buf[i * 2]) << 8 | uint16(buf[i * 2 + 1])
+	}
+	return
 }
@@ -2,6 +2,15 @@ package javaio
 
 import "bufio"
 
-func WriteUByte(value byte, stream *bufio.Writer) {
-	stream.WriteByte(value)
+func WriteUByte(value byte, stream *bufio.Writer) error {
+	return stream.WriteByte(value)
+}
+
+func ReadUByte(stream *bufio.Reader) (result byte, err error) {
+	result, readErr := stream.ReadByte()
+
+	if readErr != nil {
+		err = MalformedPacketError { "Unsigned byte ended abruptly" }
+	}
+	return
 }
@@ -2,8 +2,8 @@ package javaio
 
 import "bufio"
 
-func WriteULong(value uint64, stream *bufio.Writer) {
-	stream.Write([]byte {
+func WriteULong(value uint64, stream *bufio.Writer) error {
+	_, err := stream.Write([]byte {
 		byte(value >> 56),
 		byte(value >> 48),
 		byte(value >> 40),
@@ -13,4 +13,5 @@ func WriteULong(value uint64, stream *bufio.Writer) {
 		byte(value >> 8),
 		byte(value),
 	})
+	return err
 }
@@ -1,14 +1,15 @@
 package javaio
 
+import "io"
 import "bufio"
 
 func ReadUShort(stream *bufio.Reader) (result uint16, err error) {
 	const size = 2
 
 	var buf [size]byte
-	n, _ := stream.Read(buf[:])
+	_, readErr := io.ReadFull(stream, buf[:])
 
-	if n < size {
+	if readErr != nil {
 		err = MalformedPacketError { "Unsigned short ended abruptly" }
 		return
 	}
@@ -16,3 +17,11 @@ func ReadUShort(stream *bufio.Reader) (result uint16, err error) {
 	result = uint16(buf[1]) + 256 * uint16(buf[0])
 	return
 }
+
+func WriteUShort(value uint16, stream *bufio.Writer) error {
+	_, err := stream.Write([]byte {
+		byte(value >> 8),
+		byte(value),
+	})
+	return err
+}
@@ -1,19 +1,27 @@
 package javaio
 
+import "io"
 import "bufio"
 import "github.com/google/uuid"
 
-func WriteUuidBin(uuid uuid.UUID, stream *bufio.Writer) {
-	data, err := uuid.MarshalBinary()
-
-	if err != nil {
-		panic(err)
-	}
+func WriteUuidBin(uuid uuid.UUID, stream *bufio.Writer) error {
+	// Cannot fail for a UUID
+	data, _ := uuid.MarshalBinary()
 	
 	// TODO: not sure which order to use here
-	stream.Write(data)
+	_, err := stream.Write(data)
+	return err
 
 	// for i := range data {
 	// 	stream.WriteByte(data[len(data) - 1 - i])	
 	// }
 }
+
+func ReadUuidBin(stream *bufio.Reader) (result uuid.UUID, err error) {
+	_, readErr := io.ReadFull(stream, result[:])
+
+	if readErr != nil {
+		err = MalformedPacketError { "UUID ended abruptly" }
+	}
+	return
+}
@@ -29,18 +29,24 @@ func ReadVarInt(stream *bufio.Reader) (result int32, err error) {
 	return
 }
 
-func WriteVarInt(value int32, stream *bufio.Writer) {
+func WriteVarInt(value int32, stream *bufio.Writer) error {
+	// Negative numbers are encoded as their two's complement, which always takes 5 bytes
+	unsigned := uint32(value)
+
 	for {
-		byte_ := byte(value & 0b01111111)
-		value >>= 7
-		if value != 0 {
+		byte_ := byte(unsigned & 0b01111111)
+		unsigned >>= 7
+		if unsigned != 0 {
 			byte_ |= 0b10000000
 		}
 
-		stream.WriteByte(byte_)
+		err := stream.WriteByte(byte_)
+		if err != nil {
+			return err
+		}
 
-		if value == 0 {
-			break
+		if unsigned == 0 {
+			return nil
 		}
 	}
 }
@@ -7,7 +7,7 @@ import "bytes"
 func TestReadVarInt(t *testing.T) {
 	iomap := []struct {
 		input []byte
-		output int
+		output int32
 	} {
 		{[]byte {0x00, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF},           0},
 		{[]byte {0x01, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF},           1},
@@ -17,7 +17,7 @@ func TestReadVarInt(t *testing.T) {
 		{[]byte {0xff, 0x01, 0xCC, 0xDD, 0xEE, 0xFF},         255},
 		{[]byte {0xff, 0xff, 0xff, 0xff, 0x07, 0xFF},  2147483647},
 		{[]byte {0xff, 0xff, 0xff, 0xff, 0x0f, 0xFF}, -         1},
-		{[]byte {0x80, 0x80, 0x80, 0x80, 0x08, 0xFF}, -2147483647},
+		{[]byte {0x80, 0x80, 0x80, 0x80, 0x08, 0xFF}, -2147483648},
 	}
 
 	for i, mapping := range iomap {
@@ -42,8 +42,33 @@ func TestReadVarInt(t *testing.T) {
 	for i, mappingInput := range iemap {
 		_, err := ReadVarInt(bufio.NewReader(bytes.NewReader(mappingInput)))
 
-		if _, ok := err.(*MalformedPacketError); !ok {
+		if _, ok := err.(MalformedPacketError); !ok {
 			t.Errorf("Expected MalformedPacketError for mapping %d but instead got: %v", i, err)
 		}
 	}
 }
+
+func TestWriteVarInt(t *testing.T) {
+	iomap := []struct {
+		input int32
+		output []byte
+	} {
+		{          0, []byte {0x00                        }},
+		{        127, []byte {0x7f                        }},
+		{        128, []byte {0x80, 0x01                  }},
+		{ 2147483647, []byte {0xff, 0xff, 0xff, 0xff, 0x07}},
+		{-         1, []byte {0xff, 0xff, 0xff, 0xff, 0x0f}},
+		{-2147483648, []byte {0x80, 0x80, 0x80, 0x80, 0x08}},
+	}
+
+	for i, mapping := range iomap {
+		var buf bytes.Buffer
+		writer := bufio.NewWriter(&buf)
+		WriteVarInt(mapping.input, writer)
+		writer.Flush()
+
+		if !bytes.Equal(buf.Bytes(), mapping.output) {
+			t.Errorf("Output incorrect for mapping %d: %x", i, buf.Bytes())
+		}
+	}
+}
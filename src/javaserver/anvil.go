@@ -0,0 +1,178 @@
+package javaserver
+
+import "os"
+import "sync"
+import "path/filepath"
+import "github.com/davidcallanan/go-mcp/anvil"
+import "github.com/davidcallanan/go-mcp/registry"
+
+// Used when AnvilChunkProvider.Protocol is not set.
+// The clients of 1.14 and 1.15 share the ids of every block state up to the bell, which can be powered from 1.15.
+const DefaultAnvilProtocol = 0x022E
+
+// The data version of chunks and player data that are saved for the first time,
+// that of the latest release whose clients use the block state ids of the protocol version.
+func anvilDataVersion(protocol uint) int32 {
+	if protocol >= 0x0286 {
+		return 2230 // 1.15.2
+	}
+
+	return 1976 // 1.14.4
+}
+
+// Loads the chunks of a world saved by vanilla from the region files of one of its dimensions, and saves them back.
+// Safe to use from multiple goroutines. The region files are kept open until Close is called.
+// Region files that cannot be written to, because of their permissions, are opened for reading only.
+type AnvilChunkProvider struct {
+	// Such as "world/region" for the overworld.
+	Directory string
+	// Converts between the saved block states and the ids sent to clients. Defaults to registry.Blocks when nil.
+	Palette anvil.GlobalPalette
+	// The protocol version whose block state ids the chunks are converted to.
+	// Defaults to DefaultAnvilProtocol when zero.
+	Protocol uint
+	// Provides the chunks that have not been generated yet, which are left empty when nil.
+	Fallback ChunkProvider
+
+	lock sync.Mutex
+	// nil for region files that do not exist.
+	regions map[string]*anvil.Region
+}
+
+// Returns nil for region files that do not exist, unless create is set.
+func (provider *AnvilChunkProvider) region(name string, create bool) (*anvil.Region, error) {
+	provider.lock.Lock()
+	defer provider.lock.Unlock()
+
+	if region, ok := provider.regions[name]; ok && (region != nil || !create) {
+		return region, nil
+	}
+
+	if provider.regions == nil {
+		provider.regions = make(map[string]*anvil.Region)
+	}
+
+	path := filepath.Join(provider.Directory, name)
+
+	// Region files are only created once a chunk is saved to them, like in vanilla
+	_, err := os.Stat(path)
+	if create && os.IsNotExist(err) {
+		err = os.MkdirAll(provider.Directory, 0755)
+	}
+
+	var region *anvil.Region
+	if err == nil {
+		region, err = anvil.OpenWritableRegion(path)
+	}
+	if os.IsPermission(err) {
+		region, err = anvil.OpenRegion(path)
+	}
+	if os.IsNotExist(err) {
+		region, err = nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider.regions[name] = region
+	return region, nil
+}
+
+func (provider *AnvilChunkProvider) ProvideChunk(x int32, z int32) (*ChunkColumn, error) {
+	region, err := provider.region(anvil.RegionFileName(x, z), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunk *anvil.Chunk
+	if region != nil {
+		chunk, err = region.ReadChunk(x, z)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Chunks that are only partly generated lack most of their blocks, so they are generated again like vanilla would
+	if chunk == nil || !chunk.IsFullyGenerated() {
+		if provider.Fallback == nil {
+			return NewChunkColumn(x, z), nil
+		}
+
+		return provider.Fallback.ProvideChunk(x, z)
+	}
+
+	sections, err := chunk.GlobalSections(provider.protocol(), provider.palette())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChunkColumn {
+		X: x,
+		Z: z,
+		Sections: sections,
+	}, nil
+}
+
+func (provider *AnvilChunkProvider) palette() anvil.GlobalPalette {
+	if provider.Palette == nil {
+		return registry.Blocks
+	}
+
+	return provider.Palette
+}
+
+func (provider *AnvilChunkProvider) protocol() uint {
+	if provider.Protocol == 0 {
+		return DefaultAnvilProtocol
+	}
+
+	return provider.Protocol
+}
+
+// Saves a chunk to its region file, which is created if needed.
+// Everything else vanilla saved along with the chunk, such as its entities, is kept.
+func (provider *AnvilChunkProvider) SaveChunk(column *ChunkColumn) error {
+	region, err := provider.region(anvil.RegionFileName(column.X, column.Z), true)
+	if err != nil {
+		return err
+	}
+
+	chunk, err := region.ReadChunk(column.X, column.Z)
+	if err != nil {
+		return err
+	}
+
+	// Chunks that were only partly generated have been provided by the Fallback instead, so they are replaced
+	if chunk == nil || !chunk.IsFullyGenerated() {
+		chunk = &anvil.Chunk {
+			DataVersion: anvilDataVersion(provider.protocol()),
+			X: column.X,
+			Z: column.Z,
+			Status: "full",
+		}
+	}
+
+	chunk.SetGlobalSections(column.Sections, provider.protocol(), provider.palette())
+	return region.WriteChunk(chunk)
+}
+
+// Closes the region files that have been opened.
+func (provider *AnvilChunkProvider) Close() error {
+	provider.lock.Lock()
+	defer provider.lock.Unlock()
+
+	var result error
+	for name, region := range provider.regions {
+		if region == nil {
+			continue
+		}
+
+		if err := region.Close(); err != nil && result == nil {
+			result = err
+		}
+		delete(provider.regions, name)
+	}
+
+	return result
+}
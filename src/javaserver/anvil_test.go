@@ -0,0 +1,289 @@
+package javaserver
+
+import "os"
+import "bufio"
+import "time"
+import "context"
+import "testing"
+import "io/ioutil"
+import "path/filepath"
+import "github.com/davidcallanan/go-mcp/anvil"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/davidcallanan/go-mcp/registry"
+
+func TestAnvilChunkProvider(t *testing.T) {
+	provider := &AnvilChunkProvider {
+		Directory: "../anvil/testdata",
+		Fallback: ChunkProviderFunc(func(x int32, z int32) (*ChunkColumn, error) {
+			chunk := NewChunkColumn(x, z)
+			chunk.SetBlock(0, 0, 0, 7)
+			return chunk, nil
+		}),
+	}
+	defer provider.Close()
+
+	// Saved, partly generated, missing from the region, and in a region file that does not exist
+	iomap := []struct {
+		x int32
+		z int32
+		block uint32
+	} {
+		{0, 0, 33},
+		{2, 0, 7},
+		{5, 5, 7},
+		{-40, 3, 7},
+	}
+
+	for i, mapping := range iomap {
+		chunk, err := provider.ProvideChunk(mapping.x, mapping.z)
+		if err != nil {
+			t.Errorf("Error for mapping %d: %v", i, err)
+			continue
+		}
+
+		if chunk.X != mapping.x || chunk.Z != mapping.z || chunk.Block(0, 0, 0) != mapping.block {
+			t.Errorf("Chunk incorrect for mapping %d: %d %d %d", i, chunk.X, chunk.Z, chunk.Block(0, 0, 0))
+		}
+	}
+
+	world := &World { Provider: provider }
+	if block, _ := world.GetBlock(javaio.BlockPosition { X: 5, Y: 1, Z: 5 }); block != 1 {
+		t.Errorf("Block incorrect: %d", block)
+	}
+}
+
+func TestAnvilChunkProviderSaves(t *testing.T) {
+	directory, err := ioutil.TempDir("", "javaserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	newWorld := func() (*World, *AnvilChunkProvider) {
+		provider := &AnvilChunkProvider {
+			Directory: filepath.Join(directory, "region"),
+			Palette: registry.Blocks,
+			Fallback: defaultGenerator,
+		}
+		return &World { Provider: provider }, provider
+	}
+
+	world, provider := newWorld()
+	world.SetBlock(javaio.BlockPosition { X: 100, Y: 63, Z: -5 }, 230)
+	world.GetBlock(javaio.BlockPosition { X: 0, Y: 0, Z: 0 })
+
+	err = world.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.Close()
+
+	// Only the region of the chunk that changed is created
+	files, _ := ioutil.ReadDir(filepath.Join(directory, "region"))
+	if len(files) != 1 || files[0].Name() != "r.0.-1.mca" {
+		t.Errorf("Region files incorrect: %v", files)
+	}
+
+	world, provider = newWorld()
+	defer provider.Close()
+
+	iomap := []struct {
+		pos javaio.BlockPosition
+		block uint32
+	} {
+		{javaio.BlockPosition { X: 100, Y: 63, Z: -5 }, 230},
+		{javaio.BlockPosition { X: 101, Y: 63, Z: -5 }, 9},
+		{javaio.BlockPosition { X: 100, Y: 0, Z: -5 }, 33},
+		{javaio.BlockPosition { X: 100, Y: 64, Z: -5 }, 0},
+	}
+
+	for i, mapping := range iomap {
+		block, err := world.GetBlock(mapping.pos)
+		if err != nil || block != mapping.block {
+			t.Errorf("Output incorrect for mapping %d: %d %v", i, block, err)
+		}
+	}
+
+	// Nothing has changed since
+	err = world.Save()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerPlayerData(t *testing.T) {
+	directory, err := ioutil.TempDir("", "javaserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	server, addr, _ := newConfiguredTestServer(t, func(server *Server) {
+		server.PlayerDataDirectory = filepath.Join(directory, "playerdata")
+	})
+	defer server.Shutdown(context.Background())
+
+	path := filepath.Join(directory, "playerdata", testPlayerUuid("Notch").String() + ".dat")
+
+	clientSide := joinTestServer(t, addr, "Notch")
+	expectJoinGame(t, bufio.NewReader(clientSide))
+	waitForPlayerCount(t, server, 1)
+
+	conn := server.Player(testPlayerUuid("Notch"))
+	if conn.Gamemode() != javaio.GamemodeCreative {
+		t.Errorf("Gamemode incorrect: %d", conn.Gamemode())
+	}
+
+	// Moved by the position packets of the player
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+	output := bufio.NewWriter(clientSide)
+	err = javaio.EmitServerboundPacketUncompressed(javaio.Packet_PlayerPosAndLookSb { X: 100.5, Y: 70, Z: -20.25, Yaw: 90, Pitch: 15 }, ctx, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for server.Entities.PlayerEntity(conn).Position().X != 100.5 {
+		if time.Now().After(deadline) {
+			t.Fatal("Player not moved")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Saved periodically as well as when the player leaves
+	err = server.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := anvil.ReadPlayerData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Position != [3]float64 {100.5, 70, -20.25} || data.Rotation != [2]float32 {90, 15} || data.Gamemode != 1 {
+		t.Errorf("Data incorrect: %#v", data)
+	}
+
+	// Saved again as the player leaves
+	// Handled before the connection is seen to be closed
+	err = javaio.EmitServerboundPacketUncompressed(javaio.Packet_PlayerPosAndLookSb { X: -30, Y: 80, Z: 40 }, ctx, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientSide.Close()
+	waitForPlayerCount(t, server, 0)
+
+	data, err = anvil.ReadPlayerData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Switched to survival while offline, with an item that is kept as it was
+	data.Gamemode = 0
+	data.Inventory = []anvil.ItemStack { { Slot: 0, Id: "minecraft:stone", Count: 3 } }
+	err = anvil.WritePlayerData(path, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientSide = joinTestServer(t, addr, "Notch")
+	defer clientSide.Close()
+	input := bufio.NewReader(clientSide)
+
+	joinGame := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.JoinGame)
+		return ok
+	}).(javaio.JoinGame)
+
+	if joinGame.Gamemode != javaio.GamemodeSurvival {
+		t.Errorf("Gamemode incorrect: %d", joinGame.Gamemode)
+	}
+
+	position := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.PlayerPositionAndLook)
+		return ok
+	}).(javaio.PlayerPositionAndLook)
+
+	if position.X != -30 || position.Y != 80 || position.Z != 40 {
+		t.Errorf("Position incorrect: %#v", position)
+	}
+
+	// The chunks around where the player left are sent
+	expectPacket(t, input, func(packet interface{}) bool {
+		chunk, ok := packet.(javaio.ChunkData)
+		return ok && chunk.X == -2 - joinChunkRadius && chunk.Z == 2 - joinChunkRadius
+	})
+
+	waitForPlayerCount(t, server, 1)
+	server.Save()
+
+	data, err = anvil.ReadPlayerData(path)
+	if err != nil || len(data.Inventory) != 1 || data.Inventory[0].Count != 3 || data.Gamemode != 0 {
+		t.Errorf("Data not kept: %#v %v", data, err)
+	}
+}
+
+func TestServerAutosave(t *testing.T) {
+	directory, err := ioutil.TempDir("", "javaserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	provider := &AnvilChunkProvider {
+		Directory: directory,
+		Palette: registry.Blocks,
+		Fallback: defaultGenerator,
+	}
+	defer provider.Close()
+
+	server, _, _ := newConfiguredTestServer(t, func(server *Server) {
+		server.World.Provider = provider
+		server.AutosaveInterval = 10 * time.Millisecond
+		server.SaveOnShutdown = true
+	})
+
+	server.World.SetBlock(javaio.BlockPosition { X: 0, Y: 100, Z: 0 }, 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(directory, "r.0.0.mca")); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Region file not saved")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Changed after autosaving has stopped, which Shutdown does first
+	server.lock.Lock()
+	close(server.stopAutosave)
+	server.stopAutosave = nil
+	server.lock.Unlock()
+
+	server.World.SetBlock(javaio.BlockPosition { X: 0, Y: 101, Z: 0 }, 33)
+
+	err = server.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	region, err := anvil.OpenRegion(filepath.Join(directory, "r.0.0.mca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer region.Close()
+
+	chunk, err := region.ReadChunk(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sections, _ := chunk.GlobalSections(DefaultAnvilProtocol, registry.Blocks)
+	if sections[6][4 * 256] != 1 || sections[6][5 * 256] != 33 {
+		t.Error("Changes not saved")
+	}
+}
@@ -0,0 +1,143 @@
+package javaserver
+
+import "fmt"
+import "net/url"
+import "net/http"
+import "math/big"
+import "crypto/rsa"
+import "crypto/sha1"
+import "crypto/rand"
+import "encoding/hex"
+import "encoding/json"
+import "github.com/google/uuid"
+
+const DefaultSessionServerUrl = "https://sessionserver.mojang.com"
+
+type GameProfile struct {
+	Uuid uuid.UUID
+	Username string
+	Properties []GameProfileProperty
+}
+
+type GameProfileProperty struct {
+	Name string
+	Value string
+	Signature string
+}
+
+// Verifies that a player has announced their join to the session server,
+// proving that they own the account they are logging in with.
+type Authenticator interface {
+	HasJoined(username string, serverHash string) (GameProfile, error)
+}
+
+type AuthenticationError struct {
+	details string
+}
+
+func (err AuthenticationError) Error() string {
+	return fmt.Sprintf("Authentication failed: %s", err.details)
+}
+
+// Authenticates against the Mojang session server, or any server implementing the same API.
+type SessionServerAuthenticator struct {
+	BaseUrl string
+	Client *http.Client
+}
+
+type hasJoinedJson struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Properties []struct {
+		Name string `json:"name"`
+		Value string `json:"value"`
+		Signature string `json:"signature"`
+	} `json:"properties"`
+}
+
+func (auth SessionServerAuthenticator) HasJoined(username string, serverHash string) (profile GameProfile, err error) {
+	baseUrl := auth.BaseUrl
+	if baseUrl == "" {
+		baseUrl = DefaultSessionServerUrl
+	}
+
+	client := auth.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	query := url.Values {}
+	query.Set("username", username)
+	query.Set("serverId", serverHash)
+
+	res, err := client.Get(baseUrl + "/session/minecraft/hasJoined?" + query.Encode())
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		// The session server responds with 204 No Content when the player has not joined
+		err = AuthenticationError { fmt.Sprintf("Session server responded with status %d", res.StatusCode) }
+		return
+	}
+
+	var body hasJoinedJson
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return
+	}
+
+	// The session server omits the dashes from the UUID
+	uuidBytes, err := hex.DecodeString(body.Id)
+	if err != nil {
+		err = AuthenticationError { fmt.Sprintf("Session server returned malformed UUID %q", body.Id) }
+		return
+	}
+
+	profileUuid, err := uuid.FromBytes(uuidBytes)
+	if err != nil {
+		err = AuthenticationError { fmt.Sprintf("Session server returned malformed UUID %q", body.Id) }
+		return
+	}
+
+	profile = GameProfile {
+		Uuid: profileUuid,
+		Username: body.Name,
+		Properties: make([]GameProfileProperty, len(body.Properties)),
+	}
+
+	for i, property := range body.Properties {
+		profile.Properties[i] = GameProfileProperty {
+			Name: property.Name,
+			Value: property.Value,
+			Signature: property.Signature,
+		}
+	}
+
+	return
+}
+
+// Generates a keypair suitable for Config.PrivateKey.
+// Vanilla servers generate a single 1024-bit key on startup and reuse it for every connection.
+func GenerateServerKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 1024)
+}
+
+// Computes the server hash sent to the session server.
+// This is a SHA-1 digest printed as a signed (two's complement) hexadecimal number, as done by Java's BigInteger.
+func ServerHash(serverId string, sharedSecret []byte, publicKey []byte) string {
+	hash := sha1.New()
+	hash.Write([]byte(serverId))
+	hash.Write(sharedSecret)
+	hash.Write(publicKey)
+	digest := hash.Sum(nil)
+
+	n := new(big.Int).SetBytes(digest)
+
+	if digest[0] & 0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(digest) * 8)))
+	}
+
+	return n.Text(16)
+}
@@ -0,0 +1,197 @@
+package javaserver
+
+import "io"
+import "net"
+import "bytes"
+import "bufio"
+import "testing"
+import "net/http"
+import "crypto/rsa"
+import "crypto/rand"
+import "crypto/x509"
+import "net/http/httptest"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/google/uuid"
+
+func TestServerHash(t *testing.T) {
+	// Well-known digests of player names, as printed by Java's BigInteger
+	iomap := []struct {
+		input string
+		output string
+	} {
+		{"Notch", "4ed1f46bbe04bc756bcb17c0c7ce3e4632f06a48"},
+		{"jeb_", "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1"},
+		{"simon", "88e16a1019277b15d58faf0541e11910eb756f6"},
+	}
+
+	for _, mapping := range iomap {
+		output := ServerHash(mapping.input, nil, nil)
+
+		if output != mapping.output {
+			t.Errorf("Hash of %q incorrect: %s", mapping.input, output)
+		}
+	}
+}
+
+const testProfileJson = `{
+	"id": "069a79f444e94726a5befca90e38aaf5",
+	"name": "Notch",
+	"properties": [{"name": "textures", "value": "abc", "signature": "def"}]
+}`
+
+var testProfileUuid = uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+
+func newTestSessionServer(t *testing.T, expectedServerHash *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/minecraft/hasJoined" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("username") != "Notch" || (expectedServerHash != nil && r.URL.Query().Get("serverId") != *expectedServerHash) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Write([]byte(testProfileJson))
+	}))
+}
+
+func TestSessionServerAuthenticator(t *testing.T) {
+	server := newTestSessionServer(t, nil)
+	defer server.Close()
+
+	auth := SessionServerAuthenticator { BaseUrl: server.URL }
+
+	profile, err := auth.HasJoined("Notch", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Uuid != testProfileUuid || profile.Username != "Notch" {
+		t.Errorf("Profile incorrect: %#v", profile)
+	}
+
+	if len(profile.Properties) != 1 || profile.Properties[0].Name != "textures" || profile.Properties[0].Signature != "def" {
+		t.Errorf("Profile properties incorrect: %#v", profile.Properties)
+	}
+
+	_, err = auth.HasJoined("Herobrine", "abc")
+	if _, ok := err.(AuthenticationError); !ok {
+		t.Errorf("Expected AuthenticationError but instead got: %v", err)
+	}
+}
+
+// Writes a single uncompressed packet in one write, as a real client would.
+func writeTestPacket(t *testing.T, conn net.Conn, packetId int32, writeData func(*bufio.Writer)) {
+	var dataBuf bytes.Buffer
+	dataWriter := bufio.NewWriter(&dataBuf)
+	javaio.WriteVarInt(packetId, dataWriter)
+	writeData(dataWriter)
+	dataWriter.Flush()
+
+	var packetBuf bytes.Buffer
+	packetWriter := bufio.NewWriter(&packetBuf)
+	javaio.WriteVarInt(int32(dataBuf.Len()), packetWriter)
+	packetWriter.Write(dataBuf.Bytes())
+	packetWriter.Flush()
+
+	if _, err := conn.Write(packetBuf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Reads a single uncompressed packet and returns a reader over its data.
+func readTestPacket(t *testing.T, input *bufio.Reader, expectedPacketId int32) *bufio.Reader {
+	length, err := javaio.ReadVarInt(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(input, packet); err != nil {
+		t.Fatal(err)
+	}
+
+	data := bufio.NewReader(bytes.NewReader(packet))
+	packetId, err := javaio.ReadVarInt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if packetId != expectedPacketId {
+		t.Fatalf("Expected packet id %d but instead got %d", expectedPacketId, packetId)
+	}
+
+	return data
+}
+
+func TestOnlineModeLogin(t *testing.T) {
+	privateKey, err := GenerateServerKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sharedSecret := []byte("0123456789abcdef")
+	publicKeyDer, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	expectedServerHash := ServerHash("", sharedSecret, publicKeyDer)
+
+	sessionServer := newTestSessionServer(t, &expectedServerHash)
+	defer sessionServer.Close()
+
+	joinRequests := make(chan PlayerJoinRequest, 1)
+	serverSide, clientSide := net.Pipe()
+
+	NewConnectionWithConfig(serverSide, func() { serverSide.Close() }, EventHandlers {
+		OnPlayerJoinRequest: func(data PlayerJoinRequest) PlayerJoinResponse {
+			joinRequests <- data
+			return PlayerJoinResponse { PreventResponse: true }
+		},
+	}, Config {
+		CompressionThreshold: -1,
+		OnlineMode: true,
+		Authenticator: SessionServerAuthenticator { BaseUrl: sessionServer.URL },
+		PrivateKey: privateKey,
+	})
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteVarInt(javaio.EncodePostNettyVersion(0x0286), data)
+		javaio.WriteString("localhost", data)
+		data.Write([]byte {0x63, 0xdd})
+		javaio.WriteVarInt(2, data)
+	})
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteString("Notch", data)
+	})
+
+	input := bufio.NewReader(clientSide)
+	request := readTestPacket(t, input, 0x01)
+
+	if serverId, err := javaio.ReadString(request, 20); err != nil || serverId != "" {
+		t.Fatalf("Server id incorrect: %q %v", serverId, err)
+	}
+
+	publicKey, err := javaio.ReadByteArray(request, 512)
+	if err != nil || !bytes.Equal(publicKey, publicKeyDer) {
+		t.Fatalf("Public key incorrect: %v", err)
+	}
+
+	verifyToken, err := javaio.ReadByteArray(request, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryptedSecret, _ := rsa.EncryptPKCS1v15(rand.Reader, &privateKey.PublicKey, sharedSecret)
+	encryptedToken, _ := rsa.EncryptPKCS1v15(rand.Reader, &privateKey.PublicKey, verifyToken)
+
+	writeTestPacket(t, clientSide, 0x01, func(data *bufio.Writer) {
+		javaio.WriteByteArray(encryptedSecret, data)
+		javaio.WriteByteArray(encryptedToken, data)
+	})
+
+	joinRequest := <-joinRequests
+
+	if joinRequest.Profile == nil || joinRequest.Profile.Uuid != testProfileUuid {
+		t.Errorf("Join request profile incorrect: %#v", joinRequest.Profile)
+	}
+}
@@ -0,0 +1,69 @@
+package javaserver
+
+import "strings"
+import "unicode"
+import "github.com/davidcallanan/go-mcp/chat"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+// Whether the vanilla server allows a character in chat messages.
+// Control characters are not allowed, nor is the section sign, which would allow legacy formatting codes.
+func isAllowedChatCharacter(char rune) bool {
+	return char >= ' ' && char != 0x7f && char != '§'
+}
+
+// Removes the characters that the vanilla server does not allow in chat messages,
+// and collapses runs of whitespace into single spaces like vanilla does.
+func SanitizeChatMessage(message string) string {
+	message = strings.Map(func(char rune) rune {
+		if isAllowedChatCharacter(char) {
+			return char
+		}
+
+		if unicode.IsSpace(char) {
+			// Collapsed below, so that words on either side stay apart
+			return ' '
+		}
+
+		return -1
+	}, message)
+
+	return strings.Join(strings.Fields(message), " ")
+}
+
+// The message as the vanilla server shows it, in the form "<username> message".
+func PlayerChatMessage(username string, message string) chat.TextComponent {
+	return chat.TextComponent {
+		Translate: "chat.type.text",
+		With: []chat.TextComponent {
+			{ Text: username, Insertion: username },
+			chat.Text(message),
+		},
+	}
+}
+
+func (conn *Connection) processChatMessage(data javaio.ChatMessageSb) {
+	if conn.eventHandlers.OnChatMessage == nil {
+		return
+	}
+
+	message := SanitizeChatMessage(data.Message)
+	if message == "" {
+		return
+	}
+
+	conn.eventHandlers.OnChatMessage(message)
+}
+
+func (conn *Connection) SendMessage(message chat.TextComponent, position javaio.ChatPosition) error {
+	return conn.send(javaio.ChatMessage {
+		Message: message,
+		Position: position,
+	})
+}
+
+// Sends the message to every online player.
+func (server *Server) BroadcastMessage(message chat.TextComponent, position javaio.ChatPosition) {
+	server.Broadcast(func(conn *Connection) {
+		conn.SendMessage(message, position)
+	})
+}
@@ -0,0 +1,74 @@
+package javaserver
+
+import "time"
+import "bufio"
+import "reflect"
+import "testing"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+func TestSanitizeChatMessage(t *testing.T) {
+	iomap := []struct {
+		input string
+		output string
+	} {
+		{"Hello, World!", "Hello, World!"},
+		{"  padded  ", "padded"},
+		{"tab\tand\nnewline", "tab and newline"},
+		{"§cred", "cred"},
+		{"bell\x07 and delete\x7f", "bell and delete"},
+		{"héllo 世界", "héllo 世界"},
+		{"\x00\x01", ""},
+	}
+
+	for i, mapping := range iomap {
+		if output := SanitizeChatMessage(mapping.input); output != mapping.output {
+			t.Errorf("Output incorrect for mapping %d: %q", i, output)
+		}
+	}
+}
+
+func TestChatMessages(t *testing.T) {
+	messages := make(chan string, 2)
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {
+		OnChatMessage: func(message string) {
+			messages <- message
+		},
+	})
+	defer conn.Close()
+
+	output := bufio.NewWriter(clientSide)
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+
+	// Ignored, as nothing is left after sanitizing
+	javaio.EmitServerboundPacketUncompressed(javaio.ChatMessageSb { Message: "§§" }, ctx, output)
+	javaio.EmitServerboundPacketUncompressed(javaio.ChatMessageSb { Message: "§aHello  there" }, ctx, output)
+
+	select {
+	case message := <-messages:
+		if message != "aHello there" {
+			t.Errorf("Message incorrect: %q", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for chat message")
+	}
+
+	sent := PlayerChatMessage("Notch", "Hello there")
+	err := conn.SendMessage(sent, javaio.ChatPositionActionBar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := expectPacket(t, bufio.NewReader(clientSide), func(packet interface{}) bool {
+		_, ok := packet.(javaio.ChatMessage)
+		return ok
+	}).(javaio.ChatMessage)
+
+	if received.Position != javaio.ChatPositionActionBar {
+		t.Errorf("Position incorrect: %d", received.Position)
+	}
+
+	if !reflect.DeepEqual(received.Message, sent) {
+		t.Errorf("Message incorrect: %#v", received.Message)
+	}
+}
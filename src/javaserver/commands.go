@@ -0,0 +1,674 @@
+package javaserver
+
+import "fmt"
+import "sort"
+import "sync"
+import "strconv"
+import "strings"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+// Returned when a command does not match any registered command, or one of its arguments cannot be parsed.
+type CommandSyntaxError struct {
+	details string
+}
+
+func (err CommandSyntaxError) Error() string {
+	return fmt.Sprintf("Invalid command: %s", err.details)
+}
+
+// How an argument is parsed, and how the client is told to parse it.
+type ArgumentType struct {
+	parser string
+	properties interface{}
+	// Parses the argument at the start of input, which is never empty,
+	// returning its value and how many bytes of input it takes up.
+	parse func(input string) (value interface{}, length int, err error)
+}
+
+// Either true or false. Read with CommandContext.Bool.
+func BoolArgument() ArgumentType {
+	return ArgumentType {
+		parser: "brigadier:bool",
+		parse: func(input string) (value interface{}, length int, err error) {
+			word := readCommandWord(input)
+			switch word {
+			case "true":
+				value = true
+			case "false":
+				value = false
+			default:
+				err = CommandSyntaxError { fmt.Sprintf("Expected true or false but found %q", word) }
+				return
+			}
+
+			length = len(word)
+			return
+		},
+	}
+}
+
+// Read with CommandContext.Int.
+func IntegerArgument() ArgumentType {
+	return integerArgument(javaio.NumberParserProperties {})
+}
+
+// Like IntegerArgument, limited to the range from min to max, both inclusive.
+func IntegerRangeArgument(min int32, max int32) ArgumentType {
+	return integerArgument(javaio.NumberParserProperties {
+		HasMin: true,
+		Min: float64(min),
+		HasMax: true,
+		Max: float64(max),
+	})
+}
+
+func integerArgument(properties javaio.NumberParserProperties) ArgumentType {
+	return ArgumentType {
+		parser: "brigadier:integer",
+		properties: properties,
+		parse: func(input string) (value interface{}, length int, err error) {
+			word := readCommandWord(input)
+			number, parseErr := strconv.ParseInt(word, 10, 32)
+			if parseErr != nil {
+				err = CommandSyntaxError { fmt.Sprintf("Expected integer but found %q", word) }
+				return
+			}
+
+			err = checkCommandNumberRange("Integer", float64(number), properties)
+			if err != nil {
+				return
+			}
+
+			value = int(number)
+			length = len(word)
+			return
+		},
+	}
+}
+
+// Read with CommandContext.Double.
+func DoubleArgument() ArgumentType {
+	return doubleArgument(javaio.NumberParserProperties {})
+}
+
+// Like DoubleArgument, limited to the range from min to max, both inclusive.
+func DoubleRangeArgument(min float64, max float64) ArgumentType {
+	return doubleArgument(javaio.NumberParserProperties {
+		HasMin: true,
+		Min: min,
+		HasMax: true,
+		Max: max,
+	})
+}
+
+func doubleArgument(properties javaio.NumberParserProperties) ArgumentType {
+	return ArgumentType {
+		parser: "brigadier:double",
+		properties: properties,
+		parse: func(input string) (value interface{}, length int, err error) {
+			word := readCommandWord(input)
+
+			// Like Brigadier, which does not accept exponents, infinities or NaN
+			number, parseErr := strconv.ParseFloat(word, 64)
+			if parseErr != nil || strings.Trim(word, "0123456789.-") != "" {
+				err = CommandSyntaxError { fmt.Sprintf("Expected double but found %q", word) }
+				return
+			}
+
+			err = checkCommandNumberRange("Double", number, properties)
+			if err != nil {
+				return
+			}
+
+			value = number
+			length = len(word)
+			return
+		},
+	}
+}
+
+func checkCommandNumberRange(kind string, number float64, properties javaio.NumberParserProperties) error {
+	if properties.HasMin && number < properties.Min {
+		return CommandSyntaxError { fmt.Sprintf("%s must not be less than %v, found %v", kind, properties.Min, number) }
+	}
+
+	if properties.HasMax && number > properties.Max {
+		return CommandSyntaxError { fmt.Sprintf("%s must not be more than %v, found %v", kind, properties.Max, number) }
+	}
+
+	return nil
+}
+
+// A single word of letters, digits and the characters _-.+ Read with CommandContext.String.
+func WordArgument() ArgumentType {
+	return ArgumentType {
+		parser: "brigadier:string",
+		properties: javaio.StringParserProperties { Kind: javaio.StringParserKindSingleWord },
+		parse: func(input string) (value interface{}, length int, err error) {
+			return parseCommandWord(input)
+		},
+	}
+}
+
+// A single word like WordArgument, or any text in double quotes, where \" and \\ are escapes.
+// Read with CommandContext.String.
+func StringArgument() ArgumentType {
+	return ArgumentType {
+		parser: "brigadier:string",
+		properties: javaio.StringParserProperties { Kind: javaio.StringParserKindQuotablePhrase },
+		parse: func(input string) (value interface{}, length int, err error) {
+			if input[0] != '"' {
+				return parseCommandWord(input)
+			}
+
+			var builder strings.Builder
+			isEscaped := false
+
+			for i := 1; i < len(input); i++ {
+				char := input[i]
+
+				switch {
+				case isEscaped:
+					if char != '"' && char != '\\' {
+						err = CommandSyntaxError { fmt.Sprintf("Invalid escape sequence \\%c in quoted string", char) }
+						return
+					}
+					builder.WriteByte(char)
+					isEscaped = false
+				case char == '\\':
+					isEscaped = true
+				case char == '"':
+					value = builder.String()
+					length = i + 1
+					return
+				default:
+					builder.WriteByte(char)
+				}
+			}
+
+			err = CommandSyntaxError { "Unclosed quoted string" }
+			return
+		},
+	}
+}
+
+// The rest of the command, spaces included, so it can only be the last argument. Read with CommandContext.String.
+func GreedyStringArgument() ArgumentType {
+	return ArgumentType {
+		parser: "brigadier:string",
+		properties: javaio.StringParserProperties { Kind: javaio.StringParserKindGreedyPhrase },
+		parse: func(input string) (value interface{}, length int, err error) {
+			return input, len(input), nil
+		},
+	}
+}
+
+// The username of a player, which the client completes from the tab list.
+// The player may not be online, so look them up with Server.PlayerByName. Read with CommandContext.String.
+func PlayerArgument() ArgumentType {
+	return ArgumentType {
+		parser: "minecraft:game_profile",
+		parse: func(input string) (value interface{}, length int, err error) {
+			word := readCommandWord(input)
+			if word == "" {
+				err = CommandSyntaxError { "Expected a player name" }
+				return
+			}
+
+			return word, len(word), nil
+		},
+	}
+}
+
+// Everything up to the next space.
+func readCommandWord(input string) string {
+	if index := strings.IndexByte(input, ' '); index >= 0 {
+		return input[:index]
+	}
+
+	return input
+}
+
+func parseCommandWord(input string) (value interface{}, length int, err error) {
+	word := readCommandWord(input)
+
+	for _, char := range word {
+		isAllowed := char >= '0' && char <= '9' || char >= 'A' && char <= 'Z' || char >= 'a' && char <= 'z' ||
+			char == '_' || char == '-' || char == '.' || char == '+'
+
+		if !isAllowed {
+			err = CommandSyntaxError { fmt.Sprintf("Expected a word but found %q", word) }
+			return
+		}
+	}
+
+	if word == "" {
+		err = CommandSyntaxError { "Expected a word" }
+		return
+	}
+
+	return word, len(word), nil
+}
+
+// A literal or argument of a command, along with the nodes that may follow it.
+// Build commands with Literal and Argument, and register them with a CommandDispatcher.
+type CommandNode struct {
+	name string
+	// nil for literals.
+	argumentType *ArgumentType
+	children []*CommandNode
+	executor func(ctx *CommandContext) error
+	requirement func(conn *Connection) bool
+	suggestions func(ctx *CommandContext) []string
+}
+
+// A node matching exactly the given word, such as the name of a command.
+func Literal(name string) *CommandNode {
+	return &CommandNode { name: name }
+}
+
+// A node parsing a value of the given type, which executors read from the CommandContext under the given name.
+func Argument(name string, argumentType ArgumentType) *CommandNode {
+	return &CommandNode { name: name, argumentType: &argumentType }
+}
+
+// Adds nodes that may follow this one, separated by a space. Returns the node itself, so that calls can be chained.
+func (node *CommandNode) Then(children ...*CommandNode) *CommandNode {
+	node.children = append(node.children, children...)
+	return node
+}
+
+// Allows the command to end at this node, running the executor.
+// An error returned by the executor is shown to the player by the Server.
+func (node *CommandNode) Executes(executor func(ctx *CommandContext) error) *CommandNode {
+	node.executor = executor
+	return node
+}
+
+// Hides the node, and the nodes following it, from players for whom the requirement does not hold.
+func (node *CommandNode) Requires(requirement func(conn *Connection) bool) *CommandNode {
+	node.requirement = requirement
+	return node
+}
+
+// Has the client ask the server to complete this argument. The dispatcher keeps the suggestions
+// that start with what the player has typed so far, ignoring case, so all possible values may be returned.
+func (node *CommandNode) Suggests(suggestions func(ctx *CommandContext) []string) *CommandNode {
+	node.suggestions = suggestions
+	return node
+}
+
+func (node *CommandNode) canUse(conn *Connection) bool {
+	return node.requirement == nil || node.requirement(conn)
+}
+
+func (node *CommandNode) parse(input string) (value interface{}, length int, err error) {
+	if node.argumentType != nil {
+		return node.argumentType.parse(input)
+	}
+
+	if readCommandWord(input) != node.name {
+		err = CommandSyntaxError { fmt.Sprintf("Expected %q", node.name) }
+		return
+	}
+
+	return nil, len(node.name), nil
+}
+
+// Passed to executors and suggestion providers.
+type CommandContext struct {
+	// The player that typed the command.
+	Conn *Connection
+	// The command without the leading slash.
+	Input string
+	arguments map[string]interface{}
+}
+
+// The value of an IntegerArgument, or zero if there is no such argument.
+func (ctx *CommandContext) Int(name string) int {
+	value, _ := ctx.arguments[name].(int)
+	return value
+}
+
+// The value of a DoubleArgument, or zero if there is no such argument.
+func (ctx *CommandContext) Double(name string) float64 {
+	value, _ := ctx.arguments[name].(float64)
+	return value
+}
+
+// The value of a BoolArgument, or false if there is no such argument.
+func (ctx *CommandContext) Bool(name string) bool {
+	value, _ := ctx.arguments[name].(bool)
+	return value
+}
+
+// The value of a WordArgument, StringArgument, GreedyStringArgument or PlayerArgument,
+// or an empty string if there is no such argument.
+func (ctx *CommandContext) String(name string) string {
+	value, _ := ctx.arguments[name].(string)
+	return value
+}
+
+// Holds the registered commands, and runs and completes the commands players type.
+// The zero value is ready to use, and is safe to use from any goroutine.
+// Nodes must not be changed once they have been registered.
+type CommandDispatcher struct {
+	lock sync.Mutex
+	commands []*CommandNode
+}
+
+// Adds a command, usually built with Literal. Replaces any registered command with the same name.
+func (dispatcher *CommandDispatcher) Register(command *CommandNode) {
+	dispatcher.lock.Lock()
+	defer dispatcher.lock.Unlock()
+
+	for i, registered := range dispatcher.commands {
+		if registered.name == command.name {
+			dispatcher.commands[i] = command
+			return
+		}
+	}
+
+	dispatcher.commands = append(dispatcher.commands, command)
+}
+
+// A root node holding the commands registered so far, so that the lock need not be held while they are used.
+func (dispatcher *CommandDispatcher) root() *CommandNode {
+	dispatcher.lock.Lock()
+	defer dispatcher.lock.Unlock()
+
+	children := make([]*CommandNode, len(dispatcher.commands))
+	copy(children, dispatcher.commands)
+	return &CommandNode { children: children }
+}
+
+// Runs the command typed by a player, with or without the leading slash.
+// Returns a CommandSyntaxError if the command cannot be parsed, otherwise the error of the executor.
+func (dispatcher *CommandDispatcher) Execute(conn *Connection, text string) error {
+	input := strings.TrimPrefix(text, "/")
+
+	ctx := &CommandContext {
+		Conn: conn,
+		Input: input,
+		arguments: make(map[string]interface{}),
+	}
+
+	node, _, err := dispatcher.resolve(dispatcher.root(), ctx, input, 0)
+	if err != nil {
+		return err
+	}
+
+	return node.executor(ctx)
+}
+
+// Finds the node with an executor at which the input ends, storing the arguments on the way in ctx.
+// Like Brigadier, arguments are not tried when a literal matches, and otherwise the first branch that fits the whole input wins.
+// Otherwise the error found furthest into the input is returned, along with where it was found.
+func (dispatcher *CommandDispatcher) resolve(node *CommandNode, ctx *CommandContext, input string, cursor int) (result *CommandNode, errCursor int, err error) {
+	if input == "" {
+		if node.executor == nil {
+			errCursor = cursor
+			err = CommandSyntaxError { "Incomplete command" }
+			return
+		}
+
+		result = node
+		return
+	}
+
+	errCursor = -1
+
+	for _, child := range relevantChildren(node, ctx.Conn, input) {
+		value, length, childErr := child.parse(input)
+		if childErr != nil {
+			// A literal that does not match is not worth reporting, as any other word may have been meant
+			if child.argumentType != nil && cursor > errCursor {
+				errCursor = cursor
+				err = childErr
+			}
+			continue
+		}
+
+		rest := input[length:]
+		if rest != "" && rest[0] != ' ' {
+			if cursor + length > errCursor {
+				errCursor = cursor + length
+				err = CommandSyntaxError { fmt.Sprintf("Expected a space after %q", input[:length]) }
+			}
+			continue
+		}
+
+		if rest != "" {
+			rest = rest[1:]
+			length++
+		}
+
+		if child.argumentType != nil {
+			ctx.arguments[child.name] = value
+		}
+
+		childResult, childCursor, childErr := dispatcher.resolve(child, ctx, rest, cursor + length)
+		if childErr == nil {
+			return childResult, 0, nil
+		}
+
+		if child.argumentType != nil {
+			delete(ctx.arguments, child.name)
+		}
+
+		if childCursor > errCursor {
+			errCursor = childCursor
+			err = childErr
+		}
+	}
+
+	if err == nil {
+		errCursor = cursor
+		if cursor == 0 {
+			err = CommandSyntaxError { fmt.Sprintf("Unknown command %q", readCommandWord(input)) }
+		} else {
+			err = CommandSyntaxError { fmt.Sprintf("Incorrect argument %q", readCommandWord(input)) }
+		}
+	}
+
+	return
+}
+
+// The children the player may use, or only the literal matching the start of input, if there is one.
+func relevantChildren(node *CommandNode, conn *Connection, input string) []*CommandNode {
+	result := make([]*CommandNode, 0, len(node.children))
+
+	for _, child := range node.children {
+		if !child.canUse(conn) {
+			continue
+		}
+
+		if child.argumentType == nil && input != "" {
+			if _, _, err := child.parse(input); err == nil {
+				return []*CommandNode { child }
+			}
+		}
+
+		result = append(result, child)
+	}
+
+	return result
+}
+
+// Completions for a partly typed command. Start and Length are in bytes, and select the part of the text the matches replace.
+type TabCompleteResponse struct {
+	Start int
+	Length int
+	Matches []string
+}
+
+// Completes the last word of a command typed by a player, with or without the leading slash.
+// Only literals and arguments with suggestion providers are completed, the client completes the rest itself.
+func (dispatcher *CommandDispatcher) Suggest(conn *Connection, text string) TabCompleteResponse {
+	offset := 0
+	if strings.HasPrefix(text, "/") {
+		offset = 1
+	}
+
+	ctx := &CommandContext {
+		Conn: conn,
+		Input: text[offset:],
+		arguments: make(map[string]interface{}),
+	}
+
+	result := TabCompleteResponse {
+		Start: len(text),
+		Matches: make([]string, 0),
+	}
+
+	dispatcher.suggest(dispatcher.root(), ctx, text[offset:], offset, &result)
+
+	sort.Strings(result.Matches)
+	return result
+}
+
+// Suggestions found deeper into the text replace those found earlier, as they are more specific.
+func (dispatcher *CommandDispatcher) suggest(node *CommandNode, ctx *CommandContext, input string, cursor int, result *TabCompleteResponse) {
+	for _, child := range relevantChildren(node, ctx.Conn, input) {
+		if input != "" {
+			value, length, err := child.parse(input)
+			if err == nil && length < len(input) && input[length] == ' ' {
+				if child.argumentType != nil {
+					ctx.arguments[child.name] = value
+				}
+
+				dispatcher.suggest(child, ctx, input[length + 1:], cursor + length + 1, result)
+
+				if child.argumentType != nil {
+					delete(ctx.arguments, child.name)
+				}
+				continue
+			}
+		}
+
+		// The child is still being typed
+		var candidates []string
+		if child.argumentType == nil {
+			candidates = []string { child.name }
+		} else if child.suggestions != nil {
+			candidates = child.suggestions(ctx)
+		}
+
+		for _, candidate := range candidates {
+			if !strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(input)) {
+				continue
+			}
+
+			if cursor > result.Start || len(result.Matches) == 0 {
+				result.Start = cursor
+				result.Length = len(input)
+				result.Matches = result.Matches[:0]
+			} else if cursor < result.Start {
+				continue
+			}
+
+			result.Matches = append(result.Matches, candidate)
+		}
+	}
+}
+
+// Sends the commands the player may use, so that the client can highlight and complete them.
+// The Server does so when a player joins. Send them again once the requirements of a player have changed.
+func (dispatcher *CommandDispatcher) SendTo(conn *Connection) error {
+	root := dispatcher.root()
+
+	// Nodes are numbered in the order they are found, so that the root is always zero
+	indices := map[*CommandNode]int32 { root: 0 }
+	queue := []*CommandNode { root }
+	nodes := make([]javaio.CommandNode, 0)
+
+	for i := 0; i < len(queue); i++ {
+		node := queue[i]
+
+		packetNode := javaio.CommandNode {
+			IsExecutable: node.executor != nil,
+			Children: make([]int32, 0),
+		}
+
+		for _, child := range node.children {
+			if !child.canUse(conn) {
+				continue
+			}
+
+			index, ok := indices[child]
+			if !ok {
+				index = int32(len(queue))
+				indices[child] = index
+				queue = append(queue, child)
+			}
+
+			packetNode.Children = append(packetNode.Children, index)
+		}
+
+		switch {
+		case node == root:
+			packetNode.Type = javaio.CommandNodeTypeRoot
+		case node.argumentType == nil:
+			packetNode.Type = javaio.CommandNodeTypeLiteral
+			packetNode.Name = node.name
+		default:
+			packetNode.Type = javaio.CommandNodeTypeArgument
+			packetNode.Name = node.name
+			packetNode.Parser = node.argumentType.parser
+			packetNode.Properties = node.argumentType.properties
+			if node.suggestions != nil {
+				packetNode.SuggestionsType = "minecraft:ask_server"
+			}
+		}
+
+		nodes = append(nodes, packetNode)
+	}
+
+	return conn.send(javaio.DeclareCommands {
+		Nodes: nodes,
+		RootIndex: 0,
+	})
+}
+
+func (conn *Connection) processTabComplete(data javaio.TabCompleteSb) {
+	if conn.eventHandlers.OnTabComplete == nil {
+		return
+	}
+
+	response := conn.eventHandlers.OnTabComplete(data.Text)
+
+	start := response.Start
+	if start < 0 || start > len(data.Text) {
+		start = len(data.Text)
+	}
+
+	end := start + response.Length
+	if end < start || end > len(data.Text) {
+		end = len(data.Text)
+	}
+
+	matches := make([]javaio.TabCompleteMatch, 0, len(response.Matches))
+	for _, match := range response.Matches {
+		matches = append(matches, javaio.TabCompleteMatch { Match: match })
+	}
+
+	conn.send(javaio.TabComplete {
+		TransactionId: data.TransactionId,
+		Start: int32(utf16Length(data.Text[:start])),
+		Length: int32(utf16Length(data.Text[start:end])),
+		Matches: matches,
+	})
+}
+
+// The client counts characters like Java strings do, in UTF-16 code units.
+func utf16Length(text string) int {
+	length := 0
+	for _, char := range text {
+		if char > 0xffff {
+			length += 2
+		} else {
+			length++
+		}
+	}
+	return length
+}
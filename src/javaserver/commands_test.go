@@ -0,0 +1,189 @@
+package javaserver
+
+import "fmt"
+import "bufio"
+import "reflect"
+import "testing"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+func newTestDispatcher(results *[]string, isOperator *bool) *CommandDispatcher {
+	record := func(ctx *CommandContext) error {
+		*results = append(*results, fmt.Sprintf("%s %d %v %v %q", ctx.Input, ctx.Int("count"), ctx.Double("x"), ctx.Bool("flag"), ctx.String("text")))
+		return nil
+	}
+
+	dispatcher := &CommandDispatcher {}
+
+	dispatcher.Register(Literal("give").Then(
+		Argument("text", PlayerArgument()).Then(
+			Argument("count", IntegerRangeArgument(1, 64)).Executes(record),
+		),
+	))
+
+	dispatcher.Register(Literal("name").Then(
+		Argument("text", StringArgument()).Executes(record),
+	))
+
+	dispatcher.Register(Literal("say").Then(
+		Argument("text", GreedyStringArgument()).Executes(record),
+	))
+
+	dispatcher.Register(Literal("set").Then(
+		Literal("flag").Then(Argument("flag", BoolArgument()).Executes(record)),
+		Argument("x", DoubleArgument()).Executes(record),
+		Argument("text", StringArgument()).Executes(record).Suggests(func(ctx *CommandContext) []string {
+			return []string { "Apple", "apricot", "banana" }
+		}),
+	))
+
+	dispatcher.Register(Literal("stop").Executes(record).Requires(func(conn *Connection) bool {
+		return *isOperator
+	}))
+
+	return dispatcher
+}
+
+func TestCommandExecute(t *testing.T) {
+	iomap := []struct {
+		input string
+		result string
+		err error
+	} {
+		{"/give Notch 64", `give Notch 64 64 0 false "Notch"`, nil},
+		{"give Notch 1", `give Notch 1 1 0 false "Notch"`, nil},
+		{"/give Notch 65", "", CommandSyntaxError { "Integer must not be more than 64, found 65" }},
+		{"/give Notch", "", CommandSyntaxError { "Incomplete command" }},
+		{"/give Notch 5 6", "", CommandSyntaxError { `Incorrect argument "6"` }},
+		{"/say hello  there", `say hello  there 0 0 false "hello  there"`, nil},
+		{"/set flag true", `set flag true 0 0 true ""`, nil},
+		// Like Brigadier, arguments are not tried once a literal matches
+		{"/set flag", "", CommandSyntaxError { "Incomplete command" }},
+		{"/set flagged", `set flagged 0 0 false "flagged"`, nil},
+		{"/set -1.5", `set -1.5 0 -1.5 false ""`, nil},
+		{`/set "a \"quoted\" word"`, `set "a \"quoted\" word" 0 0 false "a \"quoted\" word"`, nil},
+		{`/name "unclosed`, "", CommandSyntaxError { "Unclosed quoted string" }},
+		{`/set "a"b`, "", CommandSyntaxError { `Expected a space after "\"a\""` }},
+		{"/set flag maybe", "", CommandSyntaxError { `Expected true or false but found "maybe"` }},
+		{"/stop", "", CommandSyntaxError { `Unknown command "stop"` }},
+		{"/teleport", "", CommandSyntaxError { `Unknown command "teleport"` }},
+	}
+
+	var results []string
+	isOperator := false
+	dispatcher := newTestDispatcher(&results, &isOperator)
+
+	for i, mapping := range iomap {
+		results = nil
+		err := dispatcher.Execute(nil, mapping.input)
+
+		if err != mapping.err {
+			t.Errorf("Error incorrect for mapping %d: %v", i, err)
+		}
+
+		if mapping.err == nil && (len(results) != 1 || results[0] != mapping.result) {
+			t.Errorf("Result incorrect for mapping %d: %q", i, results)
+		}
+	}
+
+	isOperator = true
+	results = nil
+	if err := dispatcher.Execute(nil, "/stop"); err != nil || len(results) != 1 {
+		t.Errorf("Command with met requirement not executed: %v", err)
+	}
+}
+
+func TestCommandSuggest(t *testing.T) {
+	iomap := []struct {
+		input string
+		output TabCompleteResponse
+	} {
+		{"/", TabCompleteResponse { Start: 1, Length: 0, Matches: []string { "give", "name", "say", "set" } }},
+		{"/s", TabCompleteResponse { Start: 1, Length: 1, Matches: []string { "say", "set" } }},
+		{"/set ", TabCompleteResponse { Start: 5, Length: 0, Matches: []string { "Apple", "apricot", "banana", "flag" } }},
+		{"/set a", TabCompleteResponse { Start: 5, Length: 1, Matches: []string { "Apple", "apricot" } }},
+		{"/set flag t", TabCompleteResponse { Start: 11, Length: 0, Matches: []string {} }},
+		{"/teleport ", TabCompleteResponse { Start: 10, Length: 0, Matches: []string {} }},
+	}
+
+	var results []string
+	isOperator := false
+	dispatcher := newTestDispatcher(&results, &isOperator)
+
+	for i, mapping := range iomap {
+		if output := dispatcher.Suggest(nil, mapping.input); !reflect.DeepEqual(output, mapping.output) {
+			t.Errorf("Output incorrect for mapping %d: %#v", i, output)
+		}
+	}
+}
+
+func TestCommandPackets(t *testing.T) {
+	var results []string
+	isOperator := false
+	dispatcher := newTestDispatcher(&results, &isOperator)
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {
+		OnTabComplete: func(text string) TabCompleteResponse {
+			return dispatcher.Suggest(nil, text)
+		},
+	})
+	defer conn.Close()
+
+	input := bufio.NewReader(clientSide)
+
+	dispatcher.SendTo(conn)
+
+	declared := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.DeclareCommands)
+		return ok
+	}).(javaio.DeclareCommands)
+
+	// The stop command is left out, as its requirement is not met
+	root := declared.Nodes[declared.RootIndex]
+	if root.Type != javaio.CommandNodeTypeRoot || len(root.Children) != 4 {
+		t.Fatalf("Root node incorrect: %#v", root)
+	}
+
+	give := declared.Nodes[root.Children[0]]
+	player := declared.Nodes[give.Children[0]]
+	count := declared.Nodes[player.Children[0]]
+
+	expected := javaio.CommandNode {
+		Type: javaio.CommandNodeTypeArgument,
+		IsExecutable: true,
+		Children: []int32 {},
+		Name: "count",
+		Parser: "brigadier:integer",
+		Properties: javaio.NumberParserProperties { HasMin: true, Min: 1, HasMax: true, Max: 64 },
+	}
+
+	if give.Name != "give" || player.Parser != "minecraft:game_profile" || !reflect.DeepEqual(count, expected) {
+		t.Errorf("Give command incorrect: %#v, %#v, %#v", give, player, count)
+	}
+
+	output := bufio.NewWriter(clientSide)
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+
+	// Offsets are counted in UTF-16 code units, so the emoji counts twice
+	javaio.EmitServerboundPacketUncompressed(javaio.TabCompleteSb { TransactionId: 7, Text: "/set \"😀\" " }, ctx, output)
+
+	completed := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.TabComplete)
+		return ok
+	}).(javaio.TabComplete)
+
+	if completed.TransactionId != 7 || completed.Start != 10 || completed.Length != 0 || len(completed.Matches) != 0 {
+		t.Errorf("Tab completion incorrect: %#v", completed)
+	}
+
+	javaio.EmitServerboundPacketUncompressed(javaio.TabCompleteSb { TransactionId: 8, Text: "/set b" }, ctx, output)
+
+	completed = expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.TabComplete)
+		return ok
+	}).(javaio.TabComplete)
+
+	if completed.TransactionId != 8 || completed.Start != 5 || completed.Length != 1 ||
+		!reflect.DeepEqual(completed.Matches, []javaio.TabCompleteMatch { { Match: "banana" } }) {
+		t.Errorf("Tab completion incorrect: %#v", completed)
+	}
+}
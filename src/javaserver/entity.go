@@ -0,0 +1,352 @@
+package javaserver
+
+import "fmt"
+import "math"
+import "sync"
+import "sync/atomic"
+import "github.com/davidcallanan/go-mcp/registry"
+import "github.com/google/uuid"
+
+// Used when EntityRegistry.ViewDistance is not set.
+const DefaultEntityViewDistance = 64
+
+// Named like in registry.EntityTypes, such as "minecraft:pig".
+type EntityType string
+const (
+	EntityTypeInvalid EntityType = ""
+	EntityTypePlayer EntityType = "minecraft:player"
+)
+
+func (entityType EntityType) String() string {
+	if entityType == EntityTypeInvalid {
+		return "invalid"
+	}
+
+	return string(entityType)
+}
+
+// Returned when an entity cannot be spawned with EntityRegistry.Spawn.
+type UnsupportedEntityTypeError struct {
+	details string
+}
+
+func (err UnsupportedEntityTypeError) Error() string {
+	return fmt.Sprintf("Unsupported entity type: %s", err.details)
+}
+
+type EntityPosition struct {
+	X float64
+	Y float64
+	Z float64
+	Yaw float32
+	Pitch float32
+	OnGround bool
+}
+
+// Allocates entity ids and shows each entity to the players close enough to see it.
+// The zero value is ready to use, and is safe to use from any goroutine.
+type EntityRegistry struct {
+	// Entities further away from a player than this many blocks horizontally are not shown to them.
+	// Defaults to DefaultEntityViewDistance when zero.
+	ViewDistance float64
+
+	// Accessed atomically, as ids are allocated while logging in, without the lock.
+	lastId int32
+	lock sync.Mutex
+	entities map[int32]*Entity
+	// The entity of each player, whose position decides what the player sees.
+	playerEntities map[*Connection]*Entity
+}
+
+type Entity struct {
+	registry *EntityRegistry
+	id int32
+	entityType EntityType
+	uuid uuid.UUID
+	// The connection of the player this entity represents, which is never shown its own entity.
+	owner *Connection
+	// Guarded by the lock of the registry, like the rest of the fields below.
+	position EntityPosition
+	// The connections this entity has been spawned on.
+	viewers map[*Connection]struct{}
+	isRemoved bool
+}
+
+// Never returns zero, so that zero can mean that no id has been allocated.
+func (registry *EntityRegistry) AllocateId() int32 {
+	return atomic.AddInt32(&registry.lastId, 1)
+}
+
+// The last protocol version whose entity types are known.
+const latestEntityTypeProtocol = 0x0293
+
+// Must be called with the lock held.
+func (registry *EntityRegistry) init() {
+	if registry.entities == nil {
+		registry.entities = make(map[int32]*Entity)
+		registry.playerEntities = make(map[*Connection]*Entity)
+	}
+}
+
+// Tracks the player of a connection that has joined, under the entity id and UUID it joined with.
+// The player is spawned for the players that can see them, and is shown the entities it can see in turn.
+func (registry *EntityRegistry) AddPlayer(conn *Connection, position EntityPosition) *Entity {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.init()
+
+	entity := &Entity {
+		registry: registry,
+		id: conn.EntityId(),
+		entityType: EntityTypePlayer,
+		uuid: conn.Uuid(),
+		owner: conn,
+		position: position,
+		viewers: make(map[*Connection]struct{}),
+	}
+
+	registry.entities[entity.id] = entity
+	registry.playerEntities[conn] = entity
+
+	registry.updateViewers(entity)
+	registry.updateView(entity)
+
+	return entity
+}
+
+// Tracks a new mob, such as "minecraft:pig", and spawns it for the players close enough to see it.
+// Players are added with AddPlayer instead, and entities that are not mobs, such as items, cannot be spawned yet.
+// Clients whose version does not have the type are not shown the entity.
+func (entityRegistry *EntityRegistry) Spawn(entityType EntityType, position EntityPosition) (*Entity, error) {
+	if entityType == EntityTypePlayer {
+		return nil, UnsupportedEntityTypeError { "Players are added with AddPlayer" }
+	}
+
+	// Every type of an earlier version is still there in the latest one
+	id, err := registry.EntityTypes.LookupId(string(entityType), latestEntityTypeProtocol)
+	if err != nil {
+		return nil, err
+	}
+
+	// With the namespace, which may have been left out
+	name, _ := registry.EntityTypes.Name(id, latestEntityTypeProtocol)
+
+	entity := &Entity {
+		registry: entityRegistry,
+		id: entityRegistry.AllocateId(),
+		entityType: EntityType(name),
+		uuid: uuid.New(),
+		position: position,
+		viewers: make(map[*Connection]struct{}),
+	}
+
+	entityRegistry.lock.Lock()
+	defer entityRegistry.lock.Unlock()
+
+	entityRegistry.init()
+	entityRegistry.entities[entity.id] = entity
+	entityRegistry.updateViewers(entity)
+
+	return entity, nil
+}
+
+// The entity with this id, or nil.
+func (registry *EntityRegistry) Entity(entityId int32) *Entity {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	return registry.entities[entityId]
+}
+
+// The entity of the player of this connection, or nil.
+func (registry *EntityRegistry) PlayerEntity(conn *Connection) *Entity {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	return registry.playerEntities[conn]
+}
+
+func (registry *EntityRegistry) viewDistance() float64 {
+	if registry.ViewDistance <= 0 {
+		return DefaultEntityViewDistance
+	}
+
+	return registry.ViewDistance
+}
+
+// Whether the player of viewer is close enough to see the entity.
+func (registry *EntityRegistry) canSee(viewer *Entity, entity *Entity) bool {
+	if viewer == entity {
+		return false
+	}
+
+	distance := math.Max(math.Abs(viewer.position.X - entity.position.X), math.Abs(viewer.position.Z - entity.position.Z))
+	return distance <= registry.viewDistance()
+}
+
+// Spawns or destroys the entity for each player whose view it has entered or left.
+// Players that can still see it are sent the move from previous.
+// Must be called with the lock held.
+func (registry *EntityRegistry) updateViewersAfterMove(entity *Entity, previous EntityPosition) {
+	for conn, viewer := range registry.playerEntities {
+		_, isViewer := entity.viewers[conn]
+		canSee := registry.canSee(viewer, entity)
+
+		switch {
+		case isViewer && canSee:
+			conn.MoveEntity(entity.id, previous, entity.position)
+		case isViewer:
+			delete(entity.viewers, conn)
+			conn.DestroyEntities([]int32 { entity.id })
+		case canSee:
+			entity.viewers[conn] = struct{}{}
+			entity.spawnFor(conn)
+		}
+	}
+}
+
+// Like updateViewersAfterMove, for an entity that has not been spawned for anyone yet.
+// Must be called with the lock held.
+func (registry *EntityRegistry) updateViewers(entity *Entity) {
+	for conn, viewer := range registry.playerEntities {
+		if registry.canSee(viewer, entity) {
+			entity.viewers[conn] = struct{}{}
+			entity.spawnFor(conn)
+		}
+	}
+}
+
+// Spawns or destroys the entities that have entered or left the view of a player.
+// Must be called with the lock held.
+func (registry *EntityRegistry) updateView(player *Entity) {
+	conn := player.owner
+	var destroyed []int32
+
+	for _, entity := range registry.entities {
+		_, isViewer := entity.viewers[conn]
+		canSee := registry.canSee(player, entity)
+
+		if isViewer && !canSee {
+			delete(entity.viewers, conn)
+			destroyed = append(destroyed, entity.id)
+		} else if !isViewer && canSee {
+			entity.viewers[conn] = struct{}{}
+			entity.spawnFor(conn)
+		}
+	}
+
+	if len(destroyed) > 0 {
+		conn.DestroyEntities(destroyed)
+	}
+}
+
+func (entity *Entity) Id() int32 {
+	return entity.id
+}
+
+func (entity *Entity) Type() EntityType {
+	return entity.entityType
+}
+
+func (entity *Entity) Uuid() uuid.UUID {
+	return entity.uuid
+}
+
+func (entity *Entity) Position() EntityPosition {
+	entity.registry.lock.Lock()
+	defer entity.registry.lock.Unlock()
+	return entity.position
+}
+
+// Must be called with the lock held.
+func (entity *Entity) spawnFor(conn *Connection) {
+	if entity.entityType != EntityTypePlayer {
+		conn.SpawnLivingEntity(LivingEntityToSpawn {
+			EntityId: entity.id,
+			Uuid: entity.uuid,
+			Type: entity.entityType,
+			X: entity.position.X,
+			Y: entity.position.Y,
+			Z: entity.position.Z,
+			Yaw: entity.position.Yaw,
+			Pitch: entity.position.Pitch,
+		})
+		return
+	}
+
+	conn.SpawnPlayer(PlayerToSpawn {
+		EntityId: entity.id,
+		Uuid: entity.uuid,
+		X: entity.position.X,
+		Y: entity.position.Y,
+		Z: entity.position.Z,
+		Yaw: entity.position.Yaw,
+		Pitch: entity.position.Pitch,
+	})
+
+	conn.SetEntityHeadYaw(entity.id, entity.position.Yaw)
+}
+
+// Moves the entity for everyone that can see it.
+// For a player, the entities that come into or go out of their view are spawned or destroyed as well.
+func (entity *Entity) Move(position EntityPosition) {
+	registry := entity.registry
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if entity.isRemoved {
+		return
+	}
+
+	previous := entity.position
+	entity.position = position
+
+	registry.updateViewersAfterMove(entity, previous)
+
+	if entity.owner != nil {
+		registry.updateView(entity)
+	}
+}
+
+// Sets the velocity of the entity, in blocks per second, for everyone that can see it.
+func (entity *Entity) SetVelocity(x float64, y float64, z float64) {
+	registry := entity.registry
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	for conn := range entity.viewers {
+		conn.SetEntityVelocity(EntityVelocity {
+			EntityId: entity.id,
+			X: x,
+			Y: y,
+			Z: z,
+		})
+	}
+}
+
+// Destroys the entity for everyone that can see it and stops tracking it.
+// A removed player no longer sees any entities, but nothing is destroyed on their connection, as they have usually left.
+func (entity *Entity) Remove() {
+	registry := entity.registry
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	if entity.isRemoved {
+		return
+	}
+
+	entity.isRemoved = true
+	delete(registry.entities, entity.id)
+
+	for conn := range entity.viewers {
+		conn.DestroyEntities([]int32 { entity.id })
+	}
+	entity.viewers = nil
+
+	if entity.owner != nil {
+		delete(registry.playerEntities, entity.owner)
+
+		for _, other := range registry.entities {
+			delete(other.viewers, entity.owner)
+		}
+	}
+}
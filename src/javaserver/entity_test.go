@@ -0,0 +1,249 @@
+package javaserver
+
+import "bufio"
+import "context"
+import "testing"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/davidcallanan/go-mcp/registry"
+
+// Reads packets until one matches, skipping those that cannot be parsed.
+func expectPacket(t *testing.T, input *bufio.Reader, match func(packet interface{}) bool) interface{} {
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+
+	for {
+		packet, err := javaio.ParseClientboundPacketUncompressed(input, ctx, ctx.State)
+		if _, ok := err.(javaio.UnknownPacketError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Stream ended before expected packet: %v", err)
+		}
+
+		if match(packet) {
+			return packet
+		}
+	}
+}
+
+func expectSpawn(t *testing.T, input *bufio.Reader, entityId int32) javaio.Packet_SpawnPlayer {
+	return expectPacket(t, input, func(packet interface{}) bool {
+		spawn, ok := packet.(javaio.Packet_SpawnPlayer)
+		return ok && spawn.EntityId == entityId
+	}).(javaio.Packet_SpawnPlayer)
+}
+
+func expectDestroy(t *testing.T, input *bufio.Reader, entityId int32) {
+	expectPacket(t, input, func(packet interface{}) bool {
+		destroy, ok := packet.(javaio.DestroyEntities)
+		return ok && len(destroy.EntityIds) == 1 && destroy.EntityIds[0] == entityId
+	})
+}
+
+func expectJoinGame(t *testing.T, input *bufio.Reader) int32 {
+	return expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.JoinGame)
+		return ok
+	}).(javaio.JoinGame).EntityId
+}
+
+func TestEntityTracking(t *testing.T) {
+	server, addr, _ := newTestServer(t)
+	defer server.Shutdown(context.Background())
+
+	notchClient := joinTestServer(t, addr, "Notch")
+	defer notchClient.Close()
+	notchInput := bufio.NewReader(notchClient)
+	notchId := expectJoinGame(t, notchInput)
+	waitForPlayerCount(t, server, 1)
+
+	jebClient := joinTestServer(t, addr, "jeb_")
+	defer jebClient.Close()
+	jebInput := bufio.NewReader(jebClient)
+	jebId := expectJoinGame(t, jebInput)
+
+	if notchId == 0 || jebId == 0 || notchId == jebId {
+		t.Fatalf("Entity ids not allocated: %d and %d", notchId, jebId)
+	}
+
+	// Both players see each other once the second one has joined
+	if spawn := expectSpawn(t, jebInput, notchId); spawn.Uuid != testPlayerUuid("Notch") || spawn.Y != 64 {
+		t.Errorf("Spawned incorrectly: %#v", spawn)
+	}
+	expectSpawn(t, notchInput, jebId)
+
+	notch := server.Entities.Entity(notchId)
+	if notch == nil || notch.Type() != EntityTypePlayer || server.Entities.PlayerEntity(server.Player(testPlayerUuid("Notch"))) != notch {
+		t.Fatal("Entity of player not tracked")
+	}
+
+	// Players are moved by their own movement
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+	err := javaio.EmitServerboundPacketUncompressed(javaio.Packet_PlayerPosSb { X: 1, Y: 64, Z: 0.5 }, ctx, bufio.NewWriter(notchClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	move := expectPacket(t, jebInput, func(packet interface{}) bool {
+		_, ok := packet.(javaio.EntityRelativeMove)
+		return ok
+	}).(javaio.EntityRelativeMove)
+
+	if move.EntityId != notchId || move.DeltaX != 4096 || move.DeltaY != 0 || move.DeltaZ != 2048 {
+		t.Errorf("Moved incorrectly: %#v", move)
+	}
+
+	// Out of view of each other
+	notch.Move(EntityPosition { X: DefaultEntityViewDistance + 1, Y: 64 })
+	expectDestroy(t, jebInput, notchId)
+	expectDestroy(t, notchInput, jebId)
+
+	// And back into view
+	notch.Move(EntityPosition { X: 10, Y: 64 })
+	if spawn := expectSpawn(t, jebInput, notchId); spawn.X != 10 {
+		t.Errorf("Spawned at wrong position: %#v", spawn)
+	}
+	expectSpawn(t, notchInput, jebId)
+
+	notchClient.Close()
+	expectDestroy(t, jebInput, notchId)
+	waitForPlayerCount(t, server, 1)
+
+	if server.Entities.Entity(notchId) != nil {
+		t.Error("Entity of player that left is still tracked")
+	}
+}
+
+func TestEntitySpawn(t *testing.T) {
+	server, addr, _ := newTestServer(t)
+	defer server.Shutdown(context.Background())
+
+	// Mobs spawned before a player joins are shown to them as they join
+	pig, err := server.Entities.Spawn("pig", EntityPosition { X: 2, Y: 64, Yaw: 90 })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pig.Type() != "minecraft:pig" || server.Entities.Entity(pig.Id()) != pig {
+		t.Fatalf("Pig not tracked: %v", pig.Type())
+	}
+
+	client := joinTestServer(t, addr, "Notch")
+	defer client.Close()
+	input := bufio.NewReader(client)
+	expectJoinGame(t, input)
+
+	spawn := expectPacket(t, input, func(packet interface{}) bool {
+		spawn, ok := packet.(javaio.SpawnLivingEntity)
+		return ok && spawn.EntityId == pig.Id()
+	}).(javaio.SpawnLivingEntity)
+
+	// The id of a pig in 1.15
+	if spawn.Uuid != pig.Uuid() || spawn.Type != 55 || spawn.X != 2 || spawn.Y != 64 || spawn.Yaw != 64 || spawn.HeadYaw != 64 {
+		t.Errorf("Spawned incorrectly: %#v", spawn)
+	}
+
+	pig.Move(EntityPosition { X: 3, Y: 64, Yaw: 90 })
+	move := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.EntityRelativeMove)
+		return ok
+	}).(javaio.EntityRelativeMove)
+
+	if move.EntityId != pig.Id() || move.DeltaX != 4096 {
+		t.Errorf("Moved incorrectly: %#v", move)
+	}
+
+	pig.Move(EntityPosition { X: DefaultEntityViewDistance + 10, Y: 64 })
+	expectDestroy(t, input, pig.Id())
+
+	pig.Remove()
+	if server.Entities.Entity(pig.Id()) != nil {
+		t.Error("Removed pig still tracked")
+	}
+
+	if _, err := server.Entities.Spawn(EntityTypePlayer, EntityPosition {}); err == nil {
+		t.Error("Spawned a player")
+	} else if _, ok := err.(UnsupportedEntityTypeError); !ok {
+		t.Errorf("Expected UnsupportedEntityTypeError but instead got: %v", err)
+	}
+
+	if _, err := server.Entities.Spawn("minecraft:unicorn", EntityPosition {}); err == nil {
+		t.Error("Spawned an unknown entity")
+	} else if _, ok := err.(registry.UnknownEntryError); !ok {
+		t.Errorf("Expected UnknownEntryError but instead got: %v", err)
+	}
+}
+
+func TestMoveEntityPackets(t *testing.T) {
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {})
+	defer conn.Close()
+	input := bufio.NewReader(clientSide)
+
+	start := EntityPosition { X: 0.5, Y: 64, Z: 0.5, Yaw: 90 }
+
+	iomap := []struct {
+		position EntityPosition
+		packet interface{}
+	} {
+		{EntityPosition { X: 1.5, Y: 64, Z: 0.5, Yaw: 90 }, javaio.EntityRelativeMove { EntityId: 1, DeltaX: 4096 }},
+		{EntityPosition { X: 0.5, Y: 64, Z: 0.5, Yaw: 90, OnGround: true }, javaio.EntityRelativeMove { EntityId: 1, OnGround: true }},
+		{EntityPosition { X: 0.5, Y: 64, Z: 0.5, Yaw: 90, Pitch: -45 }, javaio.EntityLook { EntityId: 1, Yaw: 64, Pitch: 224 }},
+		{EntityPosition { X: 0.5, Y: 63, Z: 0.5, Yaw: -90 }, javaio.Packet_EntityTranslate { EntityId: 1, DeltaY: -4096, Yaw: 192 }},
+		{EntityPosition { X: 8.5, Y: 64, Z: 0.5, Yaw: 90 }, javaio.EntityTeleport { EntityId: 1, X: 8.5, Y: 64, Z: 0.5, Yaw: 64 }},
+		{EntityPosition { X: 0.5, Y: 64, Z: -100, Yaw: 450 }, javaio.EntityTeleport { EntityId: 1, X: 0.5, Y: 64, Z: -100, Yaw: 64 }},
+	}
+
+	for i, mapping := range iomap {
+		err := conn.MoveEntity(1, start, mapping.position)
+		if err != nil {
+			t.Fatalf("Failed to move for mapping %d: %v", i, err)
+		}
+
+		packet := expectPacket(t, input, func(packet interface{}) bool {
+			switch packet.(type) {
+			case javaio.EntityRelativeMove, javaio.EntityLook, javaio.Packet_EntityTranslate, javaio.EntityTeleport, javaio.EntityHeadLook:
+				return true
+			}
+			return false
+		})
+
+		if packet != mapping.packet {
+			t.Errorf("Packet incorrect for mapping %d: %#v", i, packet)
+		}
+
+		// The head follows the body when the yaw changes
+		if encodeAngle(mapping.position.Yaw) != encodeAngle(start.Yaw) {
+			headLook := expectPacket(t, input, func(packet interface{}) bool {
+				_, ok := packet.(javaio.EntityHeadLook)
+				return ok
+			}).(javaio.EntityHeadLook)
+
+			if headLook.HeadYaw != encodeAngle(mapping.position.Yaw) {
+				t.Errorf("Head yaw incorrect for mapping %d: %d", i, headLook.HeadYaw)
+			}
+		}
+	}
+
+	err := conn.TranslateEntity(EntityTranslation { EntityId: 1, DeltaX: 8 })
+	if _, ok := err.(MoveTooFarError); !ok {
+		t.Errorf("Expected MoveTooFarError but instead got: %v", err)
+	}
+}
+
+func TestEntityVelocityClamped(t *testing.T) {
+	iomap := []struct {
+		velocity float64
+		encoded int16
+	} {
+		{0, 0},
+		{1, 400},
+		{-2.5, -1000},
+		{78, 31200},
+		{1000, 31200},
+		{-1e9, -31200},
+	}
+
+	for i, mapping := range iomap {
+		if encoded := encodeVelocity(mapping.velocity); encoded != mapping.encoded {
+			t.Errorf("Output incorrect for mapping %d: %d", i, encoded)
+		}
+	}
+}
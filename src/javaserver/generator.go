@@ -0,0 +1,281 @@
+package javaserver
+
+import "fmt"
+import "math"
+import "strconv"
+import "strings"
+import "math/rand"
+import "github.com/davidcallanan/go-mcp/registry"
+
+// Makes up the terrain of chunks, the same every time for the same position and settings.
+// A Generator can be the Provider of a World, or the Fallback of an AnvilChunkProvider for the chunks that have not been saved.
+type Generator interface {
+	ChunkProvider
+	// The y of the lowest block above the terrain at the given block coordinates, where a player can stand.
+	SurfaceHeight(x int32, z int32) int
+}
+
+// Returned when the layers of a flat world cannot be parsed.
+type InvalidLayersError struct {
+	details string
+}
+
+func (err InvalidLayersError) Error() string {
+	return fmt.Sprintf("Invalid layers: %s", err.details)
+}
+
+// The layers of the flat world used by a World without a provider, which has grass at y 63 like the sea level of vanilla worlds.
+const DefaultFlatLayers = "minecraft:bedrock,47*minecraft:stone,15*minecraft:dirt,minecraft:grass_block"
+
+var defaultGenerator = func() *FlatGenerator {
+	generator, err := NewFlatGenerator(DefaultFlatLayers)
+	if err != nil {
+		panic(fmt.Sprintf("Internal package bug: %v", err))
+	}
+
+	return generator
+}()
+
+// Generates the same layers of blocks everywhere, like the superflat worlds of vanilla.
+// Safe to use from multiple goroutines.
+type FlatGenerator struct {
+	// From y 0 upwards.
+	layers []uint32
+}
+
+// Parses layers written like in the superflat presets of vanilla, from the bottom up, such as
+// "minecraft:bedrock,2*minecraft:dirt,minecraft:grass_block". Blocks may be given properties, such as minecraft:snow[layers=2].
+// The blocks use the ids of 1.14, like the rest of the built-in worlds.
+func NewFlatGenerator(layers string) (*FlatGenerator, error) {
+	generator := &FlatGenerator {}
+
+	for _, layer := range splitLayers(layers) {
+		count := 1
+		block := strings.TrimSpace(layer)
+
+		if i := strings.Index(block, "*"); i >= 0 {
+			var err error
+			count, err = strconv.Atoi(strings.TrimSpace(block[:i]))
+			if err != nil || count < 1 {
+				return nil, InvalidLayersError { fmt.Sprintf("Invalid count in %q", layer) }
+			}
+			block = strings.TrimSpace(block[i + 1:])
+		}
+
+		id, err := registry.Blocks.LookupId(block, builtinBlockProtocol)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(generator.layers) + count > ChunkSectionCount * 16 {
+			return nil, InvalidLayersError { fmt.Sprintf("More than %d layers in %q", ChunkSectionCount * 16, layers) }
+		}
+
+		for i := 0; i < count; i++ {
+			generator.layers = append(generator.layers, id)
+		}
+	}
+
+	return generator, nil
+}
+
+// Splits at the commas between layers, leaving those between the properties of a block.
+func splitLayers(layers string) []string {
+	var result []string
+	depth := 0
+	start := 0
+
+	for i, c := range layers {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, layers[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, layers[start:])
+}
+
+func (generator *FlatGenerator) ProvideChunk(x int32, z int32) (*ChunkColumn, error) {
+	chunk := NewChunkColumn(x, z)
+
+	for y, block := range generator.layers {
+		if block == 0 {
+			continue
+		}
+
+		for z := 0; z < 16; z++ {
+			for x := 0; x < 16; x++ {
+				chunk.SetBlock(x, y, z, block)
+			}
+		}
+	}
+
+	return chunk, nil
+}
+
+// Above the top layer, wherever it is.
+func (generator *FlatGenerator) SurfaceHeight(x int32, z int32) int {
+	return len(generator.layers)
+}
+
+var (
+	noiseBedrock = builtinBlockStateId("minecraft:bedrock")
+	noiseStone = builtinBlockStateId("minecraft:stone")
+	noiseDirt = builtinBlockStateId("minecraft:dirt")
+	noiseGrass = builtinBlockStateId("minecraft:grass_block[snowy=false]")
+	noiseSand = builtinBlockStateId("minecraft:sand")
+	noiseWater = builtinBlockStateId("minecraft:water[level=0]")
+)
+
+// The highest block of water, like in vanilla worlds.
+const noiseSeaLevel = 62
+
+// Generates hills of grass with sandy seas between them, from a heightmap made of Perlin noise.
+// The same seed always gives the same terrain. Safe to use from multiple goroutines.
+type NoiseGenerator struct {
+	// A shuffle of 0 to 255, twice over so that it can be indexed past the end.
+	permutation [512]uint8
+	// Where each octave is sampled from, so that the noise is not 0 at the origin of every world.
+	offsets [noiseOctaves][2]float64
+}
+
+const noiseOctaves = 4
+
+// The blocks use the ids of 1.14, like the rest of the built-in worlds.
+func NewNoiseGenerator(seed int64) *NoiseGenerator {
+	generator := &NoiseGenerator {}
+
+	// The sequence of a seeded math/rand source is guaranteed not to change between releases of Go
+	random := rand.New(rand.NewSource(seed))
+	for i, value := range random.Perm(256) {
+		generator.permutation[i] = uint8(value)
+		generator.permutation[i + 256] = uint8(value)
+	}
+
+	for i := range generator.offsets {
+		generator.offsets[i] = [2]float64 { random.Float64() * 256, random.Float64() * 256 }
+	}
+
+	return generator
+}
+
+// The y of the highest block of the terrain, leaving out the water above it.
+func (generator *NoiseGenerator) terrainHeight(x int32, z int32) int {
+	// Several octaves, each with twice the detail and half the effect of the one before
+	value := 0.0
+	frequency := 1.0 / 128
+	amplitude := 1.0
+	for _, offset := range generator.offsets {
+		value += generator.noise(float64(x) * frequency + offset[0], float64(z) * frequency + offset[1]) * amplitude
+		frequency *= 2
+		amplitude /= 2
+	}
+
+	height := 64 + int(math.Floor(value * 24))
+	if height < 1 {
+		return 1
+	}
+	if height > ChunkSectionCount * 16 - 2 {
+		return ChunkSectionCount * 16 - 2
+	}
+
+	return height
+}
+
+// Perlin noise, between about -1 and 1, which is 0 at whole coordinates.
+func (generator *NoiseGenerator) noise(x float64, z float64) float64 {
+	cellX := math.Floor(x)
+	cellZ := math.Floor(z)
+	x -= cellX
+	z -= cellZ
+
+	p := &generator.permutation
+	i := int(cellX) & 255
+	j := int(cellZ) & 255
+
+	fade := func(t float64) float64 {
+		return t * t * t * (t * (t * 6 - 15) + 10)
+	}
+
+	// The dot product of one of eight directions with the offset from a corner
+	gradient := func(hash uint8, x float64, z float64) float64 {
+		switch hash & 7 {
+		case 0:
+			return x + z
+		case 1:
+			return x - z
+		case 2:
+			return -x + z
+		case 3:
+			return -x - z
+		case 4:
+			return x
+		case 5:
+			return -x
+		case 6:
+			return z
+		default:
+			return -z
+		}
+	}
+
+	lerp := func(t float64, a float64, b float64) float64 {
+		return a + t * (b - a)
+	}
+
+	u := fade(x)
+	v := fade(z)
+
+	return lerp(v,
+		lerp(u, gradient(p[int(p[i]) + j], x, z), gradient(p[int(p[i + 1]) + j], x - 1, z)),
+		lerp(u, gradient(p[int(p[i]) + j + 1], x, z - 1), gradient(p[int(p[i + 1]) + j + 1], x - 1, z - 1)),
+	)
+}
+
+func (generator *NoiseGenerator) ProvideChunk(chunkX int32, chunkZ int32) (*ChunkColumn, error) {
+	chunk := NewChunkColumn(chunkX, chunkZ)
+
+	for z := 0; z < 16; z++ {
+		for x := 0; x < 16; x++ {
+			height := generator.terrainHeight(chunkX * 16 + int32(x), chunkZ * 16 + int32(z))
+
+			chunk.SetBlock(x, 0, z, noiseBedrock)
+			for y := 1; y <= height; y++ {
+				block := noiseStone
+				switch {
+				case height < noiseSeaLevel && y > height - 3:
+					block = noiseSand
+				case y == height:
+					block = noiseGrass
+				case y > height - 4:
+					block = noiseDirt
+				}
+
+				chunk.SetBlock(x, y, z, block)
+			}
+
+			for y := height + 1; y <= noiseSeaLevel; y++ {
+				chunk.SetBlock(x, y, z, noiseWater)
+			}
+		}
+	}
+
+	return chunk, nil
+}
+
+// Above the water, where there is water.
+func (generator *NoiseGenerator) SurfaceHeight(x int32, z int32) int {
+	height := generator.terrainHeight(x, z)
+	if height < noiseSeaLevel {
+		height = noiseSeaLevel
+	}
+
+	return height + 1
+}
@@ -0,0 +1,118 @@
+package javaserver
+
+import "reflect"
+import "testing"
+import "github.com/davidcallanan/go-mcp/registry"
+
+func TestFlatGenerator(t *testing.T) {
+	iomap := []struct {
+		layers string
+		// The block at each y from 0, after which there is air
+		blocks []uint32
+	} {
+		{"minecraft:bedrock,2*minecraft:dirt,minecraft:grass_block", []uint32 {33, 10, 10, 9}},
+		{"bedrock, 3*stone", []uint32 {33, 1, 1, 1}},
+		{"minecraft:air,minecraft:oak_log[axis=x],minecraft:snow[layers=2]", []uint32 {0, 72, 3920}},
+	}
+
+	for i, mapping := range iomap {
+		generator, err := NewFlatGenerator(mapping.layers)
+		if err != nil {
+			t.Errorf("Error for mapping %d: %v", i, err)
+			continue
+		}
+
+		chunk, _ := generator.ProvideChunk(-3, 7)
+		for y := 0; y <= len(mapping.blocks); y++ {
+			expected := uint32(0)
+			if y < len(mapping.blocks) {
+				expected = mapping.blocks[y]
+			}
+
+			if block := chunk.Block(15, y, 3); block != expected {
+				t.Errorf("Block at y %d incorrect for mapping %d: %d", y, i, block)
+			}
+		}
+
+		if height := generator.SurfaceHeight(100, -100); height != len(mapping.blocks) {
+			t.Errorf("Surface height incorrect for mapping %d: %d", i, height)
+		}
+	}
+}
+
+func TestInvalidFlatLayers(t *testing.T) {
+	iemap := []struct {
+		layers string
+		err error
+	} {
+		{"minecraft:bedrock,0*minecraft:dirt", InvalidLayersError {}},
+		{"two*minecraft:dirt", InvalidLayersError {}},
+		{"200*minecraft:stone,100*minecraft:dirt", InvalidLayersError {}},
+		{"", registry.MalformedBlockStateError {}},
+		{"minecraft:bedrock,,minecraft:dirt", registry.MalformedBlockStateError {}},
+		{"minecraft:bedrock,minecraft:unknown_block", registry.UnknownEntryError {}},
+	}
+
+	for i, mapping := range iemap {
+		_, err := NewFlatGenerator(mapping.layers)
+		if reflect.TypeOf(err) != reflect.TypeOf(mapping.err) {
+			t.Errorf("Expected %T for mapping %d but instead got: %v", mapping.err, i, err)
+		}
+	}
+}
+
+func TestNoiseGenerator(t *testing.T) {
+	generator := NewNoiseGenerator(1234)
+
+	for _, position := range []chunkPosition { {0, 0}, {-5, 12}, {300, -40} } {
+		chunk, _ := generator.ProvideChunk(position.x, position.z)
+		again, _ := NewNoiseGenerator(1234).ProvideChunk(position.x, position.z)
+		if !reflect.DeepEqual(chunk, again) {
+			t.Errorf("Chunk %v not the same for the same seed", position)
+		}
+
+		for z := 0; z < 16; z++ {
+			for x := 0; x < 16; x++ {
+				height := generator.SurfaceHeight(position.x * 16 + int32(x), position.z * 16 + int32(z))
+				if chunk.Block(x, 0, z) != 33 || chunk.Block(x, height - 1, z) == 0 || chunk.Block(x, height, z) != 0 {
+					t.Fatalf("Column %d %d of chunk %v incorrect for surface height %d", x, z, position, height)
+				}
+			}
+		}
+	}
+
+	// Hills and seas, which differ between seeds
+	heights := make(map[int]bool)
+	differences := 0
+	other := NewNoiseGenerator(5678)
+	for x := int32(0); x < 1024; x += 16 {
+		height := generator.SurfaceHeight(x, x)
+		heights[height] = true
+
+		if other.SurfaceHeight(x, x) != height {
+			differences++
+		}
+	}
+
+	if len(heights) < 10 || !heights[noiseSeaLevel + 1] || differences == 0 {
+		t.Errorf("Terrain too flat: %v %d", heights, differences)
+	}
+}
+
+func TestWorldSpawnPosition(t *testing.T) {
+	iomap := []struct {
+		provider ChunkProvider
+		y float64
+	} {
+		{nil, 64},
+		{ChunkProviderFunc(func(x int32, z int32) (*ChunkColumn, error) { return nil, nil }), 64},
+		{NewNoiseGenerator(1234), float64(NewNoiseGenerator(1234).SurfaceHeight(0, 0))},
+	}
+
+	for i, mapping := range iomap {
+		world := &World { Provider: mapping.provider }
+		if position := world.SpawnPosition(); position != (EntityPosition { Y: mapping.y }) {
+			t.Errorf("Output incorrect for mapping %d: %v", i, position)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package javaserver
+
+import "sync"
+import "time"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+const DefaultKeepAliveInterval = 20 * time.Second
+
+// The same as the vanilla server.
+const DefaultKeepAliveTimeout = 30 * time.Second
+
+// Shared between the keep alive goroutine, which sends keep alives, and the receive goroutine, which handles the answers.
+type keepAliveState struct {
+	lock sync.Mutex
+	// Set once the player has joined, so that sending the join packets does not count towards the timeout.
+	isActive bool
+	isPending bool
+	payload int64
+	sentAt time.Time
+	latency time.Duration
+}
+
+func (conn *Connection) keepAliveLoop() {
+	interval := conn.config.KeepAliveInterval
+	if interval <= 0 {
+		interval = DefaultKeepAliveInterval
+	}
+
+	timeout := conn.config.KeepAliveTimeout
+	if timeout <= 0 {
+		timeout = DefaultKeepAliveTimeout
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Only one keep alive is pending at a time, so a single timer is enough
+	timeoutTimer := time.NewTimer(timeout)
+	timeoutTimer.Stop()
+	defer timeoutTimer.Stop()
+
+	for {
+		select {
+		case <-conn.done:
+			return
+		case now := <-ticker.C:
+			state := &conn.keepAlive
+			state.lock.Lock()
+
+			if !state.isActive || state.isPending {
+				state.lock.Unlock()
+				continue
+			}
+
+			payload := now.UnixNano()
+			state.isPending = true
+			state.payload = payload
+			state.sentAt = now
+			state.lock.Unlock()
+
+			// The timer may have expired unnoticed after the previous keep alive was answered
+			if !timeoutTimer.Stop() {
+				select {
+				case <-timeoutTimer.C:
+				default:
+				}
+			}
+			timeoutTimer.Reset(timeout)
+
+			conn.send(javaio.KeepAlive {
+				Payload: payload,
+			})
+		case <-timeoutTimer.C:
+			state := &conn.keepAlive
+			state.lock.Lock()
+			isPending := state.isPending
+			state.lock.Unlock()
+
+			if isPending {
+				println("Client did not answer keep alive.. closing connection")
+				conn.closeWithReason(LeaveReasonTimedOut)
+				return
+			}
+		}
+	}
+}
+
+// Called once the player has joined.
+func (conn *Connection) startKeepAlive() {
+	conn.keepAlive.lock.Lock()
+	conn.keepAlive.isActive = true
+	conn.keepAlive.lock.Unlock()
+}
+
+func (conn *Connection) processKeepAlive(data javaio.KeepAlive) {
+	state := &conn.keepAlive
+	state.lock.Lock()
+
+	if !state.isPending || data.Payload != state.payload {
+		state.lock.Unlock()
+		println("Unexpected keep alive from client.. closing connection")
+		conn.closeWithReason(LeaveReasonProtocolError)
+		return
+	}
+
+	rtt := time.Since(state.sentAt)
+	state.isPending = false
+
+	// Smoothed like the vanilla server, so that a single slow answer does not cause the ping to jump
+	if state.latency == 0 {
+		state.latency = rtt
+	} else {
+		state.latency = (state.latency * 3 + rtt) / 4
+	}
+
+	latency := state.latency
+	state.lock.Unlock()
+
+	if conn.eventHandlers.OnLatencyUpdate != nil {
+		conn.eventHandlers.OnLatencyUpdate(latency)
+	}
+}
+
+// The round trip time of keep alives, or zero until the client has answered one.
+func (conn *Connection) Latency() time.Duration {
+	conn.keepAlive.lock.Lock()
+	defer conn.keepAlive.lock.Unlock()
+	return conn.keepAlive.latency
+}
+
+// The latency in milliseconds, as shown in the tab list through PlayerInfoToAdd.Ping and UpdatePlayerLatency.
+func (conn *Connection) PingMillis() int32 {
+	return int32(conn.Latency() / time.Millisecond)
+}
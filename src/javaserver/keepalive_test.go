@@ -0,0 +1,109 @@
+package javaserver
+
+import "net"
+import "time"
+import "bufio"
+import "testing"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+// Plays the part of a logged in client, answering each keep alive with the payload returned by answer, if any.
+func answerKeepAlives(clientSide net.Conn, answer func(payload int64) int64) {
+	input := bufio.NewReader(clientSide)
+	output := bufio.NewWriter(clientSide)
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+
+	for {
+		packet, err := javaio.ParseClientboundPacketUncompressed(input, ctx, ctx.State)
+		if _, ok := err.(javaio.UnknownPacketError); ok {
+			// Login success shares its id with a play packet that is not parsed
+			continue
+		} else if err != nil {
+			return
+		}
+
+		if keepAlive, ok := packet.(javaio.KeepAlive); ok && answer != nil {
+			javaio.EmitServerboundPacketUncompressed(javaio.KeepAlive {
+				Payload: answer(keepAlive.Payload),
+			}, ctx, output)
+		}
+	}
+}
+
+var testKeepAliveConfig = Config {
+	CompressionThreshold: -1,
+	KeepAliveInterval: 20 * time.Millisecond,
+	KeepAliveTimeout: 200 * time.Millisecond,
+}
+
+func TestKeepAliveLatency(t *testing.T) {
+	reasons := make(chan LeaveReason, 1)
+
+	conn, clientSide := newTestPlayer(t, testKeepAliveConfig, EventHandlers {
+		OnPlayerLeave: func(reason LeaveReason) {
+			reasons <- reason
+		},
+	})
+	defer clientSide.Close()
+
+	go answerKeepAlives(clientSide, func(payload int64) int64 {
+		time.Sleep(5 * time.Millisecond)
+		return payload
+	})
+
+	// Stays connected for longer than the timeout while answering
+	time.Sleep(500 * time.Millisecond)
+
+	select {
+	case reason := <-reasons:
+		t.Fatalf("Player left unexpectedly: %s", reason)
+	default:
+	}
+
+	if latency := conn.Latency(); latency < 5 * time.Millisecond || latency > 200 * time.Millisecond {
+		t.Errorf("Latency incorrect: %s", latency)
+	}
+
+	if ping := conn.PingMillis(); ping < 5 {
+		t.Errorf("Ping incorrect: %d", ping)
+	}
+
+	conn.Close()
+	expectLeaveReason(t, reasons, LeaveReasonClosed)
+}
+
+func TestKeepAliveTimeout(t *testing.T) {
+	reasons := make(chan LeaveReason, 1)
+
+	conn, clientSide := newTestPlayer(t, testKeepAliveConfig, EventHandlers {
+		OnPlayerLeave: func(reason LeaveReason) {
+			reasons <- reason
+		},
+	})
+	defer clientSide.Close()
+
+	// Reads everything, but never answers
+	go answerKeepAlives(clientSide, nil)
+
+	expectLeaveReason(t, reasons, LeaveReasonTimedOut)
+
+	if conn.Latency() != 0 {
+		t.Errorf("Latency measured without an answer: %s", conn.Latency())
+	}
+}
+
+func TestKeepAliveWrongPayload(t *testing.T) {
+	reasons := make(chan LeaveReason, 1)
+
+	_, clientSide := newTestPlayer(t, testKeepAliveConfig, EventHandlers {
+		OnPlayerLeave: func(reason LeaveReason) {
+			reasons <- reason
+		},
+	})
+	defer clientSide.Close()
+
+	go answerKeepAlives(clientSide, func(payload int64) int64 {
+		return payload + 1
+	})
+
+	expectLeaveReason(t, reasons, LeaveReasonProtocolError)
+}
@@ -0,0 +1,94 @@
+package javaserver
+
+import "net"
+import "bufio"
+import "testing"
+import "github.com/davidcallanan/go-mcp/chat"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+// Reads packets until a disconnect packet arrives, and then expects the stream to end.
+func expectDisconnect(t *testing.T, clientSide net.Conn, ctx javaio.ClientContext) javaio.Disconnect {
+	input := bufio.NewReader(clientSide)
+
+	for {
+		packet, err := javaio.ParseClientboundPacketUncompressed(input, ctx, ctx.State)
+		if _, ok := err.(javaio.UnknownPacketError); ok {
+			continue
+		} else if err != nil {
+			t.Fatalf("Stream ended before disconnect packet: %v", err)
+		}
+
+		if disconnect, ok := packet.(javaio.Disconnect); ok {
+			if _, err := input.ReadByte(); err == nil {
+				t.Error("Stream did not end after disconnect packet")
+			}
+
+			return disconnect
+		}
+	}
+}
+
+func TestDenyJoin(t *testing.T) {
+	reason := chat.TextComponent { Text: "The server is full", Color: "red" }
+	left := false
+	serverSide, clientSide := net.Pipe()
+	defer clientSide.Close()
+
+	conn := NewConnectionWithConfig(serverSide, func() { serverSide.Close() }, EventHandlers {
+		OnPlayerJoinRequest: func(data PlayerJoinRequest) PlayerJoinResponse {
+			return PlayerJoinResponse { DenyReason: &reason }
+		},
+		OnPlayerLeave: func(reason LeaveReason) {
+			left = true
+		},
+	}, Config { CompressionThreshold: -1 })
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteVarInt(javaio.EncodePostNettyVersion(0x0286), data)
+		javaio.WriteString("localhost", data)
+		data.Write([]byte {0x63, 0xdd})
+		javaio.WriteVarInt(2, data)
+	})
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteString("Notch", data)
+	})
+
+	disconnect := expectDisconnect(t, clientSide, javaio.ClientContext { State: javaio.StateLogin, Protocol: 0x0286 })
+	if disconnect.Reason.Text != reason.Text || disconnect.Reason.Color != reason.Color {
+		t.Errorf("Reason incorrect: %#v", disconnect.Reason)
+	}
+
+	<-conn.Done()
+
+	if left {
+		t.Error("Player left without having joined")
+	}
+}
+
+func TestKick(t *testing.T) {
+	reasons := make(chan LeaveReason, 1)
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {
+		OnPlayerLeave: func(reason LeaveReason) {
+			reasons <- reason
+		},
+	})
+	defer clientSide.Close()
+
+	if err := conn.Kick(chat.Text("Kicked by an operator")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := conn.SpawnPlayer(PlayerToSpawn { EntityId: 1 })
+	if _, ok := err.(ConnectionClosedError); !ok {
+		t.Errorf("Expected ConnectionClosedError after kick but instead got: %v", err)
+	}
+
+	disconnect := expectDisconnect(t, clientSide, javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286 })
+	if disconnect.Reason.Text != "Kicked by an operator" {
+		t.Errorf("Reason incorrect: %#v", disconnect.Reason)
+	}
+
+	expectLeaveReason(t, reasons, LeaveReasonKicked)
+}
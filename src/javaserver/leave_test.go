@@ -0,0 +1,64 @@
+package javaserver
+
+import "net"
+import "time"
+import "testing"
+import "io/ioutil"
+
+// Joins as a player that reads everything it is sent, and returns the channel the leave reason is sent on.
+func newLeavingTestPlayer(t *testing.T) (*Connection, net.Conn, chan LeaveReason) {
+	reasons := make(chan LeaveReason, 1)
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {
+		OnPlayerLeave: func(reason LeaveReason) {
+			reasons <- reason
+		},
+	})
+
+	go func() {
+		ioutil.ReadAll(clientSide)
+	}()
+
+	return conn, clientSide, reasons
+}
+
+func expectLeaveReason(t *testing.T, reasons chan LeaveReason, expected LeaveReason) {
+	select {
+	case reason := <-reasons:
+		if reason != expected {
+			t.Errorf("Expected leave reason %s but instead got %s", expected, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the player to leave")
+	}
+}
+
+func TestPlayerLeaveOnDisconnect(t *testing.T) {
+	conn, clientSide, reasons := newLeavingTestPlayer(t)
+
+	clientSide.Close()
+	expectLeaveReason(t, reasons, LeaveReasonDisconnected)
+
+	select {
+	case <-conn.Done():
+	default:
+		t.Error("Connection is not closed after the client disconnected")
+	}
+}
+
+func TestPlayerLeaveOnClose(t *testing.T) {
+	conn, clientSide, reasons := newLeavingTestPlayer(t)
+	defer clientSide.Close()
+
+	conn.Close()
+	expectLeaveReason(t, reasons, LeaveReasonClosed)
+}
+
+func TestPlayerLeaveOnMalformedPacket(t *testing.T) {
+	_, clientSide, reasons := newLeavingTestPlayer(t)
+	defer clientSide.Close()
+
+	// A packet length that never ends
+	clientSide.Write([]byte {0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	expectLeaveReason(t, reasons, LeaveReasonProtocolError)
+}
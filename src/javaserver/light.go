@@ -0,0 +1,242 @@
+package javaserver
+
+import "fmt"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/davidcallanan/go-mcp/registry"
+
+// Describes how a block state interacts with light.
+// Light passing into a block is reduced by its opacity, or by 1 if the opacity is lower, so an opacity of 15 blocks light completely.
+// Light spreads outwards from blocks with a non-zero emission.
+type BlockLightProperties func(block uint32) (opacity uint8, emission uint8)
+
+type blockLightRange struct {
+	first uint32
+	last uint32
+	opacity uint8
+	emission uint8
+}
+
+// Blocks that are not full opaque blocks, with the ids of all their states.
+var defaultBlockLightRanges = func() []blockLightRange {
+	blocks := []struct {
+		name string
+		opacity uint8
+		emission uint8
+	} {
+		{"minecraft:air", 0, 0},
+		{"minecraft:oak_sapling", 0, 0},
+		{"minecraft:spruce_sapling", 0, 0},
+		{"minecraft:birch_sapling", 0, 0},
+		{"minecraft:jungle_sapling", 0, 0},
+		{"minecraft:acacia_sapling", 0, 0},
+		{"minecraft:dark_oak_sapling", 0, 0},
+		{"minecraft:water", 1, 0},
+		{"minecraft:lava", 1, 15},
+		{"minecraft:oak_leaves", 1, 0},
+		{"minecraft:spruce_leaves", 1, 0},
+		{"minecraft:birch_leaves", 1, 0},
+		{"minecraft:jungle_leaves", 1, 0},
+		{"minecraft:acacia_leaves", 1, 0},
+		{"minecraft:dark_oak_leaves", 1, 0},
+		{"minecraft:glass", 0, 0},
+	}
+
+	ranges := make([]blockLightRange, len(blocks))
+	for i, block := range blocks {
+		first, last, ok := registry.Blocks.BlockStateIds(block.name, builtinBlockProtocol)
+		if !ok {
+			panic(fmt.Sprintf("Internal package bug: no block %s", block.name))
+		}
+
+		ranges[i] = blockLightRange { first, last, block.opacity, block.emission }
+	}
+
+	return ranges
+}()
+
+// Properties of the block states used by the built-in worlds, which have the ids of 1.14.
+// Any other block is treated as a full opaque block that does not emit light.
+func DefaultBlockLightProperties(block uint32) (opacity uint8, emission uint8) {
+	for _, blocks := range defaultBlockLightRanges {
+		if block >= blocks.first && block <= blocks.last {
+			return blocks.opacity, blocks.emission
+		}
+	}
+
+	return 15, 0
+}
+
+// Light levels of a chunk, ready to be sent with an Update Light packet.
+type ChunkLight struct {
+	SkyLight [javaio.LightSectionCount][]byte
+	BlockLight [javaio.LightSectionCount][]byte
+}
+
+// Computes the light of a chunk from the same sections that are passed to ChunkData.
+// Light from neighbouring chunks is not taken into account, so light does not spread across chunk borders.
+func ComputeChunkLight(sections [][]uint32, properties BlockLightProperties) ChunkLight {
+	const columnCount = 16 * 16
+	const blockCount = columnCount * 256
+
+	if properties == nil {
+		properties = DefaultBlockLightProperties
+	}
+
+	// Indexed in the same order as chunk sections, with y varying slowest
+	opacity := make([]uint8, blockCount)
+	skyLight := make([]uint8, blockCount)
+	blockLight := make([]uint8, blockCount)
+	var skyQueue []int
+	var blockQueue []int
+
+	airOpacity, airEmission := properties(0)
+
+	for i := range opacity {
+		sectionY := i / 4096
+		block := uint32(0)
+
+		if sectionY < len(sections) && len(sections[sectionY]) == 4096 {
+			block = sections[sectionY][i % 4096]
+		}
+
+		if block == 0 {
+			opacity[i] = airOpacity
+			blockLight[i] = airEmission
+		} else {
+			opacity[i], blockLight[i] = properties(block)
+		}
+
+		if blockLight[i] > 0 {
+			blockQueue = append(blockQueue, i)
+		}
+	}
+
+	// Sky light shines straight down without losing strength until it reaches a block
+	for column := 0; column < columnCount; column++ {
+		light := uint8(15)
+
+		for i := blockCount - columnCount + column; i >= 0; i -= columnCount {
+			if opacity[i] >= light {
+				light = 0
+			} else {
+				light -= opacity[i]
+			}
+
+			skyLight[i] = light
+		}
+	}
+
+	// Most blocks are surrounded by blocks with as much sky light, so only the few at the edges of shadows are spread from
+	for i := range skyLight {
+		if skyLight[i] > 1 && canSpreadLight(skyLight, opacity, i) {
+			skyQueue = append(skyQueue, i)
+		}
+	}
+
+	spreadLight(skyLight, opacity, skyQueue)
+	spreadLight(blockLight, opacity, blockQueue)
+
+	var result ChunkLight
+
+	for sectionY := 0; sectionY < 16; sectionY++ {
+		result.SkyLight[sectionY + 1] = packLight(skyLight[sectionY * 4096:(sectionY + 1) * 4096])
+		result.BlockLight[sectionY + 1] = packLight(blockLight[sectionY * 4096:(sectionY + 1) * 4096])
+	}
+
+	// Nothing above the world blocks the sky
+	fullSkyLight := make([]byte, javaio.LightArrayLength)
+	for i := range fullSkyLight {
+		fullSkyLight[i] = 0xff
+	}
+	result.SkyLight[javaio.LightSectionCount - 1] = fullSkyLight
+
+	return result
+}
+
+// The neighbours of a block within the chunk, or -1 where the chunk ends.
+func lightNeighbours(i int) [6]int {
+	x := i % 16
+	z := i / 16 % 16
+	y := i / 256
+
+	neighbours := [6]int {-1, -1, -1, -1, -1, -1}
+	if x > 0 {
+		neighbours[0] = i - 1
+	}
+	if x < 15 {
+		neighbours[1] = i + 1
+	}
+	if z > 0 {
+		neighbours[2] = i - 16
+	}
+	if z < 15 {
+		neighbours[3] = i + 16
+	}
+	if y > 0 {
+		neighbours[4] = i - 256
+	}
+	if y < 255 {
+		neighbours[5] = i + 256
+	}
+
+	return neighbours
+}
+
+// Whether the light of a block would raise the light of any of its neighbours.
+func canSpreadLight(light []uint8, opacity []uint8, i int) bool {
+	for _, neighbour := range lightNeighbours(i) {
+		if neighbour < 0 {
+			continue
+		}
+
+		reduction := opacity[neighbour]
+		if reduction < 1 {
+			reduction = 1
+		}
+
+		if light[i] > reduction && light[i] - reduction > light[neighbour] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Spreads light from the queued blocks to their neighbours within the chunk, losing strength on the way.
+func spreadLight(light []uint8, opacity []uint8, queue []int) {
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+
+		for _, neighbour := range lightNeighbours(i) {
+			if neighbour < 0 {
+				continue
+			}
+
+			reduction := opacity[neighbour]
+			if reduction < 1 {
+				reduction = 1
+			}
+
+			if light[i] <= reduction {
+				continue
+			}
+
+			if spread := light[i] - reduction; spread > light[neighbour] {
+				light[neighbour] = spread
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+}
+
+// Packs light levels into the nibble array format, with the first level in the low bits of the first byte.
+func packLight(levels []uint8) []byte {
+	result := make([]byte, javaio.LightArrayLength)
+
+	for i, level := range levels {
+		result[i / 2] |= (level & 0xf) << uint(i % 2 * 4)
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+package javaserver
+
+import "testing"
+
+// Reads a light level from a packed nibble array of the world section containing y.
+func lightAt(sections [18][]byte, x int, y int, z int) uint8 {
+	i := y % 16 * 256 + z * 16 + x
+	return sections[y / 16 + 1][i / 2] >> uint(i % 2 * 4) & 0xf
+}
+
+func flatSections(top uint32) [][]uint32 {
+	ground := make([]uint32, 4096)
+	for i := range ground {
+		ground[i] = 1
+	}
+
+	surface := make([]uint32, 4096)
+	for i := 0; i < 256; i++ {
+		surface[i] = top
+	}
+
+	// Stone from y=0 to y=15, with one layer of the top block at y=16
+	return [][]uint32 {ground, surface}
+}
+
+func TestSkyLight(t *testing.T) {
+	light := ComputeChunkLight(flatSections(1), nil)
+
+	iomap := []struct {
+		x, y, z int
+		output uint8
+	} {
+		{0, 17, 0, 15},
+		{7, 200, 9, 15},
+		{15, 255, 15, 15},
+		{0, 16, 0, 0},
+		{8, 3, 8, 0},
+	}
+
+	for i, mapping := range iomap {
+		if output := lightAt(light.SkyLight, mapping.x, mapping.y, mapping.z); output != mapping.output {
+			t.Errorf("Output incorrect for mapping %d: %d", i, output)
+		}
+	}
+
+	if light.SkyLight[17][0] != 0xff || light.SkyLight[0] != nil {
+		t.Errorf("Sky light outside of the world incorrect")
+	}
+}
+
+func TestSkyLightThroughWater(t *testing.T) {
+	// Water from y=16 to y=19 reduces sky light by one level per block
+	sections := flatSections(34)
+	for i := 256; i < 4 * 256; i++ {
+		sections[1][i] = 34
+	}
+
+	light := ComputeChunkLight(sections, nil)
+
+	for y := 16; y <= 19; y++ {
+		if output := lightAt(light.SkyLight, 4, y, 4); output != uint8(y - 5) {
+			t.Errorf("Output incorrect at y=%d: %d", y, output)
+		}
+	}
+}
+
+func TestBlockLight(t *testing.T) {
+	sections := flatSections(1)
+
+	// Lava at x=8 z=8 on top of the surface
+	sections[1][256 + 8 * 16 + 8] = 50
+
+	light := ComputeChunkLight(sections, nil)
+
+	iomap := []struct {
+		x, y, z int
+		output uint8
+	} {
+		{8, 17, 8, 15},
+		{9, 17, 8, 14},
+		{8, 18, 8, 14},
+		{10, 18, 11, 9},
+		{8, 16, 8, 0},
+	}
+
+	for i, mapping := range iomap {
+		if output := lightAt(light.BlockLight, mapping.x, mapping.y, mapping.z); output != mapping.output {
+			t.Errorf("Output incorrect for mapping %d: %d", i, output)
+		}
+	}
+}
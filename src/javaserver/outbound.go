@@ -0,0 +1,206 @@
+package javaserver
+
+import "fmt"
+import "time"
+import "bufio"
+import "bytes"
+import "sync/atomic"
+import "github.com/davidcallanan/go-mcp/chat"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+// Used when Config.OutboundQueueSize is not set.
+// Large enough to hold every packet sent while a player logs in.
+const DefaultOutboundQueueSize = 1024
+
+// Decides what happens to packets sent to a client that does not read them as fast as they are sent.
+type SlowClientPolicy int
+const (
+	// Same as SlowClientDisconnect.
+	SlowClientDefault = iota
+	// Closes the connection once the outbound queue is full.
+	SlowClientDisconnect = iota
+	// Discards packets that do not fit into the outbound queue.
+	// The client may end up out of sync with the server, so this suits packets that are sent again soon anyway.
+	SlowClientDrop = iota
+)
+
+// Returned when a packet is sent on a connection that has been closed.
+type ConnectionClosedError struct {
+	details string
+}
+
+func (err ConnectionClosedError) Error() string {
+	return fmt.Sprintf("Connection closed: %s", err.details)
+}
+
+// Returned when a packet does not fit into the outbound queue of a slow client.
+type OutboundQueueFullError struct {
+	details string
+}
+
+func (err OutboundQueueFullError) Error() string {
+	return fmt.Sprintf("Outbound queue full: %s", err.details)
+}
+
+// How long a kicked client is given to receive the disconnect packet before the connection is closed regardless.
+const kickTimeout = 5 * time.Second
+
+// A fully framed packet waiting to be written by the writer goroutine.
+type outboundPacket struct {
+	data []byte
+	// The stream at the time the packet was sent, as encryption may be enabled for later packets.
+	output *bufio.Writer
+	// Set for the disconnect packet of a kick, which is the last packet written.
+	closeAfter bool
+}
+
+// Encodes the packet straight away, so that encoding errors are returned to the caller,
+// and leaves the writing to the writer goroutine.
+// Closes the connection when the packet cannot be sent, unless it is dropped because of the slow client policy.
+func (conn *Connection) send(packet interface{}) error {
+	return conn.sendPacket(packet, false)
+}
+
+// Sends the packet and closes the connection once it has been written.
+// No more packets can be sent afterwards.
+func (conn *Connection) sendAndClose(packet interface{}) error {
+	return conn.sendPacket(packet, true)
+}
+
+func (conn *Connection) sendPacket(packet interface{}, closeAfter bool) (err error) {
+	conn.sendLock.Lock()
+	defer conn.sendLock.Unlock()
+
+	if conn.isClosed() || atomic.LoadInt32(&conn.isKicked) != 0 {
+		err = ConnectionClosedError { fmt.Sprintf("Cannot send %T", packet) }
+		return
+	}
+
+	if closeAfter {
+		// From now on the connection is closing, even if the packet never arrives
+		atomic.StoreInt32(&conn.isKicked, 1)
+		time.AfterFunc(kickTimeout, func() {
+			conn.closeWithReason(LeaveReasonKicked)
+		})
+	}
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+
+	if conn.ctx.CompressionThreshold >= 0 {
+		err = javaio.EmitClientboundPacketCompressed(packet, conn.ctx, writer)
+	} else {
+		err = javaio.EmitClientboundPacketUncompressed(packet, conn.ctx, writer)
+	}
+
+	if err != nil {
+		// Typically an UnknownPacketError, as the client version is not fully supported
+		println("Failed to send packet (" + err.Error() + ").. closing connection")
+		conn.closeWithReason(LeaveReasonProtocolError)
+		return
+	}
+
+	select {
+	case conn.outbound <- outboundPacket { data: buf.Bytes(), output: conn.outputStream, closeAfter: closeAfter }:
+		return
+	default:
+	}
+
+	if closeAfter {
+		conn.closeWithReason(LeaveReasonKicked)
+		err = OutboundQueueFullError { fmt.Sprintf("Closed without sending %T", packet) }
+		return
+	}
+
+	if conn.config.SlowClientPolicy == SlowClientDrop {
+		err = OutboundQueueFullError { fmt.Sprintf("Dropped %T", packet) }
+		return
+	}
+
+	println("Client is not keeping up with sent packets.. closing connection")
+	conn.closeWithReason(LeaveReasonSlowClient)
+	err = OutboundQueueFullError { fmt.Sprintf("Disconnected while sending %T", packet) }
+	return
+}
+
+// The only goroutine that writes to the stream.
+func (conn *Connection) writeLoop() {
+	for {
+		select {
+		case <-conn.done:
+			return
+		case packet := <-conn.outbound:
+			_, err := packet.output.Write(packet.data)
+
+			// Packets sent in quick succession are flushed together
+			if err == nil && (len(conn.outbound) == 0 || packet.closeAfter) {
+				err = packet.output.Flush()
+			}
+
+			if err != nil {
+				conn.closeWithReason(streamErrorReason(err))
+				return
+			}
+
+			if packet.closeAfter {
+				conn.closeWithReason(LeaveReasonKicked)
+				return
+			}
+		}
+	}
+}
+
+// Closes the connection and stops its goroutines. Packets that have not been written yet are discarded.
+// Use Kick instead to show the client why it was disconnected.
+// Safe to call more than once, and from any goroutine.
+func (conn *Connection) Close() {
+	conn.closeWithReason(LeaveReasonClosed)
+}
+
+// Only the first reason is kept when the connection is closed more than once.
+// Once a client has been kicked, the reason is always LeaveReasonKicked, even if the client ends the connection first.
+func (conn *Connection) closeWithReason(reason LeaveReason) {
+	conn.closeOnce.Do(func() {
+		if atomic.LoadInt32(&conn.isKicked) != 0 {
+			reason = LeaveReasonKicked
+		}
+
+		conn.closeReason = reason
+		close(conn.done)
+		conn.endStream()
+	})
+}
+
+// Disconnects the client with a message.
+// Only clients that are logging in or playing can be shown a message, other connections are closed straight away.
+func (conn *Connection) Kick(reason chat.TextComponent) error {
+	switch conn.currentState() {
+	case javaio.StateLogin, javaio.StatePlay:
+		return conn.sendAndClose(javaio.Disconnect {
+			Reason: reason,
+		})
+	default:
+		atomic.StoreInt32(&conn.isKicked, 1)
+		conn.closeWithReason(LeaveReasonKicked)
+		return nil
+	}
+}
+
+// Closed once the connection has been closed, either by the server or because of a problem with the client.
+func (conn *Connection) Done() <-chan struct{} {
+	return conn.done
+}
+
+// Blocks until the goroutines of the connection have exited, which happens soon after it is closed.
+func (conn *Connection) wait() {
+	conn.goroutines.Wait()
+}
+
+func (conn *Connection) isClosed() bool {
+	select {
+	case <-conn.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+package javaserver
+
+import "net"
+import "sync"
+import "time"
+import "bufio"
+import "testing"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/google/uuid"
+
+// Logs in as a 1.15 client over a pipe, and returns the client side once the server has handled the join.
+// Nothing is read from the client side, so packets pile up in the outbound queue.
+func newTestPlayer(t *testing.T, config Config, eventHandlers EventHandlers) (*Connection, net.Conn) {
+	joined := make(chan struct{})
+	serverSide, clientSide := net.Pipe()
+
+	if eventHandlers.OnPlayerJoinRequest == nil {
+		eventHandlers.OnPlayerJoinRequest = func(data PlayerJoinRequest) PlayerJoinResponse {
+			return PlayerJoinResponse { Uuid: uuid.New() }
+		}
+	}
+
+	onPlayerJoin := eventHandlers.OnPlayerJoin
+	eventHandlers.OnPlayerJoin = func() {
+		if onPlayerJoin != nil {
+			onPlayerJoin()
+		}
+		close(joined)
+	}
+
+	conn := NewConnectionWithConfig(serverSide, func() { serverSide.Close() }, eventHandlers, config)
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteVarInt(javaio.EncodePostNettyVersion(0x0286), data)
+		javaio.WriteString("localhost", data)
+		data.Write([]byte {0x63, 0xdd})
+		javaio.WriteVarInt(2, data)
+	})
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteString("Notch", data)
+	})
+
+	select {
+	case <-joined:
+	case <-conn.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the player to join")
+	}
+
+	return conn, clientSide
+}
+
+func TestConcurrentSend(t *testing.T) {
+	const senders = 8
+	const packetsPerSender = 50
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: 64 }, EventHandlers {})
+	defer conn.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(entityId int32) {
+			defer wg.Done()
+			for j := 0; j < packetsPerSender; j++ {
+				conn.TranslateEntity(EntityTranslation { EntityId: entityId, DeltaX: 1 })
+				conn.SetEntityVelocity(EntityVelocity { EntityId: entityId, Y: 1 })
+			}
+		}(int32(i))
+	}
+
+	// Every packet must arrive whole and in the order each goroutine sent it
+	input := bufio.NewReader(clientSide)
+	ctx := javaio.ClientContext { State: javaio.StateLogin, Protocol: 0x0286, CompressionThreshold: -1 }
+	counts := make(map[int32]int)
+	received := 0
+
+	for received < senders * packetsPerSender * 2 {
+		var packet interface{}
+		var err error
+
+		if ctx.CompressionThreshold >= 0 {
+			packet, err = javaio.ParseClientboundPacketCompressed(input, ctx, ctx.State)
+		} else {
+			packet, err = javaio.ParseClientboundPacketUncompressed(input, ctx, ctx.State)
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to parse packet after %d entity packets: %v", received, err)
+		}
+
+		switch packet := packet.(type) {
+		case javaio.SetCompression:
+			ctx.CompressionThreshold = packet.Threshold
+		case javaio.LoginSuccess:
+			ctx.State = javaio.StatePlay
+		case javaio.Packet_EntityTranslate:
+			if counts[packet.EntityId] % 2 != 0 {
+				t.Fatalf("Packets of entity %d out of order", packet.EntityId)
+			}
+			counts[packet.EntityId]++
+			received++
+		case javaio.Packet_EntityVelocity:
+			if counts[packet.EntityId] % 2 != 1 {
+				t.Fatalf("Packets of entity %d out of order", packet.EntityId)
+			}
+			counts[packet.EntityId]++
+			received++
+		}
+	}
+
+	wg.Wait()
+}
+
+func TestSlowClientDisconnect(t *testing.T) {
+	conn, _ := newTestPlayer(t, Config { CompressionThreshold: -1, OutboundQueueSize: 4 }, EventHandlers {})
+
+	select {
+	case <-conn.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Slow client was not disconnected")
+	}
+
+	err := conn.SpawnPlayer(PlayerToSpawn { EntityId: 1 })
+	if _, ok := err.(ConnectionClosedError); !ok {
+		t.Errorf("Expected ConnectionClosedError but instead got: %v", err)
+	}
+}
+
+func TestSlowClientDrop(t *testing.T) {
+	// Large enough for the packets sent while logging in, but not for many more
+	conn, clientSide := newTestPlayer(t, Config {
+		CompressionThreshold: -1,
+		OutboundQueueSize: 128,
+		SlowClientPolicy: SlowClientDrop,
+	}, EventHandlers {})
+	defer conn.Close()
+
+	var err error
+	for i := 0; i < 128 && err == nil; i++ {
+		err = conn.SpawnPlayer(PlayerToSpawn { EntityId: int32(i) })
+	}
+
+	if _, ok := err.(OutboundQueueFullError); !ok {
+		t.Errorf("Expected OutboundQueueFullError but instead got: %v", err)
+	}
+
+	if conn.isClosed() {
+		t.Fatal("Slow client was disconnected")
+	}
+
+	// The packets that fit into the queue are still delivered
+	input := bufio.NewReader(clientSide)
+	readTestPacket(t, input, 0x02)
+}
+
+func TestClose(t *testing.T) {
+	serverSide, clientSide := net.Pipe()
+	defer clientSide.Close()
+
+	conn := NewConnection(serverSide, func() { serverSide.Close() }, EventHandlers {})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-conn.Done():
+	default:
+		t.Fatal("Done is not closed after Close")
+	}
+
+	if _, err := clientSide.Write([]byte {0x00}); err == nil {
+		t.Error("Stream is still open after Close")
+	}
+}
@@ -0,0 +1,74 @@
+package javaserver
+
+import "time"
+import "bufio"
+import "reflect"
+import "testing"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+func TestPlayEvents(t *testing.T) {
+	events := make(chan interface{}, 8)
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {
+		OnTeleportConfirm: func(teleportId int32) {
+			events <- teleportId
+		},
+		OnClientSettings: func(settings ClientSettings) {
+			events <- settings
+		},
+		OnEntityAction: func(data EntityAction) {
+			events <- data
+		},
+		OnPlayerAbilities: func(data PlayerAbilities) {
+			events <- data
+		},
+		OnHeldItemChange: func(slot int) {
+			events <- slot
+		},
+	})
+	defer conn.Close()
+
+	iomap := []struct {
+		packet interface{}
+		event interface{}
+	} {
+		{javaio.TeleportConfirm { TeleportId: 1 }, int32(1)},
+		{javaio.ClientSettings {
+			Locale: "en_us",
+			ViewDistance: 8,
+			ChatMode: javaio.ChatModeEnabled,
+			ChatColors: true,
+			DisplayedSkinParts: 0x7f,
+			MainHand: javaio.HandRight,
+		}, ClientSettings {
+			Locale: "en_us",
+			ViewDistance: 8,
+			ChatMode: javaio.ChatModeEnabled,
+			ChatColors: true,
+			DisplayedSkinParts: 0x7f,
+			MainHand: javaio.HandRight,
+		}},
+		{javaio.EntityAction { EntityId: 1, Action: javaio.PlayerActionStartSneaking }, EntityAction { Action: javaio.PlayerActionStartSneaking }},
+		{javaio.PlayerAbilitiesSb { IsFlying: true, AllowFlying: true, FlyingSpeed: 0.05, WalkingSpeed: 0.1 }, PlayerAbilities { IsFlying: true }},
+		{javaio.HeldItemChange { Slot: 4 }, 4},
+	}
+
+	output := bufio.NewWriter(clientSide)
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+
+	for i, mapping := range iomap {
+		err := javaio.EmitServerboundPacketUncompressed(mapping.packet, ctx, output)
+		if err != nil {
+			t.Fatalf("Failed to send mapping %d: %v", i, err)
+		}
+
+		select {
+		case event := <-events:
+			if !reflect.DeepEqual(event, mapping.event) {
+				t.Errorf("Event incorrect for mapping %d: %#v", i, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for event of mapping %d", i)
+		}
+	}
+}
@@ -0,0 +1,557 @@
+package javaserver
+
+import "os"
+import "fmt"
+import "net"
+import "sync"
+import "time"
+import "context"
+import "strings"
+import "path/filepath"
+import "github.com/davidcallanan/go-mcp/anvil"
+import "github.com/davidcallanan/go-mcp/chat"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/google/uuid"
+
+// Accepts connections and keeps track of the players that are online.
+// Use NewConnection directly instead for transports other than a net.Listener.
+type Server struct {
+	// Used for every connection, usually DefaultConfig with some changes.
+	Config Config
+	// Creates the event handlers of each accepted connection.
+	// Players are added to the tab list of everyone online as they join, and removed again as they leave, before the handlers are called.
+	Handlers func(conn *Connection) EventHandlers
+	// Shown to players that are online when the server shuts down. Defaults to the vanilla message when empty.
+	ShutdownMessage chat.TextComponent
+	// Every player that joins is added, moved as they move, and removed again when they leave.
+	Entities EntityRegistry
+	// The world players join, unless OnPlayerJoinRequest chooses another.
+	World World
+	// Where the position, rotation, gamemode and inventory of each player are kept between sessions, such as "world/playerdata".
+	// Players rejoin where they left the game, unless OnPlayerJoinRequest places them itself.
+	// The data of a player is saved as they leave, and nothing is kept when empty.
+	// Inventories are kept as they were, as they are not sent to players yet.
+	PlayerDataDirectory string
+	// How often Save is called while the server is serving. Nothing is saved periodically when zero.
+	AutosaveInterval time.Duration
+	// Saves the World once every connection has ended as the server shuts down.
+	SaveOnShutdown bool
+	// Sent to every player that joins. Chat messages starting with a slash are run as commands
+	// instead of being passed to OnChatMessage, and tab completion uses them unless OnTabComplete is set.
+	Commands CommandDispatcher
+
+	lock sync.Mutex
+	listeners map[net.Listener]struct{}
+	connections map[*Connection]struct{}
+	players map[uuid.UUID]*Connection
+	// The data each player joined with, or nil for players that had not played before.
+	playerData map[*Connection]*anvil.PlayerData
+	isShutdown bool
+	// Closed by Shutdown, once autosaving has started.
+	stopAutosave chan struct{}
+	// Counts connections until their goroutines have exited, and the autosave goroutine.
+	goroutines sync.WaitGroup
+}
+
+// Returned by Serve and ListenAndServe once Shutdown has been called.
+type ServerClosedError struct {
+	details string
+}
+
+func (err ServerClosedError) Error() string {
+	return fmt.Sprintf("Server closed: %s", err.details)
+}
+
+func (server *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(listener)
+}
+
+// Accepts connections until the listener fails or the server is shut down, and closes the listener when returning.
+func (server *Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+
+	server.lock.Lock()
+	if server.isShutdown {
+		server.lock.Unlock()
+		return ServerClosedError { "Cannot serve after shutdown" }
+	}
+	if server.listeners == nil {
+		server.listeners = make(map[net.Listener]struct{})
+	}
+	server.listeners[listener] = struct{}{}
+	server.startAutosave()
+	server.lock.Unlock()
+
+	defer func() {
+		server.lock.Lock()
+		delete(server.listeners, listener)
+		server.lock.Unlock()
+	}()
+
+	retryDelay := time.Duration(0)
+
+	for {
+		stream, err := listener.Accept()
+		if err != nil {
+			server.lock.Lock()
+			isShutdown := server.isShutdown
+			server.lock.Unlock()
+
+			if isShutdown {
+				return ServerClosedError { "Listener closed by shutdown" }
+			}
+
+			// Such as running out of file descriptors, which may resolve itself
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				if retryDelay == 0 {
+					retryDelay = 5 * time.Millisecond
+				} else if retryDelay < time.Second {
+					retryDelay *= 2
+				}
+
+				time.Sleep(retryDelay)
+				continue
+			}
+
+			return err
+		}
+
+		retryDelay = 0
+		server.serveConnection(stream)
+	}
+}
+
+func (server *Server) serveConnection(stream net.Conn) {
+	config := server.Config
+	config.World = &server.World
+	conn := newConnection(stream, func() { stream.Close() }, config)
+
+	var handlers EventHandlers
+	if server.Handlers != nil {
+		handlers = server.Handlers(conn)
+	}
+
+	onPlayerJoinRequest := handlers.OnPlayerJoinRequest
+	if onPlayerJoinRequest != nil {
+		handlers.OnPlayerJoinRequest = func(data PlayerJoinRequest) PlayerJoinResponse {
+			response := onPlayerJoinRequest(data)
+			if response.PreventResponse || response.DenyReason != nil {
+				return response
+			}
+
+			if response.EntityId == 0 {
+				response.EntityId = server.Entities.AllocateId()
+			}
+			if response.World == nil {
+				response.World = &server.World
+			}
+			server.loadPlayerData(conn, &response)
+			return response
+		}
+	}
+
+	onPlayerJoin := handlers.OnPlayerJoin
+	handlers.OnPlayerJoin = func() {
+		server.addPlayer(conn)
+		// Before the player is spawned, as clients only spawn players that are in their tab list
+		server.addToTabList(conn)
+		if onPlayerJoin != nil {
+			onPlayerJoin()
+		}
+
+		server.Entities.AddPlayer(conn, conn.joinPosition)
+		server.Commands.SendTo(conn)
+	}
+
+	onChatMessage := handlers.OnChatMessage
+	handlers.OnChatMessage = func(message string) {
+		if !strings.HasPrefix(message, "/") {
+			if onChatMessage != nil {
+				onChatMessage(message)
+			}
+			return
+		}
+
+		err := server.Commands.Execute(conn, message)
+		if err != nil {
+			conn.SendMessage(chat.TextComponent { Text: err.Error(), Color: "red" }, javaio.ChatPositionSystem)
+		}
+	}
+
+	if handlers.OnTabComplete == nil {
+		handlers.OnTabComplete = func(text string) TabCompleteResponse {
+			return server.Commands.Suggest(conn, text)
+		}
+	}
+
+	onLatencyUpdate := handlers.OnLatencyUpdate
+	handlers.OnLatencyUpdate = func(latency time.Duration) {
+		update := []PlayerLatencyToUpdate {
+			{ Uuid: conn.Uuid(), Ping: conn.PingMillis() },
+		}
+		server.Broadcast(func(other *Connection) {
+			other.UpdatePlayerLatency(update)
+		})
+
+		if onLatencyUpdate != nil {
+			onLatencyUpdate(latency)
+		}
+	}
+
+	onPlayerMove := handlers.OnPlayerMove
+	handlers.OnPlayerMove = func(data PlayerMove) {
+		if entity := server.Entities.PlayerEntity(conn); entity != nil {
+			previous := entity.Position()
+			if position := data.Apply(previous); position != previous {
+				entity.Move(position)
+			}
+		}
+
+		if onPlayerMove != nil {
+			onPlayerMove(data)
+		}
+	}
+
+	onPlayerLeave := handlers.OnPlayerLeave
+	handlers.OnPlayerLeave = func(reason LeaveReason) {
+		// Before the entity is removed, which holds where the player left the game
+		err := server.savePlayerData(conn)
+		if err != nil {
+			println("Failed to save player data (" + err.Error() + ")")
+		}
+
+		if entity := server.Entities.PlayerEntity(conn); entity != nil {
+			entity.Remove()
+		}
+
+		// A player replaced by a newer connection keeps their place in the tab list
+		if server.removePlayer(conn) {
+			server.Broadcast(func(other *Connection) {
+				other.RemovePlayerInfo([]uuid.UUID { conn.Uuid() })
+			})
+		}
+
+		if onPlayerLeave != nil {
+			onPlayerLeave(reason)
+		}
+	}
+
+	conn.eventHandlers = handlers
+
+	server.lock.Lock()
+	if server.isShutdown {
+		server.lock.Unlock()
+		stream.Close()
+		return
+	}
+	if server.connections == nil {
+		server.connections = make(map[*Connection]struct{})
+	}
+	server.connections[conn] = struct{}{}
+	server.goroutines.Add(1)
+	server.lock.Unlock()
+
+	conn.start()
+
+	go func() {
+		defer server.goroutines.Done()
+		conn.wait()
+
+		server.lock.Lock()
+		delete(server.connections, conn)
+		// Loaded for players that did not manage to join as well
+		delete(server.playerData, conn)
+		server.lock.Unlock()
+	}()
+}
+
+// A player logging in again while still online replaces their previous connection, like in vanilla.
+func (server *Server) addPlayer(conn *Connection) {
+	server.lock.Lock()
+	if server.players == nil {
+		server.players = make(map[uuid.UUID]*Connection)
+	}
+	previous := server.players[conn.Uuid()]
+	server.players[conn.Uuid()] = conn
+	server.lock.Unlock()
+
+	if previous != nil {
+		previous.Kick(chat.TextComponent { Translate: "multiplayer.disconnect.duplicate_login" })
+	}
+}
+
+// Returns false when the player had already been replaced by a newer connection.
+func (server *Server) removePlayer(conn *Connection) bool {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	delete(server.playerData, conn)
+
+	if server.players[conn.Uuid()] != conn {
+		return false
+	}
+
+	delete(server.players, conn.Uuid())
+	return true
+}
+
+// Adds a player that has just joined to the tab list of everyone online, and everyone else to theirs.
+func (server *Server) addToTabList(conn *Connection) {
+	var others []PlayerInfoToAdd
+	for _, other := range server.Players() {
+		if other == conn {
+			continue
+		}
+
+		others = append(others, PlayerInfoToAdd { Uuid: other.Uuid(), Username: other.Username(), Ping: other.PingMillis() })
+		other.AddPlayerInfo([]PlayerInfoToAdd {
+			{ Uuid: conn.Uuid(), Username: conn.Username(), Ping: conn.PingMillis() },
+		})
+	}
+
+	others = append(others, PlayerInfoToAdd { Uuid: conn.Uuid(), Username: conn.Username(), Ping: conn.PingMillis() })
+	conn.AddPlayerInfo(others)
+}
+
+// Stops accepting connections, kicks everyone with the ShutdownMessage, and waits for all connections to end.
+// Remaining connections are closed without waiting further once the context is done.
+// The World is saved last if SaveOnShutdown is set.
+func (server *Server) Shutdown(ctx context.Context) error {
+	message := server.ShutdownMessage
+	if message.Text == "" && message.Translate == "" && len(message.Extra) == 0 {
+		message = chat.TextComponent { Translate: "multiplayer.disconnect.server_shutdown" }
+	}
+
+	server.lock.Lock()
+	server.isShutdown = true
+	for listener := range server.listeners {
+		listener.Close()
+	}
+	if server.stopAutosave != nil {
+		close(server.stopAutosave)
+		server.stopAutosave = nil
+	}
+	connections := server.connectionList()
+	server.lock.Unlock()
+
+	for _, conn := range connections {
+		conn.Kick(message)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.goroutines.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		for _, conn := range connections {
+			conn.Close()
+		}
+
+		if server.SaveOnShutdown {
+			server.World.Save()
+		}
+		return ctx.Err()
+	}
+
+	if server.SaveOnShutdown {
+		return server.World.Save()
+	}
+	return nil
+}
+
+// Must be called with the lock held.
+func (server *Server) connectionList() []*Connection {
+	result := make([]*Connection, 0, len(server.connections))
+	for conn := range server.connections {
+		result = append(result, conn)
+	}
+	return result
+}
+
+// The connection of an online player, or nil.
+func (server *Server) Player(playerUuid uuid.UUID) *Connection {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return server.players[playerUuid]
+}
+
+// The connection of an online player, ignoring case like vanilla, or nil.
+func (server *Server) PlayerByName(username string) *Connection {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	for _, conn := range server.players {
+		if strings.EqualFold(conn.Username(), username) {
+			return conn
+		}
+	}
+
+	return nil
+}
+
+// The connections of all online players, in no particular order.
+func (server *Server) Players() []*Connection {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	result := make([]*Connection, 0, len(server.players))
+	for _, conn := range server.players {
+		result = append(result, conn)
+	}
+	return result
+}
+
+func (server *Server) PlayerCount() int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return len(server.players)
+}
+
+// Calls send for every online player, without holding any locks, so send may use the server.
+func (server *Server) Broadcast(send func(conn *Connection)) {
+	for _, conn := range server.Players() {
+		send(conn)
+	}
+}
+
+// Like Broadcast, but skips one player, usually the one that caused the broadcast.
+func (server *Server) BroadcastExcept(except *Connection, send func(conn *Connection)) {
+	for _, conn := range server.Players() {
+		if conn != except {
+			send(conn)
+		}
+	}
+}
+
+// Saves the World, and the data of the players that are online when PlayerDataDirectory is set.
+// Worlds chosen by OnPlayerJoinRequest are left for their owner to save.
+// Returns the first error, after trying to save everything else.
+func (server *Server) Save() error {
+	result := server.World.Save()
+
+	for _, conn := range server.Players() {
+		err := server.savePlayerData(conn)
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+// Must be called with the lock held.
+func (server *Server) startAutosave() {
+	if server.AutosaveInterval <= 0 || server.stopAutosave != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	server.stopAutosave = stop
+	server.goroutines.Add(1)
+
+	go func() {
+		defer server.goroutines.Done()
+
+		ticker := time.NewTicker(server.AutosaveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := server.Save()
+				if err != nil {
+					println("Failed to autosave (" + err.Error() + ")")
+				}
+			}
+		}
+	}()
+}
+
+func (server *Server) playerDataPath(playerUuid uuid.UUID) string {
+	return filepath.Join(server.PlayerDataDirectory, anvil.PlayerDataFileName(playerUuid))
+}
+
+// Places a player that is about to join where they left the game, in the gamemode they had, unless the response already does.
+func (server *Server) loadPlayerData(conn *Connection, response *PlayerJoinResponse) {
+	if server.PlayerDataDirectory == "" {
+		return
+	}
+
+	data, err := anvil.ReadPlayerData(server.playerDataPath(response.Uuid))
+	if err != nil {
+		// Like vanilla, a player whose data cannot be read starts over
+		if !os.IsNotExist(err) {
+			println("Failed to read player data (" + err.Error() + ")")
+		}
+		return
+	}
+
+	server.lock.Lock()
+	if server.playerData == nil {
+		server.playerData = make(map[*Connection]*anvil.PlayerData)
+	}
+	server.playerData[conn] = data
+	server.lock.Unlock()
+
+	if response.Position == nil {
+		response.Position = &EntityPosition {
+			X: data.Position[0],
+			Y: data.Position[1],
+			Z: data.Position[2],
+			Yaw: data.Rotation[0],
+			Pitch: data.Rotation[1],
+			OnGround: data.OnGround,
+		}
+	}
+
+	// Vanilla numbers the gamemodes from 0, without an invalid one
+	if response.Gamemode == javaio.GamemodeInvalid && data.Gamemode >= 0 && data.Gamemode <= 3 {
+		response.Gamemode = javaio.Gamemode(data.Gamemode + 1)
+	}
+}
+
+// Saves where an online player is, keeping the rest of the data they joined with, such as their inventory.
+func (server *Server) savePlayerData(conn *Connection) error {
+	if server.PlayerDataDirectory == "" {
+		return nil
+	}
+
+	entity := server.Entities.PlayerEntity(conn)
+	if entity == nil {
+		return nil
+	}
+
+	server.lock.Lock()
+	previous := server.playerData[conn]
+	server.lock.Unlock()
+
+	// A copy, as the player may be saved by an autosave and as they leave at once
+	data := anvil.PlayerData { DataVersion: anvilDataVersion(DefaultAnvilProtocol) }
+	if previous != nil {
+		data = *previous
+	}
+
+	position := entity.Position()
+	data.Position = [3]float64 { position.X, position.Y, position.Z }
+	data.Rotation = [2]float32 { position.Yaw, position.Pitch }
+	data.OnGround = position.OnGround
+	data.Gamemode = int32(conn.Gamemode()) - 1
+
+	err := os.MkdirAll(server.PlayerDataDirectory, 0755)
+	if err != nil {
+		return err
+	}
+
+	return anvil.WritePlayerData(server.playerDataPath(conn.Uuid()), &data)
+}
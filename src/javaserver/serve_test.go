@@ -0,0 +1,249 @@
+package javaserver
+
+import "net"
+import "time"
+import "bufio"
+import "context"
+import "testing"
+import "io/ioutil"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/google/uuid"
+
+// Serves on a random local port, with each player given a UUID derived from their name.
+func newTestServer(t *testing.T) (*Server, string, chan error) {
+	return newConfiguredTestServer(t, func(server *Server) {})
+}
+
+// Like newTestServer, but lets the server be changed before it starts serving.
+func newConfiguredTestServer(t *testing.T, configure func(server *Server)) (*Server, string, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig
+	config.CompressionThreshold = -1
+
+	server := &Server {
+		Config: config,
+		Handlers: func(conn *Connection) EventHandlers {
+			return EventHandlers {
+				OnPlayerJoinRequest: func(data PlayerJoinRequest) PlayerJoinResponse {
+					return PlayerJoinResponse { Uuid: testPlayerUuid(data.ClientsideUsername) }
+				},
+			}
+		},
+	}
+	configure(server)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	return server, listener.Addr().String(), served
+}
+
+func testPlayerUuid(username string) uuid.UUID {
+	return uuid.NewMD5(uuid.Nil, []byte(username))
+}
+
+func joinTestServer(t *testing.T, addr string, username string) net.Conn {
+	clientSide, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteVarInt(javaio.EncodePostNettyVersion(0x0286), data)
+		javaio.WriteString("localhost", data)
+		data.Write([]byte {0x63, 0xdd})
+		javaio.WriteVarInt(2, data)
+	})
+
+	writeTestPacket(t, clientSide, 0x00, func(data *bufio.Writer) {
+		javaio.WriteString(username, data)
+	})
+
+	return clientSide
+}
+
+func waitForPlayerCount(t *testing.T, server *Server, count int) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for server.PlayerCount() != count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d players but instead there are %d", count, server.PlayerCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServerRegistry(t *testing.T) {
+	server, addr, _ := newTestServer(t)
+	defer server.Shutdown(context.Background())
+
+	notch := joinTestServer(t, addr, "Notch")
+	go ioutil.ReadAll(notch)
+	jeb := joinTestServer(t, addr, "jeb_")
+	go ioutil.ReadAll(jeb)
+
+	waitForPlayerCount(t, server, 2)
+
+	conn := server.Player(testPlayerUuid("Notch"))
+	if conn == nil || conn.Username() != "Notch" {
+		t.Fatalf("Player lookup by UUID incorrect: %v", conn)
+	}
+
+	if server.PlayerByName("notch") != conn || server.PlayerByName("Herobrine") != nil {
+		t.Error("Player lookup by name incorrect")
+	}
+
+	broadcasts := 0
+	server.BroadcastExcept(conn, func(other *Connection) {
+		broadcasts++
+		if other.Username() != "jeb_" {
+			t.Errorf("Broadcast to wrong player %s", other.Username())
+		}
+	})
+
+	if broadcasts != 1 {
+		t.Errorf("Broadcast reached %d players", broadcasts)
+	}
+
+	notch.Close()
+	waitForPlayerCount(t, server, 1)
+	jeb.Close()
+	waitForPlayerCount(t, server, 0)
+
+	// The world of the server unloads the chunks nobody is sent any more
+	if count := len(server.World.loadedChunks()); count != 0 {
+		t.Errorf("Chunks still loaded: %d", count)
+	}
+}
+
+func TestServerTabList(t *testing.T) {
+	server, addr, _ := newTestServer(t)
+	defer server.Shutdown(context.Background())
+
+	notch := joinTestServer(t, addr, "Notch")
+	defer notch.Close()
+	notchInput := bufio.NewReader(notch)
+	waitForPlayerCount(t, server, 1)
+
+	jeb := joinTestServer(t, addr, "jeb_")
+	jebInput := bufio.NewReader(jeb)
+
+	expectPacket(t, notchInput, func(packet interface{}) bool {
+		add, ok := packet.(javaio.PlayerInfoAdd)
+		return ok && len(add.Players) == 1 && add.Players[0].Username == "jeb_"
+	})
+
+	add := expectPacket(t, jebInput, func(packet interface{}) bool {
+		_, ok := packet.(javaio.PlayerInfoAdd)
+		return ok
+	}).(javaio.PlayerInfoAdd)
+
+	if len(add.Players) != 2 || add.Players[0].Username != "Notch" || add.Players[1].Username != "jeb_" {
+		t.Errorf("Tab list incorrect: %#v", add.Players)
+	}
+
+	jeb.Close()
+	expectPacket(t, notchInput, func(packet interface{}) bool {
+		remove, ok := packet.(javaio.PlayerInfoRemove)
+		return ok && len(remove.Uuids) == 1 && remove.Uuids[0] == testPlayerUuid("jeb_")
+	})
+}
+
+func TestServerLatencyUpdates(t *testing.T) {
+	server, addr, _ := newConfiguredTestServer(t, func(server *Server) {
+		server.Config.KeepAliveInterval = 20 * time.Millisecond
+		server.Config.KeepAliveTimeout = 5 * time.Second
+	})
+	defer server.Shutdown(context.Background())
+
+	notch := joinTestServer(t, addr, "Notch")
+	defer notch.Close()
+	waitForPlayerCount(t, server, 1)
+
+	jeb := joinTestServer(t, addr, "jeb_")
+	defer jeb.Close()
+	waitForPlayerCount(t, server, 2)
+
+	go answerKeepAlives(notch, func(payload int64) int64 {
+		time.Sleep(5 * time.Millisecond)
+		return payload
+	})
+
+	// Only Notch answers, and everyone is told his ping, including jeb_
+	update := expectPacket(t, bufio.NewReader(jeb), func(packet interface{}) bool {
+		_, ok := packet.(javaio.PlayerInfoUpdateLatency)
+		return ok
+	}).(javaio.PlayerInfoUpdateLatency)
+
+	if len(update.Players) != 1 || update.Players[0].Uuid != testPlayerUuid("Notch") || update.Players[0].Ping < 5 {
+		t.Errorf("Latency update incorrect: %#v", update)
+	}
+}
+
+func TestServerDuplicateLogin(t *testing.T) {
+	server, addr, _ := newTestServer(t)
+	defer server.Shutdown(context.Background())
+
+	first := joinTestServer(t, addr, "Notch")
+	defer first.Close()
+	waitForPlayerCount(t, server, 1)
+	firstConn := server.Player(testPlayerUuid("Notch"))
+
+	second := joinTestServer(t, addr, "Notch")
+	defer second.Close()
+	go ioutil.ReadAll(second)
+
+	disconnect := expectDisconnect(t, first, javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286 })
+	if disconnect.Reason.Translate != "multiplayer.disconnect.duplicate_login" {
+		t.Errorf("Reason incorrect: %#v", disconnect.Reason)
+	}
+
+	<-firstConn.Done()
+	waitForPlayerCount(t, server, 1)
+
+	if server.Player(testPlayerUuid("Notch")) == firstConn {
+		t.Error("Previous connection is still registered")
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	server, addr, served := newTestServer(t)
+
+	clientSide := joinTestServer(t, addr, "Notch")
+	defer clientSide.Close()
+	waitForPlayerCount(t, server, 1)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+		defer cancel()
+		shutdown <- server.Shutdown(ctx)
+	}()
+
+	disconnect := expectDisconnect(t, clientSide, javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286 })
+	if disconnect.Reason.Translate != "multiplayer.disconnect.server_shutdown" {
+		t.Errorf("Reason incorrect: %#v", disconnect.Reason)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+
+	if _, ok := (<-served).(ServerClosedError); !ok {
+		t.Error("Serve did not return ServerClosedError")
+	}
+
+	if server.PlayerCount() != 0 {
+		t.Errorf("Players left after shutdown: %d", server.PlayerCount())
+	}
+
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Error("Still accepting connections after shutdown")
+	}
+}
@@ -1,19 +1,93 @@
 package javaserver
 
 import "io"
+import "fmt"
 import "math"
+import "sync"
 import "time"
 import "bufio"
+import "bytes"
+import "errors"
+import "syscall"
+import "crypto/rsa"
+import "crypto/rand"
+import "crypto/x509"
+import "github.com/davidcallanan/go-mcp/chat"
 import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/davidcallanan/go-mcp/registry"
 import "github.com/google/uuid"
 
 type Connection struct {
 	ctx javaio.ClientContext
+	config Config
 	inputStream *bufio.Reader
 	outputStream *bufio.Writer
 	endStream func()
 	eventHandlers EventHandlers
-	isClosed bool
+	pendingLogin *pendingLogin
+	// Set on the receive goroutine once OnPlayerJoin has been called.
+	hasJoined bool
+	// Guards ctx and outputStream, which the receive goroutine changes while packets are sent from any goroutine.
+	sendLock sync.Mutex
+	outbound chan outboundPacket
+	closeOnce sync.Once
+	done chan struct{}
+	// Set once, before done is closed.
+	closeReason LeaveReason
+	// Set atomically once the client has been kicked.
+	isKicked int32
+	goroutines sync.WaitGroup
+	// Set on the receive goroutine before OnPlayerJoin is called.
+	uuid uuid.UUID
+	username string
+	entityId int32
+	gamemode javaio.Gamemode
+	// Where the player was placed as they joined.
+	joinPosition EntityPosition
+	world *World
+	keepAlive keepAliveState
+	view chunkView
+}
+
+type Config struct {
+	// Packets at least this many bytes long are compressed once the player has logged in.
+	// A negative value disables compression.
+	CompressionThreshold int
+	// Enables encryption and verifies players with the Authenticator before they can join.
+	OnlineMode bool
+	// Defaults to the Mojang session server when nil.
+	Authenticator Authenticator
+	// Generated for each connection when nil, which is slow. Use GenerateServerKey to share one key.
+	PrivateKey *rsa.PrivateKey
+	// Number of packets that may wait to be written before the SlowClientPolicy applies.
+	// Defaults to DefaultOutboundQueueSize when zero.
+	OutboundQueueSize int
+	SlowClientPolicy SlowClientPolicy
+	// How often a keep alive is sent to players. Defaults to DefaultKeepAliveInterval when zero.
+	KeepAliveInterval time.Duration
+	// How long a player may take to answer a keep alive before they are disconnected.
+	// Defaults to DefaultKeepAliveTimeout when zero.
+	KeepAliveTimeout time.Duration
+	// Number of chunks in each direction from a player that are sent to them, or fewer if the player chooses a shorter view distance.
+	// Defaults to DefaultViewDistance when zero.
+	ViewDistance int
+	// The most chunks sent to a player each tick as they move around. Defaults to DefaultChunksPerTick when zero.
+	ChunksPerTick int
+	// The world players join, unless OnPlayerJoinRequest chooses another.
+	// Each connection joins a world of its own when nil. A Server always uses its World instead.
+	World *World
+}
+
+// State kept between the encryption request and response in online mode.
+type pendingLogin struct {
+	username string
+	privateKey *rsa.PrivateKey
+	publicKey []byte
+	verifyToken []byte
+}
+
+var DefaultConfig = Config {
+	CompressionThreshold: 256,
 }
 
 type EventHandlers struct {
@@ -23,63 +97,144 @@ type EventHandlers struct {
 	OnPlayerJoinRequest func(data PlayerJoinRequest) PlayerJoinResponse
 	OnPlayerJoin func()
 	OnPlayerMove func(data PlayerMove)
+	// Called when the client has moved to the position it was sent, usually as it joins.
+	OnTeleportConfirm func(teleportId int32)
+	// Called after joining, and whenever the player changes their settings.
+	OnClientSettings func(settings ClientSettings)
+	// Called when the player starts or stops sneaking or sprinting, leaves a bed, and so on.
+	OnEntityAction func(data EntityAction)
+	// Called when the player starts or stops flying.
+	OnPlayerAbilities func(data PlayerAbilities)
+	// Called whenever the player answers a keep alive, with their updated Latency.
+	// A Server shows the new PingMillis of the player in the tab list of everyone online first.
+	OnLatencyUpdate func(latency time.Duration)
+	// Called when the player selects a different hotbar slot, from 0 to 8.
+	OnHeldItemChange func(slot int)
+	// Called with the message sanitized by SanitizeChatMessage, unless nothing is left of it.
+	// Commands are passed on as well, starting with a slash, except on a Server, which runs them itself.
+	OnChatMessage func(message string)
+	// Called when the client asks for completions of a partly typed command, which includes the leading slash.
+	OnTabComplete func(text string) TabCompleteResponse
+	// Called once the connection of a player that has joined is closed, for whatever reason.
+	OnPlayerLeave func(reason LeaveReason)
 }
 
 func NewConnection(stream io.ReadWriter, endStream func(), eventHandlers EventHandlers) *Connection {
+	return NewConnectionWithConfig(stream, endStream, eventHandlers, DefaultConfig)
+}
+
+func NewConnectionWithConfig(stream io.ReadWriter, endStream func(), eventHandlers EventHandlers, config Config) *Connection {
+	conn := newConnection(stream, endStream, config)
+	conn.eventHandlers = eventHandlers
+	conn.start()
+	return conn
+}
+
+// Creates a connection without starting its goroutines, so that event handlers can refer to the connection.
+func newConnection(stream io.ReadWriter, endStream func(), config Config) *Connection {
+	queueSize := config.OutboundQueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultOutboundQueueSize
+	}
+
 	conn := &Connection {
 		ctx: javaio.InitialClientContext,
+		config: config,
 		inputStream: bufio.NewReader(stream),
 		outputStream: bufio.NewWriter(stream),
 		endStream: endStream,
-		eventHandlers: eventHandlers,
-		isClosed: false,
+		outbound: make(chan outboundPacket, queueSize),
+		done: make(chan struct{}),
 	}
-	
+
+	return conn
+}
+
+func (conn *Connection) start() {
+	conn.goroutines.Add(4)
+
 	go func() {
+		defer conn.goroutines.Done()
+		conn.writeLoop()
+	}()
+
+	go func() {
+		defer conn.goroutines.Done()
 		conn.receiveLoop()
 	}()
 
 	go func() {
+		defer conn.goroutines.Done()
 		conn.keepAliveLoop()
 	}()
 
-	return conn
+	go func() {
+		defer conn.goroutines.Done()
+		conn.chunkViewLoop()
+	}()
 }
 
 func (conn *Connection) receiveLoop() {
-	for !conn.isClosed {
+	for !conn.isClosed() {
 		conn.handleReceive()
 	}
-}
 
-func (conn *Connection) keepAliveLoop() {
-	timer := time.NewTicker(time.Second * 20)
-	
-	for now := range timer.C {
-		if conn.isClosed {
-			break
-		}
-		if conn.ctx.State != javaio.StatePlay {
-			continue
-		}
-	
-		conn.send(javaio.KeepAlive {
-			Payload: now.Unix(),
-		})
+	// Otherwise a chunk sent afterwards would be kept loaded with nobody to unload it
+	conn.stopChunkView()
+
+	if conn.world != nil {
+		conn.world.RemoveViewer(conn)
+	}
+
+	// Event handlers are only called from this goroutine, so this is always the last one
+	if conn.hasJoined && conn.eventHandlers.OnPlayerLeave != nil {
+		conn.eventHandlers.OnPlayerLeave(conn.closeReason)
 	}
 }
 
-func (conn *Connection) close() {
-	conn.endStream()
-	conn.isClosed = true
+// Only the receive goroutine changes the state, so it may read conn.ctx directly instead.
+func (conn *Connection) currentState() javaio.State {
+	conn.sendLock.Lock()
+	defer conn.sendLock.Unlock()
+	return conn.ctx.State
+}
+
+func (conn *Connection) currentProtocol() uint {
+	conn.sendLock.Lock()
+	defer conn.sendLock.Unlock()
+	return conn.ctx.Protocol
+}
+
+// The UUID the player joined with. Only valid once OnPlayerJoin has been called.
+func (conn *Connection) Uuid() uuid.UUID {
+	return conn.uuid
+}
+
+// The username the player joined with, as verified in online mode. Only valid once OnPlayerJoin has been called.
+func (conn *Connection) Username() string {
+	return conn.username
+}
+
+// The entity id of the player, as sent in the join game packet. Only valid once OnPlayerJoin has been called.
+func (conn *Connection) EntityId() int32 {
+	return conn.entityId
+}
+
+// The gamemode the player joined in. Only valid once OnPlayerJoin has been called.
+func (conn *Connection) Gamemode() javaio.Gamemode {
+	return conn.gamemode
+}
+
+// The world the player is in, or nil if they have not joined.
+func (conn *Connection) World() *World {
+	return conn.world
 }
 
 type StatusResponseV1 struct {
 	// Color-coding is not supported.
-	// Description is treated as plain-text.
-	// Section character must not be used, otherwise there will be undefined behaviour.
+	// Only the plain text of the description is shown.
 	PreventResponse bool
-	Description string
+	Description chat.TextComponent
 	MaxPlayers int
 	OnlinePlayers int
 }
@@ -88,7 +243,8 @@ type StatusResponseV2 struct {
 	PreventResponse bool
 	IsClientSupported bool
 	Version string
-	Description string
+	// Shown using legacy formatting codes.
+	Description chat.TextComponent
 	MaxPlayers int
 	OnlinePlayers int
 }
@@ -97,7 +253,7 @@ type StatusResponseV3 struct {
 	PreventResponse bool
 	IsClientSupported bool
 	Version string
-	Description string
+	Description chat.TextComponent
 	FaviconPng []byte
 	MaxPlayers int
 	OnlinePlayers int
@@ -106,11 +262,78 @@ type StatusResponseV3 struct {
 
 type PlayerJoinRequest struct {
 	ClientsideUsername string
+	// Only present in online mode, once the session server has verified the player.
+	Profile *GameProfile
 }
 
 type PlayerJoinResponse struct {
 	PreventResponse bool
+	// Refuses the player with this message instead of letting them join, when not nil.
+	DenyReason *chat.TextComponent
+	// In online mode this should normally be the UUID of the verified profile.
 	Uuid uuid.UUID
+	// The entity id the client knows its own player by. A Server allocates one from its EntityRegistry when zero.
+	EntityId int32
+	// The world the player joins, whose chunks around the spawn are sent to them.
+	// Defaults to Config.World when nil, and to a world of the connection's own when that is nil too.
+	// A Server always sets Config.World to its own World.
+	World *World
+	// Where the player is placed as they join. Defaults to the SpawnPosition of the world when nil.
+	// A Server places players where they left the game when nil, if it keeps their data.
+	Position *EntityPosition
+	// Defaults to creative when invalid, and like Position, a Server uses the gamemode the player had before.
+	Gamemode javaio.Gamemode
+}
+
+// Why a player has left the game.
+type LeaveReason int
+const (
+	LeaveReasonInvalid = iota
+	// The client ended the connection, usually because the player quit.
+	LeaveReasonDisconnected = iota
+	// The connection broke down, for example because it was reset.
+	LeaveReasonConnectionLost = iota
+	// The client sent something that could not be understood, or could not be sent a packet.
+	LeaveReasonProtocolError = iota
+	// The client did not read packets as fast as they were sent.
+	LeaveReasonSlowClient = iota
+	// The server closed the connection with Close.
+	LeaveReasonClosed = iota
+	// The server disconnected the client with Kick.
+	LeaveReasonKicked = iota
+	// The client did not answer a keep alive in time.
+	LeaveReasonTimedOut = iota
+)
+
+// Tells apart a client that has quit from one whose connection has broken down.
+// Either side may notice first, so reading and writing must agree.
+func streamErrorReason(err error) LeaveReason {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, syscall.EPIPE) {
+		return LeaveReasonDisconnected
+	}
+
+	return LeaveReasonConnectionLost
+}
+
+func (reason LeaveReason) String() string {
+	switch reason {
+	case LeaveReasonDisconnected:
+		return "disconnected"
+	case LeaveReasonConnectionLost:
+		return "connection lost"
+	case LeaveReasonProtocolError:
+		return "protocol error"
+	case LeaveReasonSlowClient:
+		return "slow client"
+	case LeaveReasonClosed:
+		return "closed"
+	case LeaveReasonKicked:
+		return "kicked"
+	case LeaveReasonTimedOut:
+		return "timed out"
+	default:
+		return "invalid"
+	}
 }
 
 type PlayerMove struct {
@@ -124,25 +347,61 @@ type PlayerMove struct {
 	OnGround bool
 }
 
-func (conn *Connection) send(packet interface{}) {
-	javaio.EmitClientboundPacketUncompressed(packet, conn.ctx, conn.outputStream)
+// The position after the move, keeping whatever the move leaves out from the previous position.
+func (data PlayerMove) Apply(previous EntityPosition) EntityPosition {
+	position := previous
+
+	if data.HasPos {
+		position.X = data.X
+		position.Y = data.Y
+		position.Z = data.Z
+	}
+
+	if data.HasLook {
+		position.Yaw = data.Yaw
+		position.Pitch = data.Pitch
+	}
+
+	position.OnGround = data.OnGround
+	return position
 }
 
 func (conn *Connection) handleReceive() {
-	packet, err := javaio.ParseServerboundPacketUncompressed(conn.inputStream, conn.ctx, conn.ctx.State)
+	var packet interface{}
+	var err error
+
+	if conn.ctx.CompressionThreshold >= 0 {
+		packet, err = javaio.ParseServerboundPacketCompressed(conn.inputStream, conn.ctx, conn.ctx.State)
+	} else {
+		packet, err = javaio.ParseServerboundPacketUncompressed(conn.inputStream, conn.ctx, conn.ctx.State)
+	}
 
 	if err != nil {
 		switch err.(type) {
-		case javaio.UnsupportedPayloadError:
-			println("Unsupported payload from client")
+		case javaio.UnsupportedPayloadError, javaio.UnknownPacketError:
+			// Clients keep sending play packets that are not handled yet, such as animations, so those are ignored quietly
+			if conn.ctx.State != javaio.StatePlay {
+				println("Unsupported payload from client")
+			}
 			return
 		case javaio.MalformedPacketError:
 			println("Malformed packet from client.. closing connection")
-			conn.close()
+			conn.closeWithReason(LeaveReasonProtocolError)
 			return
-		default:
-			panic(err)
 		}
+
+		if conn.isClosed() {
+			// Reading fails once the stream has been ended by Close
+			return
+		}
+
+		reason := streamErrorReason(err)
+		if reason != LeaveReasonDisconnected {
+			println("Connection to client lost (" + err.Error() + ").. closing connection")
+		}
+
+		conn.closeWithReason(reason)
+		return
 	}
 
 	switch packet := packet.(type) {
@@ -163,6 +422,8 @@ func (conn *Connection) handleReceive() {
 		// Login
 	case javaio.LoginStart:
 		conn.processLoginStart(packet)
+	case javaio.EncryptionResponse:
+		conn.processEncryptionResponse(packet)
 
 		// Play
 	case javaio.Packet_PlayerPosSb:
@@ -171,6 +432,22 @@ func (conn *Connection) handleReceive() {
 		conn.processMoveLook(packet)
 	case javaio.Packet_PlayerPosAndLookSb:
 		conn.processMoveAll(packet)
+	case javaio.KeepAlive:
+		conn.processKeepAlive(packet)
+	case javaio.TeleportConfirm:
+		conn.processTeleportConfirm(packet)
+	case javaio.ClientSettings:
+		conn.processClientSettings(packet)
+	case javaio.EntityAction:
+		conn.processEntityAction(packet)
+	case javaio.PlayerAbilitiesSb:
+		conn.processPlayerAbilities(packet)
+	case javaio.HeldItemChange:
+		conn.processHeldItemChange(packet)
+	case javaio.ChatMessageSb:
+		conn.processChatMessage(packet)
+	case javaio.TabCompleteSb:
+		conn.processTabComplete(packet)
 
 		// Pre-Netty
 	case javaio.Packet_002E_StatusRequest:
@@ -187,12 +464,16 @@ func (conn *Connection) handleReceive() {
 }
 
 func (conn *Connection) processProtocolDetermined(data javaio.ProtocolDetermined) {
+	conn.sendLock.Lock()
 	conn.ctx.State = data.NextState
+	conn.sendLock.Unlock()
 }
 
 func (conn *Connection) processHandshake(handshake javaio.Handshake) {
+	conn.sendLock.Lock()
 	conn.ctx.Protocol = javaio.DecodePostNettyVersion(handshake.Protocol)
 	conn.ctx.State = handshake.NextState
+	conn.sendLock.Unlock()
 }
 
 func (conn *Connection) processStatusRequest(_ javaio.Packet_0051_StatusRequest) {
@@ -280,77 +561,236 @@ func (conn *Connection) processPing(ping javaio.Packet_0051_Ping) {
 }
 
 func (conn *Connection) processLoginStart(data javaio.LoginStart) {
-	if conn.eventHandlers.OnPlayerJoinRequest == nil {
+	if conn.config.OnlineMode {
+		conn.requestEncryption(data.ClientsideUsername)
 		return
 	}
 
-	res := conn.eventHandlers.OnPlayerJoinRequest(PlayerJoinRequest {
+	conn.completeLogin(PlayerJoinRequest {
 		ClientsideUsername: data.ClientsideUsername,
 	})
+}
+
+func (conn *Connection) requestEncryption(username string) {
+	privateKey := conn.config.PrivateKey
+
+	if privateKey == nil {
+		var err error
+		privateKey, err = GenerateServerKey()
+		if err != nil {
+			println("Failed to generate server key.. closing connection")
+			conn.Close()
+			return
+		}
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		println("Failed to encode server key.. closing connection")
+		conn.Close()
+		return
+	}
+
+	verifyToken := make([]byte, 4)
+	_, err = rand.Read(verifyToken)
+	if err != nil {
+		println("Failed to generate verify token.. closing connection")
+		conn.Close()
+		return
+	}
+
+	conn.pendingLogin = &pendingLogin {
+		username: username,
+		privateKey: privateKey,
+		publicKey: publicKey,
+		verifyToken: verifyToken,
+	}
+
+	conn.send(javaio.EncryptionRequest {
+		ServerId: "",
+		PublicKey: publicKey,
+		VerifyToken: verifyToken,
+	})
+}
+
+func (conn *Connection) processEncryptionResponse(data javaio.EncryptionResponse) {
+	login := conn.pendingLogin
+	conn.pendingLogin = nil
+
+	if login == nil {
+		println("Unexpected encryption response from client.. closing connection")
+		conn.closeWithReason(LeaveReasonProtocolError)
+		return
+	}
+
+	sharedSecret, err := rsa.DecryptPKCS1v15(rand.Reader, login.privateKey, data.SharedSecret)
+	if err != nil || len(sharedSecret) != 16 {
+		println("Invalid shared secret from client.. closing connection")
+		conn.closeWithReason(LeaveReasonProtocolError)
+		return
+	}
+
+	verifyToken, err := rsa.DecryptPKCS1v15(rand.Reader, login.privateKey, data.VerifyToken)
+	if err != nil || !bytes.Equal(verifyToken, login.verifyToken) {
+		println("Invalid verify token from client.. closing connection")
+		conn.closeWithReason(LeaveReasonProtocolError)
+		return
+	}
+
+	// Everything after the encryption response is encrypted in both directions
+	inputStream, err := javaio.NewEncryptedReader(conn.inputStream, sharedSecret)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	outputStream, err := javaio.NewEncryptedWriter(conn.outputStream, sharedSecret)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	conn.inputStream = inputStream
+	conn.sendLock.Lock()
+	conn.outputStream = outputStream
+	conn.sendLock.Unlock()
+
+	authenticator := conn.config.Authenticator
+	if authenticator == nil {
+		authenticator = SessionServerAuthenticator {}
+	}
+
+	profile, err := authenticator.HasJoined(login.username, ServerHash("", sharedSecret, login.publicKey))
+	if err != nil {
+		println("Failed to authenticate player.. closing connection")
+		conn.Close()
+		return
+	}
+
+	conn.completeLogin(PlayerJoinRequest {
+		ClientsideUsername: login.username,
+		Profile: &profile,
+	})
+}
+
+// Chunks this many chunks or fewer from where players are placed are sent to them as they join,
+// and the rest of their view is sent a few chunks each tick afterwards.
+const joinChunkRadius = 3
+
+func (conn *Connection) completeLogin(req PlayerJoinRequest) {
+	if conn.eventHandlers.OnPlayerJoinRequest == nil {
+		return
+	}
+
+	res := conn.eventHandlers.OnPlayerJoinRequest(req)
 
 	if res.PreventResponse {
 		return
 	}
+
+	if res.DenyReason != nil {
+		conn.Kick(*res.DenyReason)
+		return
+	}
 	
 	playerUuid := res.Uuid
+	username := req.ClientsideUsername
 
-	conn.send(javaio.LoginSuccess {
+	if req.Profile != nil {
+		username = req.Profile.Username
+	}
+
+	if conn.config.CompressionThreshold >= 0 {
+		threshold := int32(conn.config.CompressionThreshold)
+		err := conn.send(javaio.SetCompression {
+			Threshold: threshold,
+		})
+		if err != nil {
+			return
+		}
+
+		conn.sendLock.Lock()
+		conn.ctx.CompressionThreshold = threshold
+		conn.sendLock.Unlock()
+	}
+
+	err := conn.send(javaio.LoginSuccess {
 		Uuid: playerUuid,
-		Username: data.ClientsideUsername,
+		Username: username,
 	})
+	if err != nil {
+		return
+	}
 
+	conn.sendLock.Lock()
 	conn.ctx.State = javaio.StatePlay
+	conn.sendLock.Unlock()
+
+	gamemode := res.Gamemode
+	if gamemode == javaio.GamemodeInvalid {
+		gamemode = javaio.GamemodeCreative
+	}
 
-	conn.send(javaio.JoinGame {
-		EntityId: 0,
-		Gamemode: javaio.GamemodeCreative,
+	world := res.World
+	if world == nil {
+		world = conn.config.World
+	}
+	if world == nil {
+		world = &World {}
+	}
+
+	spawn := world.SpawnPosition()
+	position := spawn
+	if res.Position != nil {
+		position = *res.Position
+	}
+
+	err = conn.send(javaio.JoinGame {
+		EntityId: res.EntityId,
+		Gamemode: gamemode,
 		Hardcore: false,
 		Dimension: javaio.DimensionOverworld,
-		ViewDistance: 1,
+		ViewDistance: conn.viewDistance(),
 		ReducedDebugInfo: false,
 		EnableRespawnScreen: false,
 	})
+	if err != nil {
+		return
+	}
 
-	conn.send(javaio.CompassPosition {
-		Location: javaio.BlockPosition { X: 0, Y: 64, Z: 0 },
-	})
-
-	conn.send(javaio.PlayerPositionAndLook {
-		X: 0, Y: 64, Z: 0, Yaw: 0, Pitch: 0,
+	err = conn.send(javaio.CompassPosition {
+		Location: javaio.BlockPosition { X: int(spawn.X), Y: int(spawn.Y), Z: int(spawn.Z) },
 	})
-
-	var blocksA [4096]uint32
-	var blocksB [4096]uint32
-	var blocksC [4096]uint32
-
-	for i := range blocksA {
-		if i < 256 {
-			blocksA[i] = 33
-		} else {
-			blocksA[i] = 1
-		}
+	if err != nil {
+		return
 	}
 
-	for i := range blocksB {
-		blocksB[i] = 1
+	err = conn.send(javaio.PlayerPositionAndLook {
+		X: position.X,
+		Y: position.Y,
+		Z: position.Z,
+		Yaw: position.Yaw,
+		Pitch: position.Pitch,
+	})
+	if err != nil {
+		return
 	}
 
-	for i := range blocksC {
-		if i > 4095 - 256 {
-			blocksC[i] = 9
-		} else {
-			blocksC[i] = 10
-		}
+	err = conn.startChunkView(world, position)
+	if err != nil {
+		println("Failed to send chunk (" + err.Error() + ").. closing connection")
+		conn.Close()
+		return
 	}
 
-	for x := -3; x <= 3; x++ {
-		for z := -3; z <= 3; z++ {
-			conn.send(javaio.ChunkData {
-				X: int32(x), Z: int32(z), IsNew: true,
-				Sections: [][]uint32 { nil, blocksA[:], blocksB[:], blocksC[:] },
-			})
-		}
-	}
+	conn.uuid = playerUuid
+	conn.username = username
+	conn.entityId = res.EntityId
+	conn.gamemode = gamemode
+	conn.joinPosition = position
+	conn.world = world
+	conn.hasJoined = true
+	conn.startKeepAlive()
 
 	if conn.eventHandlers.OnPlayerJoin != nil {
 		conn.eventHandlers.OnPlayerJoin()
@@ -358,6 +798,8 @@ func (conn *Connection) processLoginStart(data javaio.LoginStart) {
 }
 
 func (conn *Connection) processMovePos(data javaio.Packet_PlayerPosSb) {
+	conn.moveChunkView(data.X, data.Z)
+
 	if conn.eventHandlers.OnPlayerMove == nil {
 		return
 	}
@@ -368,6 +810,7 @@ func (conn *Connection) processMovePos(data javaio.Packet_PlayerPosSb) {
 		X: data.X,
 		Y: data.Y,
 		Z: data.Z,
+		OnGround: data.OnGround,
 	})
 }
 
@@ -381,10 +824,13 @@ func (conn *Connection) processMoveLook(data javaio.Packet_PlayerLookSb) {
 		HasLook: true,
 		Yaw: data.Yaw,
 		Pitch: data.Pitch,
+		OnGround: data.OnGround,
 	})
 }
 
 func (conn *Connection) processMoveAll(data javaio.Packet_PlayerPosAndLookSb) {
+	conn.moveChunkView(data.X, data.Z)
+
 	if conn.eventHandlers.OnPlayerMove == nil {
 		return
 	}
@@ -397,9 +843,88 @@ func (conn *Connection) processMoveAll(data javaio.Packet_PlayerPosAndLookSb) {
 		Z: data.Z,
 		Yaw: data.Yaw,
 		Pitch: data.Pitch,
+		OnGround: data.OnGround,
 	})
 }
 
+func (conn *Connection) processTeleportConfirm(data javaio.TeleportConfirm) {
+	if conn.eventHandlers.OnTeleportConfirm == nil {
+		return
+	}
+
+	conn.eventHandlers.OnTeleportConfirm(data.TeleportId)
+}
+
+type ClientSettings struct {
+	// Such as "en_us".
+	Locale string
+	// In chunks, as chosen by the player, which may be more than the server is willing to send.
+	ViewDistance int
+	ChatMode javaio.ChatMode
+	ChatColors bool
+	// A combination of the javaio.SkinPart bits.
+	DisplayedSkinParts uint8
+	MainHand javaio.Hand
+}
+
+func (conn *Connection) processClientSettings(data javaio.ClientSettings) {
+	conn.setChunkViewDistance(int(data.ViewDistance))
+
+	if conn.eventHandlers.OnClientSettings == nil {
+		return
+	}
+
+	conn.eventHandlers.OnClientSettings(ClientSettings {
+		Locale: data.Locale,
+		ViewDistance: int(data.ViewDistance),
+		ChatMode: data.ChatMode,
+		ChatColors: data.ChatColors,
+		DisplayedSkinParts: data.DisplayedSkinParts,
+		MainHand: data.MainHand,
+	})
+}
+
+type EntityAction struct {
+	Action javaio.PlayerAction
+	// From 0 to 100, only used when starting a horse jump.
+	JumpBoost int
+}
+
+func (conn *Connection) processEntityAction(data javaio.EntityAction) {
+	if conn.eventHandlers.OnEntityAction == nil {
+		return
+	}
+
+	// The entity id is always that of the player, so it is not passed on
+	conn.eventHandlers.OnEntityAction(EntityAction {
+		Action: data.Action,
+		JumpBoost: int(data.JumpBoost),
+	})
+}
+
+// The client also sends its other abilities, but they are decided by the server and so are not passed on.
+type PlayerAbilities struct {
+	IsFlying bool
+}
+
+func (conn *Connection) processPlayerAbilities(data javaio.PlayerAbilitiesSb) {
+	if conn.eventHandlers.OnPlayerAbilities == nil {
+		return
+	}
+
+	conn.eventHandlers.OnPlayerAbilities(PlayerAbilities {
+		IsFlying: data.IsFlying,
+	})
+}
+
+func (conn *Connection) processHeldItemChange(data javaio.HeldItemChange) {
+	if conn.eventHandlers.OnHeldItemChange == nil {
+		return
+	}
+
+	conn.eventHandlers.OnHeldItemChange(int(data.Slot))
+}
+
 type PlayerToSpawn struct {
 	EntityId int32
 	Uuid uuid.UUID
@@ -410,25 +935,59 @@ type PlayerToSpawn struct {
 	Pitch float32
 }
 
-func (conn *Connection) SpawnPlayer(player PlayerToSpawn) {
-	conn.send(javaio.Packet_SpawnPlayer {
+func (conn *Connection) SpawnPlayer(player PlayerToSpawn) error {
+	return conn.send(javaio.Packet_SpawnPlayer {
 		EntityId: player.EntityId,
 		Uuid: player.Uuid,
 		X: player.X,
 		Y: player.Y,
 		Z: player.Z,
-		Yaw: uint8(math.Round(float64(player.Yaw) / 360 * 255)),
-		Pitch: uint8(math.Round(float64(player.Pitch) / 360 * 255)),
+		Yaw: encodeAngle(player.Yaw),
+		Pitch: encodeAngle(player.Pitch),
+	})
+}
+
+type LivingEntityToSpawn struct {
+	EntityId int32
+	Uuid uuid.UUID
+	// Must be known in the version of the client.
+	Type EntityType
+	X float64
+	Y float64
+	Z float64
+	// Of the head as well as the body.
+	Yaw float32
+	Pitch float32
+}
+
+// Spawns a mob, whose type id is looked up for the version of the client.
+func (conn *Connection) SpawnLivingEntity(entity LivingEntityToSpawn) error {
+	typeId, err := registry.EntityTypes.LookupId(string(entity.Type), conn.currentProtocol())
+	if err != nil {
+		return err
+	}
+
+	return conn.send(javaio.SpawnLivingEntity {
+		EntityId: entity.EntityId,
+		Uuid: entity.Uuid,
+		Type: typeId,
+		X: entity.X,
+		Y: entity.Y,
+		Z: entity.Z,
+		Yaw: encodeAngle(entity.Yaw),
+		Pitch: encodeAngle(entity.Pitch),
+		HeadYaw: encodeAngle(entity.Yaw),
 	})
 }
 
 type PlayerInfoToAdd struct {
 	Uuid uuid.UUID
 	Username string
+	// In milliseconds, usually the PingMillis of the connection of that player.
 	Ping int32
 }
 
-func (conn *Connection) AddPlayerInfo(players []PlayerInfoToAdd) {
+func (conn *Connection) AddPlayerInfo(players []PlayerInfoToAdd) error {
 	packet := javaio.PlayerInfoAdd {
 		Players: make([]javaio.PlayerInfo, len(players)),
 	}
@@ -441,7 +1000,75 @@ func (conn *Connection) AddPlayerInfo(players []PlayerInfoToAdd) {
 		}
 	}
 
-	conn.send(packet)
+	return conn.send(packet)
+}
+
+type PlayerLatencyToUpdate struct {
+	Uuid uuid.UUID
+	// In milliseconds, usually the PingMillis of the connection of that player.
+	Ping int32
+}
+
+func (conn *Connection) UpdatePlayerLatency(players []PlayerLatencyToUpdate) error {
+	packet := javaio.PlayerInfoUpdateLatency {
+		Players: make([]javaio.PlayerLatency, len(players)),
+	}
+
+	for i, player := range players {
+		packet.Players[i] = javaio.PlayerLatency {
+			Uuid: player.Uuid,
+			Ping: player.Ping,
+		}
+	}
+
+	return conn.send(packet)
+}
+
+func (conn *Connection) RemovePlayerInfo(uuids []uuid.UUID) error {
+	return conn.send(javaio.PlayerInfoRemove {
+		Uuids: uuids,
+	})
+}
+
+func (conn *Connection) DestroyEntities(entityIds []int32) error {
+	return conn.send(javaio.DestroyEntities {
+		EntityIds: entityIds,
+	})
+}
+
+// Removes a player that has left from the tab list and the world of this client.
+// The entity id is the one that this client knows the player by.
+func (conn *Connection) RemovePlayer(playerUuid uuid.UUID, entityId int32) error {
+	err := conn.RemovePlayerInfo([]uuid.UUID { playerUuid })
+	if err != nil {
+		return err
+	}
+
+	return conn.DestroyEntities([]int32 { entityId })
+}
+
+// Returned by TranslateEntity when a delta does not fit into a relative move. Use MoveEntity instead.
+type MoveTooFarError struct {
+	details string
+}
+
+func (err MoveTooFarError) Error() string {
+	return fmt.Sprintf("Move too far: %s", err.details)
+}
+
+// Angles are sent in 1/256ths of a turn. Whole turns wrap around, including for negative angles.
+func encodeAngle(degrees float32) uint8 {
+	return uint8(int64(math.Round(float64(degrees) / 360 * 256)))
+}
+
+// Positions are sent in 1/4096ths of a block.
+// Deltas are taken between encoded positions, so that rounding errors do not add up on the client.
+func encodePosition(position float64) int64 {
+	return int64(math.Round(position * 4096))
+}
+
+func fitsRelativeMove(delta int64) bool {
+	return delta >= math.MinInt16 && delta <= math.MaxInt16
 }
 
 type EntityTranslation struct {
@@ -454,32 +1081,121 @@ type EntityTranslation struct {
 	OnGround bool
 }
 
-func (conn *Connection) TranslateEntity(data EntityTranslation) {
-	// TODO: 8 block bound check
-	conn.send(javaio.Packet_EntityTranslate {
+// Each delta must be less than 8 blocks.
+func (conn *Connection) TranslateEntity(data EntityTranslation) error {
+	deltaX := int64(math.Round(data.DeltaX * 4096))
+	deltaY := int64(math.Round(data.DeltaY * 4096))
+	deltaZ := int64(math.Round(data.DeltaZ * 4096))
+
+	if !fitsRelativeMove(deltaX) || !fitsRelativeMove(deltaY) || !fitsRelativeMove(deltaZ) {
+		return MoveTooFarError { fmt.Sprintf("Entity %d cannot be moved by 8 blocks or more", data.EntityId) }
+	}
+
+	return conn.send(javaio.Packet_EntityTranslate {
 		EntityId: data.EntityId,
-		DeltaX: int16(math.Round(data.DeltaX * 4096)),
-		DeltaY: int16(math.Round(data.DeltaY * 4096)),
-		DeltaZ: int16(math.Round(data.DeltaZ * 4096)),
-		Yaw: uint8(math.Round(float64(data.Yaw) / 360 * 255)),
-		Pitch: uint8(math.Round(float64(data.Pitch) / 360 * 255)),
+		DeltaX: int16(deltaX),
+		DeltaY: int16(deltaY),
+		DeltaZ: int16(deltaZ),
+		Yaw: encodeAngle(data.Yaw),
+		Pitch: encodeAngle(data.Pitch),
 		OnGround: data.OnGround,
 	})
 }
 
+// Moves an entity that the client last saw at previous, with the smallest packet that describes the change.
+// Moves of 8 blocks or more are sent as a teleport. The head is turned along with the body, like for players.
+func (conn *Connection) MoveEntity(entityId int32, previous EntityPosition, position EntityPosition) (err error) {
+	deltaX := encodePosition(position.X) - encodePosition(previous.X)
+	deltaY := encodePosition(position.Y) - encodePosition(previous.Y)
+	deltaZ := encodePosition(position.Z) - encodePosition(previous.Z)
+	yaw := encodeAngle(position.Yaw)
+	pitch := encodeAngle(position.Pitch)
+
+	hasMoved := deltaX != 0 || deltaY != 0 || deltaZ != 0
+	hasTurned := yaw != encodeAngle(previous.Yaw) || pitch != encodeAngle(previous.Pitch)
+
+	switch {
+	case !fitsRelativeMove(deltaX) || !fitsRelativeMove(deltaY) || !fitsRelativeMove(deltaZ):
+		err = conn.send(javaio.EntityTeleport {
+			EntityId: entityId,
+			X: position.X,
+			Y: position.Y,
+			Z: position.Z,
+			Yaw: yaw,
+			Pitch: pitch,
+			OnGround: position.OnGround,
+		})
+	case hasMoved && hasTurned:
+		err = conn.send(javaio.Packet_EntityTranslate {
+			EntityId: entityId,
+			DeltaX: int16(deltaX),
+			DeltaY: int16(deltaY),
+			DeltaZ: int16(deltaZ),
+			Yaw: yaw,
+			Pitch: pitch,
+			OnGround: position.OnGround,
+		})
+	case hasTurned:
+		err = conn.send(javaio.EntityLook {
+			EntityId: entityId,
+			Yaw: yaw,
+			Pitch: pitch,
+			OnGround: position.OnGround,
+		})
+	case hasMoved || position.OnGround != previous.OnGround:
+		err = conn.send(javaio.EntityRelativeMove {
+			EntityId: entityId,
+			DeltaX: int16(deltaX),
+			DeltaY: int16(deltaY),
+			DeltaZ: int16(deltaZ),
+			OnGround: position.OnGround,
+		})
+	}
+
+	if err != nil {
+		return
+	}
+
+	if yaw != encodeAngle(previous.Yaw) {
+		err = conn.SetEntityHeadYaw(entityId, position.Yaw)
+	}
+
+	return
+}
+
+// Turns the head of an entity, which is otherwise left facing the way it was spawned.
+func (conn *Connection) SetEntityHeadYaw(entityId int32, headYaw float32) error {
+	return conn.send(javaio.EntityHeadLook {
+		EntityId: entityId,
+		HeadYaw: encodeAngle(headYaw),
+	})
+}
+
 type EntityVelocity struct {
 	EntityId int32
+	// In blocks per second.
 	X float64
 	Y float64
 	Z float64
 }
 
-func (conn *Connection) SetEntityVelocity(data EntityVelocity) {
-	// TODO: bound check
-	conn.send(javaio.Packet_EntityVelocity {
+// The client ignores velocities above 3.9 blocks per tick, so larger ones are capped to that like in vanilla.
+const maxEntityVelocity = 3.9 * 20
+
+func encodeVelocity(velocity float64) int16 {
+	if math.IsNaN(velocity) {
+		return 0
+	}
+
+	velocity = math.Max(-maxEntityVelocity, math.Min(maxEntityVelocity, velocity))
+	return int16(math.Round(velocity * 400))
+}
+
+func (conn *Connection) SetEntityVelocity(data EntityVelocity) error {
+	return conn.send(javaio.Packet_EntityVelocity {
 		EntityId: data.EntityId,
-		X: int16(math.Round(data.X * 400)),
-		Y: int16(math.Round(data.Y * 400)),
-		Z: int16(math.Round(data.Z * 400)),
+		X: encodeVelocity(data.X),
+		Y: encodeVelocity(data.Y),
+		Z: encodeVelocity(data.Z),
 	})
 }
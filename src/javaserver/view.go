@@ -0,0 +1,277 @@
+package javaserver
+
+import "sync"
+import "time"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+// Used when Config.ViewDistance is not set. The same as the vanilla server.
+const DefaultViewDistance = 10
+
+// Used when Config.ChunksPerTick is not set.
+const DefaultChunksPerTick = 8
+
+// How often queued chunks are sent, which is the length of a vanilla tick.
+const chunkTickInterval = 50 * time.Millisecond
+
+// The chunks a player has been sent, and those still to be sent as they move around.
+// Shared between the receive goroutine, which moves the view, and the chunk goroutine, which sends the queued chunks.
+type chunkView struct {
+	lock sync.Mutex
+	// Set once the player has joined.
+	isActive bool
+	world *World
+	// The chunk the player is in.
+	centre chunkPosition
+	// Number of chunks in each direction from the centre that are sent to the player.
+	distance int32
+	loaded map[chunkPosition]struct{}
+	// The chunks in range that have not been sent yet, nearest first.
+	queue []chunkPosition
+}
+
+// The chunks within a square around the centre, starting with the centre and going round and round it from there.
+func spiralChunks(centre chunkPosition, radius int32) []chunkPosition {
+	result := []chunkPosition { centre }
+
+	for r := int32(1); r <= radius; r++ {
+		// Each ring starts at its north west corner and goes east, south, west and north again
+		position := chunkPosition { centre.x - r, centre.z - r }
+		for _, step := range [4]chunkPosition { {1, 0}, {0, 1}, {-1, 0}, {0, -1} } {
+			for i := int32(0); i < 2 * r; i++ {
+				result = append(result, position)
+				position.x += step.x
+				position.z += step.z
+			}
+		}
+	}
+
+	return result
+}
+
+func (view *chunkView) isInRange(position chunkPosition) bool {
+	dx := position.x - view.centre.x
+	dz := position.z - view.centre.z
+	return dx >= -view.distance && dx <= view.distance && dz >= -view.distance && dz <= view.distance
+}
+
+// Moves the view and queues the chunks that have come into range.
+// Returns the loaded chunks that have gone out of range, which are no longer counted as loaded.
+// Must be called with the lock held.
+func (view *chunkView) move(centre chunkPosition, distance int32) []chunkPosition {
+	view.centre = centre
+	view.distance = distance
+
+	if view.loaded == nil {
+		view.loaded = make(map[chunkPosition]struct{})
+	}
+
+	var unloaded []chunkPosition
+	for position := range view.loaded {
+		if !view.isInRange(position) {
+			delete(view.loaded, position)
+			unloaded = append(unloaded, position)
+		}
+	}
+
+	view.queue = nil
+	for _, position := range spiralChunks(centre, distance) {
+		if _, ok := view.loaded[position]; !ok {
+			view.queue = append(view.queue, position)
+		}
+	}
+
+	return unloaded
+}
+
+// Takes up to count chunks from the front of the queue, which are counted as loaded once they have been sent.
+// Must be called with the lock held.
+func (view *chunkView) take(count int) []chunkPosition {
+	if count > len(view.queue) {
+		count = len(view.queue)
+	}
+
+	result := view.queue[:count]
+	view.queue = view.queue[count:]
+	return result
+}
+
+// Puts chunks that were taken but could not be sent back at the front of the queue.
+// Must be called with the lock held.
+func (view *chunkView) requeue(positions []chunkPosition) {
+	view.queue = append(append([]chunkPosition {}, positions...), view.queue...)
+}
+
+func (conn *Connection) viewDistance() int32 {
+	if conn.config.ViewDistance <= 0 {
+		return DefaultViewDistance
+	}
+
+	return int32(conn.config.ViewDistance)
+}
+
+func (conn *Connection) chunksPerTick() int {
+	if conn.config.ChunksPerTick <= 0 {
+		return DefaultChunksPerTick
+	}
+
+	return conn.config.ChunksPerTick
+}
+
+// Called as the player joins. Sends the chunks nearest to them straight away, and queues the rest of the view.
+func (conn *Connection) startChunkView(world *World, position EntityPosition) error {
+	view := &conn.view
+	view.lock.Lock()
+	defer view.lock.Unlock()
+
+	centre := chunkPositionAt(position.X, position.Z)
+	view.world = world
+	view.move(centre, conn.viewDistance())
+	view.isActive = true
+
+	err := conn.send(javaio.UpdateViewPosition {
+		ChunkX: centre.x,
+		ChunkZ: centre.z,
+	})
+	if err != nil {
+		return err
+	}
+
+	radius := int32(joinChunkRadius)
+	if radius > view.distance {
+		radius = view.distance
+	}
+
+	// The queue starts with the square of chunks around the centre
+	return conn.sendChunks(view.take(int((2 * radius + 1) * (2 * radius + 1))))
+}
+
+// Called as the connection ends, after which no more chunks are sent.
+func (conn *Connection) stopChunkView() {
+	view := &conn.view
+	view.lock.Lock()
+	defer view.lock.Unlock()
+
+	view.isActive = false
+}
+
+// Sends chunks taken from the queue, and puts those that could not be sent back, to be sent on a later tick.
+// Must be called with the lock of the view held.
+func (conn *Connection) sendChunks(positions []chunkPosition) error {
+	view := &conn.view
+
+	for i, position := range positions {
+		err := view.world.SendChunk(conn, position.x, position.z)
+		if err != nil {
+			view.requeue(positions[i:])
+			return err
+		}
+
+		view.loaded[position] = struct{}{}
+	}
+
+	return nil
+}
+
+// Must be called with the lock of the view held.
+func (conn *Connection) updateChunkView(centre chunkPosition, distance int32) {
+	for _, position := range conn.view.move(centre, distance) {
+		conn.view.world.UnloadChunk(conn, position.x, position.z)
+	}
+}
+
+// Called on the receive goroutine whenever the player moves.
+func (conn *Connection) moveChunkView(x float64, z float64) {
+	view := &conn.view
+	view.lock.Lock()
+	defer view.lock.Unlock()
+
+	centre := chunkPositionAt(x, z)
+	if !view.isActive || centre == view.centre {
+		return
+	}
+
+	// Since 1.14 the client only accepts chunks within its view distance of this position
+	err := conn.send(javaio.UpdateViewPosition {
+		ChunkX: centre.x,
+		ChunkZ: centre.z,
+	})
+	if err != nil {
+		// The view stays where the client thinks it is, and is moved again as the player next moves
+		return
+	}
+
+	conn.updateChunkView(centre, view.distance)
+}
+
+// Called on the receive goroutine with the view distance chosen by the player, which is only used when it is less than that of the server.
+func (conn *Connection) setChunkViewDistance(requested int) {
+	distance := conn.viewDistance()
+	if requested > 0 && int32(requested) < distance {
+		distance = int32(requested)
+	}
+
+	view := &conn.view
+	view.lock.Lock()
+	defer view.lock.Unlock()
+
+	if !view.isActive || distance == view.distance {
+		return
+	}
+
+	err := conn.send(javaio.UpdateViewDistance {
+		ViewDistance: distance,
+	})
+	if err != nil {
+		// The view keeps the distance the client knows of
+		return
+	}
+
+	conn.updateChunkView(view.centre, distance)
+}
+
+func (conn *Connection) chunkViewLoop() {
+	ticker := time.NewTicker(chunkTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+			err := conn.sendQueuedChunks()
+			switch err.(type) {
+			case nil:
+				continue
+			case OutboundQueueFullError:
+				// Either dropped because of the slow client policy, in which case the chunk is queued to be sent again,
+				// or the connection has been closed already
+				continue
+			case ConnectionClosedError:
+				// Possibly kicked, in which case the disconnect packet has yet to be written
+				return
+			}
+
+			println("Failed to send chunk (" + err.Error() + ").. closing connection")
+			conn.Close()
+			return
+		}
+	}
+}
+
+// Sends as many of the queued chunks as the budget of a tick allows.
+func (conn *Connection) sendQueuedChunks() error {
+	view := &conn.view
+	view.lock.Lock()
+	defer view.lock.Unlock()
+
+	if !view.isActive || len(view.queue) == 0 {
+		return nil
+	}
+
+	// Leaves room in the outbound queue for other packets, so that a player is not disconnected as a slow client just for moving
+	if len(conn.outbound) > cap(conn.outbound) / 2 {
+		return nil
+	}
+
+	return conn.sendChunks(view.take(conn.chunksPerTick()))
+}
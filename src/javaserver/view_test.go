@@ -0,0 +1,195 @@
+package javaserver
+
+import "bufio"
+import "errors"
+import "reflect"
+import "testing"
+import "io/ioutil"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+func TestSpiralChunks(t *testing.T) {
+	expected := []chunkPosition {
+		{5, -3},
+		{4, -4}, {5, -4}, {6, -4}, {6, -3}, {6, -2}, {5, -2}, {4, -2}, {4, -3},
+	}
+
+	if result := spiralChunks(chunkPosition { 5, -3 }, 1); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Output incorrect: %v", result)
+	}
+
+	// Every chunk within the radius, once
+	result := spiralChunks(chunkPosition { 0, 0 }, 10)
+	seen := make(map[chunkPosition]bool)
+	for _, position := range result {
+		if seen[position] || position.x < -10 || position.x > 10 || position.z < -10 || position.z > 10 {
+			t.Fatalf("Chunk %v out of range or repeated", position)
+		}
+		seen[position] = true
+	}
+
+	if len(result) != 21 * 21 {
+		t.Errorf("Chunk count incorrect: %d", len(result))
+	}
+}
+
+func TestChunkViewMove(t *testing.T) {
+	view := &chunkView {}
+
+	if unloaded := view.move(chunkPosition { 0, 0 }, 2); len(unloaded) != 0 || len(view.queue) != 25 {
+		t.Fatalf("Initial view incorrect: %v %d", unloaded, len(view.queue))
+	}
+
+	// Limited to the budget, nearest first
+	taken := view.take(9)
+	if !reflect.DeepEqual(taken, spiralChunks(chunkPosition { 0, 0 }, 1)) {
+		t.Errorf("Taken chunks incorrect: %v", taken)
+	}
+
+	// Chunks that could not be sent go back to the front
+	view.requeue(taken[4:])
+	if len(view.queue) != 21 || view.queue[0] != taken[4] || view.queue[5] != (chunkPosition { -2, -2 }) {
+		t.Errorf("Requeued chunks incorrect: %v", view.queue)
+	}
+
+	for _, position := range taken[:4] {
+		view.loaded[position] = struct{}{}
+	}
+	for _, position := range view.take(100) {
+		view.loaded[position] = struct{}{}
+	}
+	if len(view.queue) != 0 || len(view.loaded) != 25 {
+		t.Fatalf("Chunks not loaded: %d %d", len(view.queue), len(view.loaded))
+	}
+
+	// Moving one chunk east unloads the column of chunks on the west side and queues one on the east side
+	unloaded := view.move(chunkPosition { 1, 0 }, 2)
+	if len(unloaded) != 5 || len(view.queue) != 5 {
+		t.Fatalf("Moved view incorrect: %v %v", unloaded, view.queue)
+	}
+	for i := range unloaded {
+		if unloaded[i].x != -2 || view.queue[i].x != 3 {
+			t.Errorf("Chunks incorrect: %v %v", unloaded, view.queue)
+		}
+	}
+
+	// A shorter view distance unloads the edges
+	for _, position := range view.take(5) {
+		view.loaded[position] = struct{}{}
+	}
+	if unloaded := view.move(chunkPosition { 1, 0 }, 1); len(unloaded) != 16 || len(view.queue) != 0 || len(view.loaded) != 9 {
+		t.Errorf("Shortened view incorrect: %v %v", unloaded, view.queue)
+	}
+}
+
+func TestChunkViewRequeuesUnsentChunks(t *testing.T) {
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1, ViewDistance: 1 }, EventHandlers {})
+	defer conn.Close()
+	go ioutil.ReadAll(clientSide)
+
+	// Fails the first time only
+	hasFailed := false
+	world := &World {
+		Provider: ChunkProviderFunc(func(x int32, z int32) (*ChunkColumn, error) {
+			if x == 1 && z == 0 && !hasFailed {
+				hasFailed = true
+				return nil, errors.New("Disk unavailable")
+			}
+			return nil, nil
+		}),
+	}
+
+	view := &conn.view
+	view.lock.Lock()
+	defer view.lock.Unlock()
+
+	view.world = world
+	view.loaded = nil
+	view.move(chunkPosition { 0, 0 }, 1)
+
+	if err := conn.sendChunks(view.take(9)); err == nil {
+		t.Fatal("Chunk sent without error")
+	}
+
+	// The chunk that failed and those after it are sent again later
+	if len(view.loaded) != 4 || len(view.queue) != 5 || view.queue[0] != (chunkPosition { 1, 0 }) {
+		t.Fatalf("View incorrect after failure: %v %v", view.loaded, view.queue)
+	}
+
+	if err := conn.sendChunks(view.take(9)); err != nil || len(view.loaded) != 9 || len(view.queue) != 0 {
+		t.Errorf("Chunks not sent again: %v %v", err, view.queue)
+	}
+}
+
+func TestChunkStreaming(t *testing.T) {
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1, ViewDistance: 2 }, EventHandlers {})
+	defer conn.Close()
+
+	input := bufio.NewReader(clientSide)
+
+	joinGame := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.JoinGame)
+		return ok
+	}).(javaio.JoinGame)
+
+	if joinGame.ViewDistance != 2 {
+		t.Errorf("View distance incorrect: %d", joinGame.ViewDistance)
+	}
+
+	expectPacket(t, input, func(packet interface{}) bool {
+		chunk, ok := packet.(javaio.ChunkData)
+		return ok && chunk.X == 2 && chunk.Z == -2
+	})
+
+	output := bufio.NewWriter(clientSide)
+	ctx := javaio.ClientContext { State: javaio.StatePlay, Protocol: 0x0286, CompressionThreshold: -1 }
+
+	go func() {
+		javaio.EmitServerboundPacketUncompressed(javaio.Packet_PlayerPosSb { X: 100, Y: 64, Z: -0.5, OnGround: true }, ctx, output)
+	}()
+
+	position := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.UpdateViewPosition)
+		return ok
+	}).(javaio.UpdateViewPosition)
+
+	if position.ChunkX != 6 || position.ChunkZ != -1 {
+		t.Errorf("View position incorrect: %#v", position)
+	}
+
+	expectPacket(t, input, func(packet interface{}) bool {
+		unload, ok := packet.(javaio.UnloadChunk)
+		return ok && unload.X == -2 && unload.Z == 2
+	})
+
+	// The new chunks are streamed without any more movement, nearest first
+	expectPacket(t, input, func(packet interface{}) bool {
+		chunk, ok := packet.(javaio.ChunkData)
+		if ok && (chunk.X < 4 || chunk.X > 8 || chunk.Z < -3 || chunk.Z > 1) {
+			t.Errorf("Chunk out of range sent: %d %d", chunk.X, chunk.Z)
+		}
+		return ok && chunk.X == 7 && chunk.Z == -1
+	})
+
+	expectPacket(t, input, func(packet interface{}) bool {
+		chunk, ok := packet.(javaio.ChunkData)
+		return ok && chunk.X == 7 && chunk.Z == 1
+	})
+
+	go func() {
+		javaio.EmitServerboundPacketUncompressed(javaio.ClientSettings { Locale: "en_us", ViewDistance: 1, ChatMode: javaio.ChatModeEnabled, MainHand: javaio.HandRight }, ctx, output)
+	}()
+
+	distance := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.UpdateViewDistance)
+		return ok
+	}).(javaio.UpdateViewDistance)
+
+	if distance.ViewDistance != 1 {
+		t.Errorf("Updated view distance incorrect: %d", distance.ViewDistance)
+	}
+
+	expectPacket(t, input, func(packet interface{}) bool {
+		unload, ok := packet.(javaio.UnloadChunk)
+		return ok && unload.X == 7 && unload.Z == 1
+	})
+}
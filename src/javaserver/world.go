@@ -0,0 +1,520 @@
+package javaserver
+
+import "fmt"
+import "math"
+import "sync"
+import "github.com/davidcallanan/go-mcp/javaio"
+import "github.com/davidcallanan/go-mcp/registry"
+
+// Number of 16 block high sections in a chunk column, which makes the world 256 blocks high.
+const ChunkSectionCount = 16
+
+// Returned when a block outside of the height of the world is changed.
+type PositionOutOfRangeError struct {
+	details string
+}
+
+func (err PositionOutOfRangeError) Error() string {
+	return fmt.Sprintf("Position out of range: %s", err.details)
+}
+
+// The blocks of a 16x16 column of the world, from the bottom to the top.
+// Not safe to use from multiple goroutines, so once a chunk has been handed to a World, change it through the World.
+type ChunkColumn struct {
+	X int32
+	Z int32
+	// Block state ids from the global palette, indexed by y * 256 + z * 16 + x within each section,
+	// in the same order as javaio.ChunkData expects. A nil section is filled with air.
+	Sections [ChunkSectionCount][]uint32
+}
+
+func NewChunkColumn(x int32, z int32) *ChunkColumn {
+	return &ChunkColumn { X: x, Z: z }
+}
+
+// The block at coordinates relative to the chunk, with x and z from 0 to 15 and y from 0 to 255.
+func (chunk *ChunkColumn) Block(x int, y int, z int) uint32 {
+	section := chunk.Sections[y >> 4]
+	if section == nil {
+		return 0
+	}
+
+	return section[(y & 15) * 256 + z * 16 + x]
+}
+
+// A copy that shares nothing with the chunk, so that it can be used while the chunk changes.
+func (chunk *ChunkColumn) clone() *ChunkColumn {
+	result := NewChunkColumn(chunk.X, chunk.Z)
+	for i, section := range chunk.Sections {
+		if section != nil {
+			result.Sections[i] = append([]uint32 {}, section...)
+		}
+	}
+	return result
+}
+
+// Like Block, sets the block at coordinates relative to the chunk.
+func (chunk *ChunkColumn) SetBlock(x int, y int, z int, block uint32) {
+	section := chunk.Sections[y >> 4]
+	if section == nil {
+		if block == 0 {
+			return
+		}
+
+		section = make([]uint32, 4096)
+		chunk.Sections[y >> 4] = section
+	}
+
+	section[(y & 15) * 256 + z * 16 + x] = block
+}
+
+// Supplies the chunks of a World, by generating them or loading them from storage.
+type ChunkProvider interface {
+	// Called the first time the chunk at the given chunk coordinates is needed.
+	// May be called from any goroutine, but never twice at once for the same chunk.
+	// The world takes ownership of the returned chunk.
+	ProvideChunk(x int32, z int32) (*ChunkColumn, error)
+}
+
+// Implemented by providers that can store the chunks they provide, such as AnvilChunkProvider.
+type ChunkSaver interface {
+	// Called by World.Save with a copy of a chunk whose blocks have changed since it was provided or last saved.
+	// May be called from any goroutine, but never twice at once.
+	SaveChunk(chunk *ChunkColumn) error
+}
+
+// Allows a function to be used as a ChunkProvider.
+type ChunkProviderFunc func(x int32, z int32) (*ChunkColumn, error)
+
+func (provide ChunkProviderFunc) ProvideChunk(x int32, z int32) (*ChunkColumn, error) {
+	return provide(x, z)
+}
+
+// The protocol version whose block state ids the built-in generators and DefaultBlockLightProperties use.
+const builtinBlockProtocol = 0x022E
+
+// Looks up a block state that the package itself uses, which is known to exist.
+func builtinBlockStateId(text string) uint32 {
+	id, err := registry.Blocks.LookupId(text, builtinBlockProtocol)
+	if err != nil {
+		panic(fmt.Sprintf("Internal package bug: %v", err))
+	}
+
+	return id
+}
+
+type chunkPosition struct {
+	x int32
+	z int32
+}
+
+func chunkPositionOf(pos javaio.BlockPosition) chunkPosition {
+	return chunkPosition { int32(pos.X >> 4), int32(pos.Z >> 4) }
+}
+
+// The chunk that an entity at the given coordinates is in.
+func chunkPositionAt(x float64, z float64) chunkPosition {
+	return chunkPosition { int32(math.Floor(x)) >> 4, int32(math.Floor(z)) >> 4 }
+}
+
+// A loaded chunk, along with what is needed to send it.
+type worldChunk struct {
+	// Guards the fields below, so that a chunk being lit or sent does not hold up the rest of the world.
+	lock sync.Mutex
+	column *ChunkColumn
+	// Computed when the chunk is first sent, and again once an opacity or emission has changed.
+	light *ChunkLight
+	// The connections the chunk has been sent to, which are sent changes to its blocks.
+	viewers map[*Connection]struct{}
+	// Whether the blocks have changed since the chunk was provided or last saved.
+	isModified bool
+	// Set once the chunk has been removed from the world, after which it must be looked up again.
+	isEvicted bool
+}
+
+// Holds the chunks of a dimension, which are loaded from the Provider as they are first needed.
+// The zero value is ready to use, and is safe to use from any goroutine.
+// A chunk is evicted once the last player it was sent to unloads it, and saved first if its blocks have changed.
+// Chunks whose blocks have changed stay loaded instead when the Provider is not a ChunkSaver,
+// and so do chunks that have not been sent to anyone.
+type World struct {
+	// Defaults to a FlatGenerator with DefaultFlatLayers when nil. Must not be changed once the world is in use.
+	Provider ChunkProvider
+	// Used to light chunks before they are sent. Defaults to DefaultBlockLightProperties when nil.
+	LightProperties BlockLightProperties
+
+	// Guards chunks and loading, but not the chunks themselves.
+	lock sync.Mutex
+	chunks map[chunkPosition]*worldChunk
+	// Closed once the chunk being provided has been added to chunks, or once an evicted chunk has been saved.
+	loading map[chunkPosition]chan struct{}
+	// Held while saving, so that chunks are not saved twice at once.
+	saveLock sync.Mutex
+}
+
+// Loads the chunk if needed, and returns it with its lock held, unless there is an error.
+func (world *World) lockChunk(position chunkPosition) (*worldChunk, error) {
+	for {
+		chunk, err := world.loadChunk(position)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk.lock.Lock()
+		if !chunk.isEvicted {
+			return chunk, nil
+		}
+
+		// Evicted while waiting for the lock
+		chunk.lock.Unlock()
+	}
+}
+
+// Returns the chunk, providing it first if it is not loaded yet.
+func (world *World) loadChunk(position chunkPosition) (*worldChunk, error) {
+	for {
+		world.lock.Lock()
+
+		if world.chunks == nil {
+			world.chunks = make(map[chunkPosition]*worldChunk)
+			world.loading = make(map[chunkPosition]chan struct{})
+		}
+
+		if chunk := world.chunks[position]; chunk != nil {
+			world.lock.Unlock()
+			return chunk, nil
+		}
+
+		// Another goroutine is already providing the chunk, or saving it after evicting it
+		if loaded, ok := world.loading[position]; ok {
+			world.lock.Unlock()
+			<-loaded
+			continue
+		}
+
+		loaded := make(chan struct{})
+		world.loading[position] = loaded
+		world.lock.Unlock()
+
+		// Without the lock, as providers may take a while
+		column, err := world.provideChunk(position)
+
+		world.lock.Lock()
+		delete(world.loading, position)
+		close(loaded)
+
+		if err != nil {
+			world.lock.Unlock()
+			return nil, err
+		}
+
+		chunk := &worldChunk {
+			column: column,
+			viewers: make(map[*Connection]struct{}),
+		}
+		world.chunks[position] = chunk
+		world.lock.Unlock()
+		return chunk, nil
+	}
+}
+
+// The chunks that are loaded, which may be evicted once the lock has been released.
+func (world *World) loadedChunks() []*worldChunk {
+	world.lock.Lock()
+	defer world.lock.Unlock()
+
+	result := make([]*worldChunk, 0, len(world.chunks))
+	for _, chunk := range world.chunks {
+		result = append(result, chunk)
+	}
+	return result
+}
+
+func (world *World) provider() ChunkProvider {
+	if world.Provider == nil {
+		return defaultGenerator
+	}
+
+	return world.Provider
+}
+
+// Where players join the world for the first time: on the terrain at 0, 0 when the provider is a Generator, and at y 64 otherwise.
+func (world *World) SpawnPosition() EntityPosition {
+	if generator, ok := world.provider().(Generator); ok {
+		return EntityPosition { Y: float64(generator.SurfaceHeight(0, 0)) }
+	}
+
+	return EntityPosition { Y: 64 }
+}
+
+func (world *World) provideChunk(position chunkPosition) (*ChunkColumn, error) {
+	column, err := world.provider().ProvideChunk(position.x, position.z)
+	if err != nil {
+		return nil, err
+	}
+
+	if column == nil {
+		column = NewChunkColumn(position.x, position.z)
+	}
+
+	column.X = position.x
+	column.Z = position.z
+	return column, nil
+}
+
+func (world *World) lightProperties() BlockLightProperties {
+	if world.LightProperties == nil {
+		return DefaultBlockLightProperties
+	}
+
+	return world.LightProperties
+}
+
+// The block state at a position, loading its chunk if needed. Positions above or below the world are air.
+func (world *World) GetBlock(pos javaio.BlockPosition) (uint32, error) {
+	if pos.Y < 0 || pos.Y >= ChunkSectionCount * 16 {
+		return 0, nil
+	}
+
+	chunk, err := world.lockChunk(chunkPositionOf(pos))
+	if err != nil {
+		return 0, err
+	}
+	defer chunk.lock.Unlock()
+
+	return chunk.column.Block(pos.X & 15, pos.Y, pos.Z & 15), nil
+}
+
+// Changes the block state at a position, loading its chunk if needed,
+// and shows the change to the players the chunk has been sent to.
+func (world *World) SetBlock(pos javaio.BlockPosition, block uint32) error {
+	if pos.Y < 0 || pos.Y >= ChunkSectionCount * 16 {
+		return PositionOutOfRangeError { fmt.Sprintf("Cannot set block at y %d", pos.Y) }
+	}
+
+	chunk, err := world.lockChunk(chunkPositionOf(pos))
+	if err != nil {
+		return err
+	}
+	defer chunk.lock.Unlock()
+
+	previous := chunk.column.Block(pos.X & 15, pos.Y, pos.Z & 15)
+	if previous == block {
+		return nil
+	}
+
+	chunk.column.SetBlock(pos.X & 15, pos.Y, pos.Z & 15, block)
+	chunk.isModified = true
+
+	properties := world.lightProperties()
+	previousOpacity, previousEmission := properties(previous)
+	opacity, emission := properties(block)
+	isLightChanged := opacity != previousOpacity || emission != previousEmission
+
+	if isLightChanged {
+		chunk.light = nil
+	}
+
+	for conn := range chunk.viewers {
+		conn.send(javaio.BlockChange {
+			Location: pos,
+			BlockState: int32(block),
+		})
+
+		if isLightChanged {
+			world.sendChunkLight(conn, chunk)
+		}
+	}
+
+	return nil
+}
+
+// Sends the chunk at the given chunk coordinates, loading it if needed, and keeps the player up to date with its changes.
+func (world *World) SendChunk(conn *Connection, x int32, z int32) error {
+	chunk, err := world.lockChunk(chunkPosition { x, z })
+	if err != nil {
+		return err
+	}
+
+	// The client expects the light of a chunk before the chunk itself
+	err = world.sendChunkLight(conn, chunk)
+	if err == nil {
+		err = conn.send(javaio.ChunkData {
+			X: x,
+			Z: z,
+			IsNew: true,
+			Sections: chunk.column.Sections[:],
+		})
+	}
+
+	if err != nil {
+		// Not kept loaded for a player that does not have it
+		world.releaseChunk(chunk)
+		return err
+	}
+
+	chunk.viewers[conn] = struct{}{}
+	chunk.lock.Unlock()
+	return nil
+}
+
+// Tells the player to forget a chunk sent with SendChunk, and stops sending them its changes.
+func (world *World) UnloadChunk(conn *Connection, x int32, z int32) error {
+	world.lock.Lock()
+	chunk := world.chunks[chunkPosition { x, z }]
+	world.lock.Unlock()
+
+	if chunk != nil {
+		world.removeViewer(chunk, conn)
+	}
+
+	return conn.send(javaio.UnloadChunk {
+		X: x,
+		Z: z,
+	})
+}
+
+// Must be called with the lock of the chunk held.
+func (world *World) sendChunkLight(conn *Connection, chunk *worldChunk) error {
+	if chunk.light == nil {
+		light := ComputeChunkLight(chunk.column.Sections[:], world.lightProperties())
+		chunk.light = &light
+	}
+
+	return conn.send(javaio.UpdateLight {
+		ChunkX: chunk.column.X,
+		ChunkZ: chunk.column.Z,
+		SkyLight: chunk.light.SkyLight,
+		BlockLight: chunk.light.BlockLight,
+	})
+}
+
+// Stops sending changes to a player, usually because they have left.
+func (world *World) RemoveViewer(conn *Connection) {
+	for _, chunk := range world.loadedChunks() {
+		world.removeViewer(chunk, conn)
+	}
+}
+
+// Evicts the chunk once its last viewer has been removed.
+func (world *World) removeViewer(chunk *worldChunk, conn *Connection) {
+	chunk.lock.Lock()
+
+	_, isViewer := chunk.viewers[conn]
+	delete(chunk.viewers, conn)
+
+	if !isViewer {
+		chunk.lock.Unlock()
+		return
+	}
+
+	world.releaseChunk(chunk)
+}
+
+// Evicts the chunk unless it has viewers, saving it first if its blocks have changed.
+// Changed chunks are kept loaded instead when the provider cannot save them, as they would be provided again without the changes.
+// Must be called with the lock of the chunk held, which is released.
+func (world *World) releaseChunk(chunk *worldChunk) {
+	saver, ok := world.Provider.(ChunkSaver)
+
+	if len(chunk.viewers) != 0 || chunk.isEvicted || (!ok && chunk.isModified) {
+		chunk.lock.Unlock()
+		return
+	}
+
+	position := chunkPosition { chunk.column.X, chunk.column.Z }
+	chunk.isEvicted = true
+
+	world.lock.Lock()
+	delete(world.chunks, position)
+	// Keeps the chunk from being provided again until it has been saved
+	var saved chan struct{}
+	if ok {
+		saved = make(chan struct{})
+		world.loading[position] = saved
+	}
+	world.lock.Unlock()
+	chunk.lock.Unlock()
+
+	if ok {
+		world.saveEvicted(saver, chunk, saved)
+	}
+}
+
+// Saves an evicted chunk if its blocks have changed, and puts it back into the world should that fail.
+func (world *World) saveEvicted(saver ChunkSaver, chunk *worldChunk, saved chan struct{}) {
+	// Waits for a Save in progress, which marks the chunk as modified again if it fails to save it
+	world.saveLock.Lock()
+
+	chunk.lock.Lock()
+	isModified := chunk.isModified
+	chunk.isModified = false
+	chunk.lock.Unlock()
+
+	var err error
+	if isModified {
+		// Nothing changes a chunk once it has been evicted, so it needs no copy
+		err = saver.SaveChunk(chunk.column)
+	}
+	world.saveLock.Unlock()
+
+	if err != nil {
+		println("Failed to save chunk (" + err.Error() + ").. keeping it loaded")
+		chunk.lock.Lock()
+		chunk.isModified = true
+		chunk.isEvicted = false
+		chunk.lock.Unlock()
+	}
+
+	position := chunkPosition { chunk.column.X, chunk.column.Z }
+	world.lock.Lock()
+	if err != nil {
+		world.chunks[position] = chunk
+	}
+	delete(world.loading, position)
+	close(saved)
+	world.lock.Unlock()
+}
+
+// Passes the chunks whose blocks have changed since they were provided or last saved to the Provider,
+// if it is a ChunkSaver, and does nothing otherwise. Changes made while saving are saved the next time.
+// Returns the first error, after trying to save the rest of the chunks. Chunks that fail to save are tried again the next time.
+func (world *World) Save() error {
+	saver, ok := world.Provider.(ChunkSaver)
+	if !ok {
+		return nil
+	}
+
+	world.saveLock.Lock()
+	defer world.saveLock.Unlock()
+
+	var result error
+	for _, chunk := range world.loadedChunks() {
+		chunk.lock.Lock()
+		// Evicted chunks are saved as they are evicted
+		if chunk.isEvicted || !chunk.isModified {
+			chunk.lock.Unlock()
+			continue
+		}
+
+		chunk.isModified = false
+		column := chunk.column.clone()
+		chunk.lock.Unlock()
+
+		// Without the lock, as saving may take a while
+		err := saver.SaveChunk(column)
+		if err == nil {
+			continue
+		}
+
+		if result == nil {
+			result = err
+		}
+
+		chunk.lock.Lock()
+		chunk.isModified = true
+		chunk.lock.Unlock()
+	}
+
+	return result
+}
@@ -0,0 +1,253 @@
+package javaserver
+
+import "sync"
+import "time"
+import "bufio"
+import "testing"
+import "io/ioutil"
+import "sync/atomic"
+import "github.com/davidcallanan/go-mcp/javaio"
+
+func TestWorldBlocks(t *testing.T) {
+	world := &World {}
+
+	iomap := []struct {
+		pos javaio.BlockPosition
+		block uint32
+	} {
+		{javaio.BlockPosition { X: 0, Y: 0, Z: 0 }, 33},
+		{javaio.BlockPosition { X: -1, Y: 30, Z: 100 }, 1},
+		{javaio.BlockPosition { X: -17, Y: 50, Z: -1 }, 10},
+		{javaio.BlockPosition { X: 5, Y: 63, Z: -33 }, 9},
+		{javaio.BlockPosition { X: 5, Y: 64, Z: 5 }, 0},
+		{javaio.BlockPosition { X: 5, Y: -1, Z: 5 }, 0},
+		{javaio.BlockPosition { X: 5, Y: 256, Z: 5 }, 0},
+	}
+
+	for i, mapping := range iomap {
+		block, err := world.GetBlock(mapping.pos)
+		if err != nil || block != mapping.block {
+			t.Errorf("Output incorrect for mapping %d: %d %v", i, block, err)
+		}
+	}
+
+	pos := javaio.BlockPosition { X: -20, Y: 200, Z: 7 }
+	err := world.SetBlock(pos, 230)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if block, _ := world.GetBlock(pos); block != 230 {
+		t.Errorf("Block not set: %d", block)
+	}
+
+	// Neighbouring blocks in the same chunk are unchanged
+	if block, _ := world.GetBlock(javaio.BlockPosition { X: -19, Y: 200, Z: 7 }); block != 0 {
+		t.Errorf("Neighbouring block changed: %d", block)
+	}
+
+	err = world.SetBlock(javaio.BlockPosition { X: 0, Y: 256, Z: 0 }, 1)
+	if _, ok := err.(PositionOutOfRangeError); !ok {
+		t.Errorf("Block above the world set: %v", err)
+	}
+}
+
+func TestWorldProvidesChunksOnce(t *testing.T) {
+	var calls int32
+
+	world := &World {
+		Provider: ChunkProviderFunc(func(x int32, z int32) (*ChunkColumn, error) {
+			atomic.AddInt32(&calls, 1)
+			chunk := NewChunkColumn(x, z)
+			chunk.SetBlock(0, 0, 0, uint32(x * 100 + z))
+			return chunk, nil
+		}),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			block, err := world.GetBlock(javaio.BlockPosition { X: 32, Y: 0, Z: -16 })
+			if err != nil || block != 199 {
+				t.Errorf("Block incorrect: %d %v", block, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Chunk provided %d times", calls)
+	}
+}
+
+func TestWorldSendsChanges(t *testing.T) {
+	world := &World {}
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1 }, EventHandlers {
+		OnPlayerJoinRequest: func(data PlayerJoinRequest) PlayerJoinResponse {
+			return PlayerJoinResponse { World: world }
+		},
+	})
+	defer conn.Close()
+
+	input := bufio.NewReader(clientSide)
+
+	chunk := expectPacket(t, input, func(packet interface{}) bool {
+		chunk, ok := packet.(javaio.ChunkData)
+		return ok && chunk.X == 3 && chunk.Z == 3
+	}).(javaio.ChunkData)
+
+	if chunk.Sections[3][4095] != 9 {
+		t.Errorf("Chunk incorrect: %d", chunk.Sections[3][4095])
+	}
+
+	// Glass lets light through, unlike the grass it replaces, so the light is sent again
+	pos := javaio.BlockPosition { X: -48, Y: 63, Z: 1 }
+	world.SetBlock(pos, 230)
+
+	change := expectPacket(t, input, func(packet interface{}) bool {
+		_, ok := packet.(javaio.BlockChange)
+		return ok
+	}).(javaio.BlockChange)
+
+	if change.Location != pos || change.BlockState != 230 {
+		t.Errorf("Block change incorrect: %#v", change)
+	}
+
+	expectPacket(t, input, func(packet interface{}) bool {
+		light, ok := packet.(javaio.UpdateLight)
+		return ok && light.ChunkX == -3 && light.ChunkZ == 0
+	})
+}
+
+// Keeps the chunks saved to it in memory, and provides them again.
+type memoryChunkStore struct {
+	lock sync.Mutex
+	chunks map[chunkPosition]*ChunkColumn
+	provided int
+	saved int
+}
+
+func (store *memoryChunkStore) ProvideChunk(x int32, z int32) (*ChunkColumn, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.provided++
+	if chunk := store.chunks[chunkPosition { x, z }]; chunk != nil {
+		return chunk.clone(), nil
+	}
+	return nil, nil
+}
+
+func (store *memoryChunkStore) SaveChunk(chunk *ChunkColumn) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if store.chunks == nil {
+		store.chunks = make(map[chunkPosition]*ChunkColumn)
+	}
+	store.saved++
+	store.chunks[chunkPosition { chunk.X, chunk.Z }] = chunk.clone()
+	return nil
+}
+
+func (store *memoryChunkStore) counts() (int, int) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	return store.provided, store.saved
+}
+
+func TestWorldEvictsChunks(t *testing.T) {
+	store := &memoryChunkStore {}
+	world := &World { Provider: store }
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1, ViewDistance: 1, World: world }, EventHandlers {})
+	defer conn.Close()
+	go ioutil.ReadAll(clientSide)
+
+	// The chunks around 0, 0 have been sent as the player joined
+	if provided, _ := store.counts(); provided != 9 || len(world.loadedChunks()) != 9 {
+		t.Fatalf("Chunks provided incorrectly: %d %d", provided, len(world.loadedChunks()))
+	}
+
+	pos := javaio.BlockPosition { X: 17, Y: 100, Z: 0 }
+	err := world.SetBlock(pos, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Saved as its last viewer unloads it
+	world.UnloadChunk(conn, 1, 0)
+	if _, saved := store.counts(); saved != 1 || len(world.loadedChunks()) != 8 {
+		t.Errorf("Modified chunk not evicted: %d %d", saved, len(world.loadedChunks()))
+	}
+
+	// And not saved again when unchanged
+	world.UnloadChunk(conn, 0, 0)
+	if _, saved := store.counts(); saved != 1 || len(world.loadedChunks()) != 7 {
+		t.Errorf("Unmodified chunk not evicted: %d %d", saved, len(world.loadedChunks()))
+	}
+
+	if block, err := world.GetBlock(pos); err != nil || block != 1 {
+		t.Errorf("Evicted chunk provided incorrectly: %d %v", block, err)
+	}
+
+	// The rest are evicted as the player leaves, except the chunk loaded without being sent
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(world.loadedChunks()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Chunks still loaded: %d", len(world.loadedChunks()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if provided, saved := store.counts(); provided != 10 || saved != 1 {
+		t.Errorf("Chunks provided or saved incorrectly: %d %d", provided, saved)
+	}
+}
+
+func TestWorldKeepsChangedChunksOfGenerators(t *testing.T) {
+	// Generated by the default generator, which cannot save chunks
+	world := &World {}
+
+	conn, clientSide := newTestPlayer(t, Config { CompressionThreshold: -1, ViewDistance: 1, World: world }, EventHandlers {})
+	defer conn.Close()
+	go ioutil.ReadAll(clientSide)
+
+	pos := javaio.BlockPosition { X: 17, Y: 100, Z: 0 }
+	err := world.SetBlock(pos, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	world.UnloadChunk(conn, 1, 0)
+	if count := len(world.loadedChunks()); count != 9 {
+		t.Errorf("Changed chunk evicted: %d", count)
+	}
+
+	// Unchanged chunks are generated again as needed
+	world.UnloadChunk(conn, 0, 0)
+	if count := len(world.loadedChunks()); count != 8 {
+		t.Errorf("Unchanged chunk not evicted: %d", count)
+	}
+
+	if block, err := world.GetBlock(pos); err != nil || block != 1 {
+		t.Errorf("Changed block lost: %d %v", block, err)
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(world.loadedChunks()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Chunks still loaded: %d", len(world.loadedChunks()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if block, err := world.GetBlock(pos); err != nil || block != 1 {
+		t.Errorf("Changed block lost after leaving: %d %v", block, err)
+	}
+}
@@ -0,0 +1,91 @@
+package nbt
+
+import "io"
+import "os"
+import "bufio"
+import "io/ioutil"
+import "path/filepath"
+import "compress/gzip"
+import "compress/zlib"
+
+// Returns a reader that transparently decompresses r if it begins with a gzip or zlib header.
+// Level and player data files are gzip-compressed, whereas chunks in region files are usually zlib-compressed.
+func NewDecompressingReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+
+	if err != nil {
+		return nil, MalformedDataError { "Data ended abruptly" }
+	}
+
+	if header[0] == 0x1f && header[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+
+	if header[0] & 0x0f == 0x08 && (uint16(header[0]) << 8 | uint16(header[1])) % 31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return buffered, nil
+}
+
+// Decodes a possibly compressed root tag from r into v.
+func ReadCompressed(r io.Reader, v interface{}) (name string, err error) {
+	decompressed, err := NewDecompressingReader(r)
+	if err != nil {
+		return
+	}
+
+	return NewDecoder(decompressed).DecodeNamed(v)
+}
+
+func ReadFile(path string, v interface{}) (name string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	return ReadCompressed(file, v)
+}
+
+// Encodes v as a gzip-compressed root tag with an empty name, like in level and player data files.
+func WriteCompressed(w io.Writer, v interface{}) error {
+	compressor := gzip.NewWriter(w)
+
+	err := NewEncoder(compressor).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	return compressor.Close()
+}
+
+// Like WriteCompressed, but replaces the file in one step, so that it is never left half written.
+func WriteFile(path string, v interface{}) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path) + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	err = WriteCompressed(file, v)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		// Temporary files are only readable by their owner
+		err = file.Chmod(0644)
+	}
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
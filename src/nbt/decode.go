@@ -0,0 +1,513 @@
+package nbt
+
+import "io"
+import "fmt"
+import "math"
+import "bytes"
+import "bufio"
+import "reflect"
+
+// Arrays and lists longer than this are rejected rather than allocated.
+const maxLength = 1 << 24
+
+type Decoder struct {
+	r *bufio.Reader
+	network bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder {
+		r: bufio.NewReader(r),
+	}
+}
+
+// Creates a decoder for the network format introduced in 1.20.2, where the root tag is not named.
+func NewNetworkDecoder(r io.Reader) *Decoder {
+	return &Decoder {
+		r: bufio.NewReader(r),
+		network: true,
+	}
+}
+
+func Unmarshal(data []byte, v interface{}) error {
+	return NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func UnmarshalNetwork(data []byte, v interface{}) error {
+	return NewNetworkDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (dec *Decoder) Decode(v interface{}) error {
+	_, err := dec.DecodeNamed(v)
+	return err
+}
+
+// Decodes the next root tag into v, which must be a non-nil pointer, and returns the name of the root tag.
+func (dec *Decoder) DecodeNamed(v interface{}) (name string, err error) {
+	target := reflect.ValueOf(v)
+
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		err = UnsupportedTypeError { "Decode target must be a non-nil pointer" }
+		return
+	}
+
+	state := decodeState {
+		r: dec.r,
+	}
+
+	tagType, err := state.readTagType()
+	if err != nil {
+		return
+	}
+
+	if tagType == TagEnd {
+		err = MalformedDataError { "Root tag must not be TAG_End" }
+		return
+	}
+
+	if !dec.network {
+		name, err = state.readString()
+		if err != nil {
+			return
+		}
+	}
+
+	err = state.readPayload(tagType, target, 0)
+	return
+}
+
+///////////////////////////////////////
+// Primitives
+///////////////////////////////////////
+
+type decodeState struct {
+	r *bufio.Reader
+	buf [8]byte
+}
+
+func (state *decodeState) readFull(n int) ([]byte, error) {
+	_, err := io.ReadFull(state.r, state.buf[:n])
+	if err != nil {
+		return nil, MalformedDataError { "Data ended abruptly" }
+	}
+
+	return state.buf[:n], nil
+}
+
+func (state *decodeState) readTagType() (TagType, error) {
+	b, err := state.readFull(1)
+	if err != nil {
+		return TagEnd, err
+	}
+
+	if b[0] > byte(TagLongArray) {
+		return TagEnd, MalformedDataError { fmt.Sprintf("Unknown tag type %d", b[0]) }
+	}
+
+	return TagType(b[0]), nil
+}
+
+func (state *decodeState) readUint16() (uint16, error) {
+	b, err := state.readFull(2)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint16(b[0]) << 8 | uint16(b[1]), nil
+}
+
+func (state *decodeState) readUint32() (uint32, error) {
+	b, err := state.readFull(4)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(b[0]) << 24 | uint32(b[1]) << 16 | uint32(b[2]) << 8 | uint32(b[3]), nil
+}
+
+func (state *decodeState) readUint64() (uint64, error) {
+	high, err := state.readUint32()
+	if err != nil {
+		return 0, err
+	}
+
+	low, err := state.readUint32()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(high) << 32 | uint64(low), nil
+}
+
+func (state *decodeState) readLength() (int, error) {
+	n, err := state.readUint32()
+	if err != nil {
+		return 0, err
+	}
+
+	length := int32(n)
+	if length < 0 || length > maxLength {
+		return 0, MalformedDataError { fmt.Sprintf("Invalid length %d", length) }
+	}
+
+	return int(length), nil
+}
+
+func (state *decodeState) readString() (string, error) {
+	length, err := state.readUint16()
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(state.r, data)
+	if err != nil {
+		return "", MalformedDataError { "String ended abruptly" }
+	}
+
+	return decodeModifiedUtf8(data)
+}
+
+///////////////////////////////////////
+// Generic values
+///////////////////////////////////////
+
+func (state *decodeState) readValue(tagType TagType, depth int) (result interface{}, err error) {
+	if depth > maxDepth {
+		err = MalformedDataError { "Data is nested too deeply" }
+		return
+	}
+
+	switch tagType {
+	case TagByte:
+		var b []byte
+		b, err = state.readFull(1)
+		if err == nil {
+			result = int8(b[0])
+		}
+	case TagShort:
+		var n uint16
+		n, err = state.readUint16()
+		result = int16(n)
+	case TagInt:
+		var n uint32
+		n, err = state.readUint32()
+		result = int32(n)
+	case TagLong:
+		var n uint64
+		n, err = state.readUint64()
+		result = int64(n)
+	case TagFloat:
+		var n uint32
+		n, err = state.readUint32()
+		result = math.Float32frombits(n)
+	case TagDouble:
+		var n uint64
+		n, err = state.readUint64()
+		result = math.Float64frombits(n)
+	case TagString:
+		result, err = state.readString()
+	case TagByteArray:
+		var length int
+		length, err = state.readLength()
+		if err != nil {
+			return
+		}
+
+		data := make([]byte, length)
+		_, readErr := io.ReadFull(state.r, data)
+		if readErr != nil {
+			err = MalformedDataError { "Byte array ended abruptly" }
+			return
+		}
+		result = data
+	case TagIntArray:
+		var length int
+		length, err = state.readLength()
+		if err != nil {
+			return
+		}
+
+		data := make([]int32, length)
+		for i := range data {
+			var n uint32
+			n, err = state.readUint32()
+			if err != nil {
+				return
+			}
+			data[i] = int32(n)
+		}
+		result = data
+	case TagLongArray:
+		var length int
+		length, err = state.readLength()
+		if err != nil {
+			return
+		}
+
+		data := make([]int64, length)
+		for i := range data {
+			var n uint64
+			n, err = state.readUint64()
+			if err != nil {
+				return
+			}
+			data[i] = int64(n)
+		}
+		result = data
+	case TagList:
+		var elemType TagType
+		elemType, err = state.readTagType()
+		if err != nil {
+			return
+		}
+
+		var length int
+		length, err = state.readLength()
+		if err != nil {
+			return
+		}
+
+		list := make([]interface{}, 0)
+		for i := 0; i < length; i++ {
+			var elem interface{}
+			elem, err = state.readValue(elemType, depth + 1)
+			if err != nil {
+				return
+			}
+			list = append(list, elem)
+		}
+		result = list
+	case TagCompound:
+		compound := make(map[string]interface{})
+
+		for {
+			var elemType TagType
+			elemType, err = state.readTagType()
+			if err != nil || elemType == TagEnd {
+				break
+			}
+
+			var name string
+			name, err = state.readString()
+			if err != nil {
+				return
+			}
+
+			compound[name], err = state.readValue(elemType, depth + 1)
+			if err != nil {
+				return
+			}
+		}
+		result = compound
+	default:
+		err = MalformedDataError { fmt.Sprintf("Unexpected %s", tagType) }
+	}
+
+	return
+}
+
+///////////////////////////////////////
+// Typed values
+///////////////////////////////////////
+
+func (state *decodeState) readPayload(tagType TagType, target reflect.Value, depth int) error {
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	switch {
+	case target.Kind() == reflect.Interface && target.NumMethod() == 0:
+		value, err := state.readValue(tagType, depth)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(value))
+		return nil
+	case tagType == TagCompound && target.Kind() == reflect.Struct:
+		return state.readStruct(target, depth)
+	case tagType == TagCompound && target.Kind() == reflect.Map && target.Type().Key().Kind() == reflect.String:
+		return state.readMap(target, depth)
+	case tagType == TagList && (target.Kind() == reflect.Slice || target.Kind() == reflect.Array):
+		return state.readList(target, depth)
+	}
+
+	// Everything else is decoded generically and then converted
+	value, err := state.readValue(tagType, depth)
+	if err != nil {
+		return err
+	}
+
+	return assign(target, reflect.ValueOf(value), tagType)
+}
+
+func (state *decodeState) readStruct(target reflect.Value, depth int) error {
+	fields := make(map[string]int)
+	for _, field := range structFields(target.Type()) {
+		fields[field.name] = field.index
+	}
+
+	for {
+		elemType, err := state.readTagType()
+		if err != nil {
+			return err
+		}
+
+		if elemType == TagEnd {
+			return nil
+		}
+
+		name, err := state.readString()
+		if err != nil {
+			return err
+		}
+
+		if index, ok := fields[name]; ok {
+			err = state.readPayload(elemType, target.Field(index), depth + 1)
+		} else {
+			// Unknown fields are skipped
+			_, err = state.readValue(elemType, depth + 1)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (state *decodeState) readMap(target reflect.Value, depth int) error {
+	if target.IsNil() {
+		target.Set(reflect.MakeMap(target.Type()))
+	}
+
+	for {
+		elemType, err := state.readTagType()
+		if err != nil {
+			return err
+		}
+
+		if elemType == TagEnd {
+			return nil
+		}
+
+		name, err := state.readString()
+		if err != nil {
+			return err
+		}
+
+		elem := reflect.New(target.Type().Elem()).Elem()
+		err = state.readPayload(elemType, elem, depth + 1)
+		if err != nil {
+			return err
+		}
+
+		target.SetMapIndex(reflect.ValueOf(name).Convert(target.Type().Key()), elem)
+	}
+}
+
+func (state *decodeState) readList(target reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return MalformedDataError { "Data is nested too deeply" }
+	}
+
+	elemType, err := state.readTagType()
+	if err != nil {
+		return err
+	}
+
+	length, err := state.readLength()
+	if err != nil {
+		return err
+	}
+
+	if target.Kind() == reflect.Slice {
+		target.Set(reflect.MakeSlice(target.Type(), 0, 0))
+	} else if length > target.Len() {
+		return UnsupportedTypeError { fmt.Sprintf("List of length %d does not fit in %s", length, target.Type()) }
+	}
+
+	for i := 0; i < length; i++ {
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.Append(target, reflect.Zero(target.Type().Elem())))
+		}
+
+		err = state.readPayload(elemType, target.Index(i), depth + 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var byteSliceType = reflect.TypeOf([]byte {})
+
+// Converts a generically decoded value into the type of the target.
+func assign(target reflect.Value, value reflect.Value, tagType TagType) error {
+	mismatch := UnsupportedTypeError { fmt.Sprintf("Cannot decode %s into Go type %s", tagType, target.Type()) }
+
+	switch value.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8:
+		var n int64
+		if value.Kind() == reflect.Uint8 {
+			// Elements of byte arrays, which are signed in NBT
+			n = int64(int8(value.Uint()))
+		} else {
+			n = value.Int()
+		}
+
+		switch target.Kind() {
+		case reflect.Bool:
+			target.SetBool(n != 0)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			target.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			target.SetUint(uint64(n))
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(float64(n))
+		default:
+			return mismatch
+		}
+	case reflect.Float32, reflect.Float64:
+		switch target.Kind() {
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(value.Float())
+		default:
+			return mismatch
+		}
+	case reflect.String:
+		if target.Kind() != reflect.String {
+			return mismatch
+		}
+		target.SetString(value.String())
+	case reflect.Slice:
+		if target.Kind() != reflect.Slice && target.Kind() != reflect.Array {
+			return mismatch
+		}
+
+		if target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8 && value.Type() == byteSliceType {
+			target.SetBytes(append([]byte {}, value.Bytes()...))
+			return nil
+		}
+
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(target.Type(), value.Len(), value.Len()))
+		} else if value.Len() > target.Len() {
+			return UnsupportedTypeError { fmt.Sprintf("Array of length %d does not fit in %s", value.Len(), target.Type()) }
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			err := assign(target.Index(i), value.Index(i), tagType)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+
+	return nil
+}
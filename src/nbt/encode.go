@@ -0,0 +1,441 @@
+package nbt
+
+import "io"
+import "fmt"
+import "math"
+import "sort"
+import "bytes"
+import "bufio"
+import "reflect"
+import "strings"
+
+type Encoder struct {
+	w io.Writer
+	network bool
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder {
+		w: w,
+	}
+}
+
+// Creates an encoder for the network format introduced in 1.20.2, where the root tag is not named.
+func NewNetworkEncoder(w io.Writer) *Encoder {
+	return &Encoder {
+		w: w,
+		network: true,
+	}
+}
+
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func MarshalNetwork(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := NewNetworkEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+// Encodes v as a root tag with an empty name.
+func (enc *Encoder) Encode(v interface{}) error {
+	return enc.EncodeNamed("", v)
+}
+
+func (enc *Encoder) EncodeNamed(name string, v interface{}) error {
+	if enc.network && name != "" {
+		return UnsupportedTypeError { "Root tag cannot be named in the network format" }
+	}
+
+	value := reflect.ValueOf(v)
+	tagType, err := tagTypeOfValue(value, false)
+	if err != nil {
+		return err
+	}
+
+	state := encodeState {
+		w: bufio.NewWriter(enc.w),
+	}
+
+	state.writeByte(byte(tagType))
+	if !enc.network {
+		err = state.writeString(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = state.writePayload(value, tagType, 0)
+	if err != nil {
+		return err
+	}
+
+	return state.w.Flush()
+}
+
+///////////////////////////////////////
+// Tag types of Go values
+///////////////////////////////////////
+
+func tagTypeOfType(t reflect.Type, asList bool) (tagType TagType, ok bool) {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return TagByte, true
+	case reflect.Int16, reflect.Uint16:
+		return TagShort, true
+	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
+		return TagInt, true
+	case reflect.Int64, reflect.Uint64:
+		return TagLong, true
+	case reflect.Float32:
+		return TagFloat, true
+	case reflect.Float64:
+		return TagDouble, true
+	case reflect.String:
+		return TagString, true
+	case reflect.Slice, reflect.Array:
+		if !asList {
+			switch t.Elem().Kind() {
+			case reflect.Int8, reflect.Uint8:
+				return TagByteArray, true
+			case reflect.Int32:
+				return TagIntArray, true
+			case reflect.Int64:
+				return TagLongArray, true
+			}
+		}
+		return TagList, true
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return TagCompound, true
+		}
+	case reflect.Struct:
+		return TagCompound, true
+	case reflect.Ptr:
+		return tagTypeOfType(t.Elem(), asList)
+	}
+
+	return TagEnd, false
+}
+
+func tagTypeOfValue(value reflect.Value, asList bool) (TagType, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return TagEnd, UnsupportedTypeError { "Cannot encode nil value" }
+		}
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return TagEnd, UnsupportedTypeError { "Cannot encode nil value" }
+	}
+
+	tagType, ok := tagTypeOfType(value.Type(), asList)
+	if !ok {
+		return TagEnd, UnsupportedTypeError { fmt.Sprintf("Cannot encode Go type %s", value.Type()) }
+	}
+
+	return tagType, nil
+}
+
+///////////////////////////////////////
+// Struct fields
+///////////////////////////////////////
+
+type fieldInfo struct {
+	index int
+	name string
+	omitEmpty bool
+	asList bool
+}
+
+func structFields(t reflect.Type) []fieldInfo {
+	fields := make([]fieldInfo, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.PkgPath != "" {
+			// Unexported
+			continue
+		}
+
+		tag := field.Tag.Get("nbt")
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		info := fieldInfo {
+			index: i,
+			name: parts[0],
+		}
+
+		if info.name == "" {
+			info.name = field.Name
+		}
+
+		for _, option := range parts[1:] {
+			switch option {
+			case "omitempty":
+				info.omitEmpty = true
+			case "list":
+				info.asList = true
+			}
+		}
+
+		fields = append(fields, info)
+	}
+
+	return fields
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+
+	return false
+}
+
+///////////////////////////////////////
+// Payloads
+///////////////////////////////////////
+
+type encodeState struct {
+	w *bufio.Writer
+}
+
+func (state *encodeState) writeByte(value byte) {
+	state.w.WriteByte(value)
+}
+
+func (state *encodeState) writeUint16(value uint16) {
+	state.w.Write([]byte {byte(value >> 8), byte(value)})
+}
+
+func (state *encodeState) writeUint32(value uint32) {
+	state.w.Write([]byte {byte(value >> 24), byte(value >> 16), byte(value >> 8), byte(value)})
+}
+
+func (state *encodeState) writeUint64(value uint64) {
+	state.writeUint32(uint32(value >> 32))
+	state.writeUint32(uint32(value))
+}
+
+func (state *encodeState) writeString(value string) error {
+	encoded := encodeModifiedUtf8(value)
+
+	if len(encoded) > math.MaxUint16 {
+		return UnsupportedTypeError { "String is too long" }
+	}
+
+	state.writeUint16(uint16(len(encoded)))
+	state.w.Write(encoded)
+	return nil
+}
+
+func (state *encodeState) writeLength(length int) error {
+	if length > math.MaxInt32 {
+		return UnsupportedTypeError { "Array or list is too long" }
+	}
+
+	state.writeUint32(uint32(length))
+	return nil
+}
+
+func integerOf(value reflect.Value) uint64 {
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(value.Int())
+	default:
+		return value.Uint()
+	}
+}
+
+func (state *encodeState) writePayload(value reflect.Value, tagType TagType, depth int) error {
+	if depth > maxDepth {
+		return UnsupportedTypeError { "Value is nested too deeply" }
+	}
+
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	switch tagType {
+	case TagByte:
+		state.writeByte(byte(integerOf(value)))
+	case TagShort:
+		state.writeUint16(uint16(integerOf(value)))
+	case TagInt:
+		n := integerOf(value)
+		if (value.Kind() == reflect.Int && int64(n) != int64(int32(n))) || (value.Kind() == reflect.Uint && n > math.MaxUint32) {
+			return UnsupportedTypeError { fmt.Sprintf("Value %d does not fit in %s", value.Interface(), tagType) }
+		}
+		state.writeUint32(uint32(n))
+	case TagLong:
+		state.writeUint64(integerOf(value))
+	case TagFloat:
+		state.writeUint32(math.Float32bits(float32(value.Float())))
+	case TagDouble:
+		state.writeUint64(math.Float64bits(value.Float()))
+	case TagString:
+		return state.writeString(value.String())
+	case TagByteArray, TagIntArray, TagLongArray:
+		err := state.writeLength(value.Len())
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			n := integerOf(value.Index(i))
+			switch tagType {
+			case TagByteArray:
+				state.writeByte(byte(n))
+			case TagIntArray:
+				state.writeUint32(uint32(n))
+			default:
+				state.writeUint64(n)
+			}
+		}
+	case TagList:
+		return state.writeList(value, depth)
+	case TagCompound:
+		if value.Kind() == reflect.Map {
+			return state.writeMap(value, depth)
+		}
+		return state.writeStruct(value, depth)
+	default:
+		return UnsupportedTypeError { fmt.Sprintf("Cannot encode %s", tagType) }
+	}
+
+	return nil
+}
+
+func (state *encodeState) writeList(value reflect.Value, depth int) error {
+	elemType := TagEnd
+	staticType, isStatic := tagTypeOfType(value.Type().Elem(), false)
+
+	if isStatic {
+		elemType = staticType
+	} else if value.Len() > 0 {
+		// Elements are interfaces, so the first element decides the list type
+		var err error
+		elemType, err = tagTypeOfValue(value.Index(0), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	state.writeByte(byte(elemType))
+	err := state.writeLength(value.Len())
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		elem := value.Index(i)
+
+		if !isStatic {
+			actualType, err := tagTypeOfValue(elem, false)
+			if err != nil {
+				return err
+			}
+
+			if actualType != elemType {
+				return UnsupportedTypeError { fmt.Sprintf("List cannot contain both %s and %s", elemType, actualType) }
+			}
+		} else if (elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface) && elem.IsNil() {
+			return UnsupportedTypeError { "Cannot encode nil list element" }
+		}
+
+		err = state.writePayload(elem, elemType, depth + 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (state *encodeState) writeNamedTag(name string, value reflect.Value, asList bool, depth int) error {
+	tagType, err := tagTypeOfValue(value, asList)
+	if err != nil {
+		return err
+	}
+
+	state.writeByte(byte(tagType))
+	err = state.writeString(name)
+	if err != nil {
+		return err
+	}
+
+	return state.writePayload(value, tagType, depth + 1)
+}
+
+func (state *encodeState) writeStruct(value reflect.Value, depth int) error {
+	for _, field := range structFields(value.Type()) {
+		fieldValue := value.Field(field.index)
+
+		if field.omitEmpty && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		if (fieldValue.Kind() == reflect.Ptr || fieldValue.Kind() == reflect.Interface) && fieldValue.IsNil() {
+			// There is no null tag, so nil fields are left out of the compound
+			continue
+		}
+
+		err := state.writeNamedTag(field.name, fieldValue, field.asList, depth)
+		if err != nil {
+			return err
+		}
+	}
+
+	state.writeByte(byte(TagEnd))
+	return nil
+}
+
+func (state *encodeState) writeMap(value reflect.Value, depth int) error {
+	keys := value.MapKeys()
+
+	// Sort keys so that output is deterministic
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, key := range keys {
+		elem := value.MapIndex(key)
+
+		if (elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface) && elem.IsNil() {
+			continue
+		}
+
+		err := state.writeNamedTag(key.String(), elem, false, depth)
+		if err != nil {
+			return err
+		}
+	}
+
+	state.writeByte(byte(TagEnd))
+	return nil
+}
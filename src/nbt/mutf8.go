@@ -0,0 +1,59 @@
+package nbt
+
+import "unicode/utf16"
+
+// NBT strings use Java's "modified UTF-8", which differs from standard UTF-8
+// in that null characters take two bytes and supplementary characters are
+// encoded as two three-byte surrogates.
+
+func encodeModifiedUtf8(value string) []byte {
+	result := make([]byte, 0, len(value))
+
+	for _, r := range value {
+		switch {
+		case r != 0 && r < 0x80:
+			result = append(result, byte(r))
+		case r < 0x800:
+			result = append(result, byte(0xc0 | r >> 6), byte(0x80 | r & 0x3f))
+		case r < 0x10000:
+			result = append(result, byte(0xe0 | r >> 12), byte(0x80 | (r >> 6) & 0x3f), byte(0x80 | r & 0x3f))
+		default:
+			r1, r2 := utf16.EncodeRune(r)
+			for _, s := range []rune {r1, r2} {
+				result = append(result, byte(0xe0 | s >> 12), byte(0x80 | (s >> 6) & 0x3f), byte(0x80 | s & 0x3f))
+			}
+		}
+	}
+
+	return result
+}
+
+func decodeModifiedUtf8(data []byte) (string, error) {
+	units := make([]uint16, 0, len(data))
+
+	for i := 0; i < len(data); {
+		b := data[i]
+
+		switch {
+		case b < 0x80:
+			units = append(units, uint16(b))
+			i += 1
+		case b & 0xe0 == 0xc0:
+			if i + 1 >= len(data) || data[i + 1] & 0xc0 != 0x80 {
+				return "", MalformedDataError { "Invalid modified UTF-8 string" }
+			}
+			units = append(units, uint16(b & 0x1f) << 6 | uint16(data[i + 1] & 0x3f))
+			i += 2
+		case b & 0xf0 == 0xe0:
+			if i + 2 >= len(data) || data[i + 1] & 0xc0 != 0x80 || data[i + 2] & 0xc0 != 0x80 {
+				return "", MalformedDataError { "Invalid modified UTF-8 string" }
+			}
+			units = append(units, uint16(b & 0x0f) << 12 | uint16(data[i + 1] & 0x3f) << 6 | uint16(data[i + 2] & 0x3f))
+			i += 3
+		default:
+			return "", MalformedDataError { "Invalid modified UTF-8 string" }
+		}
+	}
+
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,89 @@
+// Package nbt implements the Named Binary Tag format used by Minecraft for
+// world data and many packet fields.
+//
+// Go values map onto tags as follows:
+//
+//   bool, int8, uint8        Byte
+//   int16, uint16            Short
+//   int, int32, uint32       Int
+//   int64, uint64            Long
+//   float32                  Float
+//   float64                  Double
+//   string                   String
+//   []byte, []int8           Byte Array
+//   []int32                  Int Array
+//   []int64                  Long Array
+//   other slices and arrays  List
+//   structs, map[string]T    Compound
+//
+// Struct fields are named by the "nbt" struct tag, falling back to the field
+// name. The tag options "omitempty" (skip zero values) and "list" (encode a
+// byte, int or long slice as a List instead of an array) are supported, and
+// a tag of "-" skips the field.
+//
+// Decoding into an empty interface produces int8, int16, int32, int64,
+// float32, float64, string, []byte, []int32, []int64, []interface{} and
+// map[string]interface{} values.
+package nbt
+
+import "fmt"
+
+type TagType byte
+const (
+	TagEnd TagType = 0
+	TagByte TagType = 1
+	TagShort TagType = 2
+	TagInt TagType = 3
+	TagLong TagType = 4
+	TagFloat TagType = 5
+	TagDouble TagType = 6
+	TagByteArray TagType = 7
+	TagString TagType = 8
+	TagList TagType = 9
+	TagCompound TagType = 10
+	TagIntArray TagType = 11
+	TagLongArray TagType = 12
+)
+
+var tagNames = []string {
+	"TAG_End",
+	"TAG_Byte",
+	"TAG_Short",
+	"TAG_Int",
+	"TAG_Long",
+	"TAG_Float",
+	"TAG_Double",
+	"TAG_Byte_Array",
+	"TAG_String",
+	"TAG_List",
+	"TAG_Compound",
+	"TAG_Int_Array",
+	"TAG_Long_Array",
+}
+
+func (tagType TagType) String() string {
+	if int(tagType) < len(tagNames) {
+		return tagNames[tagType]
+	}
+
+	return fmt.Sprintf("TAG_Unknown(%d)", byte(tagType))
+}
+
+// Vanilla refuses to read compounds and lists nested deeper than this.
+const maxDepth = 512
+
+type MalformedDataError struct {
+	details string
+}
+
+func (err MalformedDataError) Error() string {
+	return fmt.Sprintf("Malformed NBT data: %s", err.details)
+}
+
+type UnsupportedTypeError struct {
+	details string
+}
+
+func (err UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("Unsupported NBT type: %s", err.details)
+}
@@ -0,0 +1,229 @@
+package nbt
+
+import "os"
+import "bytes"
+import "reflect"
+import "testing"
+import "io/ioutil"
+import "path/filepath"
+import "compress/gzip"
+import "compress/zlib"
+
+// The "hello world" example from the original NBT specification
+var helloWorld = []byte {
+	0x0a, 0x00, 0x0b, 'h', 'e', 'l', 'l', 'o', ' ', 'w', 'o', 'r', 'l', 'd',
+	0x08, 0x00, 0x04, 'n', 'a', 'm', 'e', 0x00, 0x09, 'B', 'a', 'n', 'a', 'n', 'r', 'a', 'm', 'a',
+	0x00,
+}
+
+type helloWorldCompound struct {
+	Name string `nbt:"name"`
+}
+
+func TestEncodeNamed(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewEncoder(&buf).EncodeNamed("hello world", helloWorldCompound { Name: "Bananrama" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), helloWorld) {
+		t.Errorf("Output incorrect: %x", buf.Bytes())
+	}
+}
+
+func TestDecodeNamed(t *testing.T) {
+	var result helloWorldCompound
+	name, err := NewDecoder(bytes.NewReader(helloWorld)).DecodeNamed(&result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "hello world" || result.Name != "Bananrama" {
+		t.Errorf("Output incorrect: %q %#v", name, result)
+	}
+}
+
+func TestNetworkFormat(t *testing.T) {
+	data, err := MarshalNetwork(helloWorldCompound { Name: "Bananrama" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same as the named format, minus the root name
+	expected := append([]byte {0x0a}, helloWorld[14:]...)
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Output incorrect: %x", data)
+	}
+
+	var result helloWorldCompound
+	err = UnmarshalNetwork(data, &result)
+	if err != nil || result.Name != "Bananrama" {
+		t.Errorf("Output incorrect: %#v %v", result, err)
+	}
+}
+
+type allTypes struct {
+	Bool bool
+	Byte int8
+	UByte uint8
+	Short int16
+	Int int32
+	PlainInt int
+	Long int64
+	Float float32
+	Double float64
+	String string
+	ByteArray []byte
+	IntArray []int32
+	LongArray []int64
+	IntList []int32 `nbt:",list"`
+	StringList []string
+	Compounds []helloWorldCompound
+	Map map[string]int16
+	Pointer *helloWorldCompound
+	Renamed string `nbt:"renamed_field"`
+	Omitted string `nbt:",omitempty"`
+	Skipped string `nbt:"-"`
+	Fixed [3]float64
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := allTypes {
+		Bool: true,
+		Byte: -5,
+		UByte: 200,
+		Short: -1234,
+		Int: 123456789,
+		PlainInt: -42,
+		Long: -1234567890123,
+		Float: 1.5,
+		Double: -2.25,
+		String: "null \x00 and emoji \U0001F600",
+		ByteArray: []byte {1, 2, 255},
+		IntArray: []int32 {-1, 0, 1},
+		LongArray: []int64 {1 << 40, -1},
+		IntList: []int32 {7, 8},
+		StringList: []string {"a", "b"},
+		Compounds: []helloWorldCompound {{ Name: "x" }, { Name: "y" }},
+		Map: map[string]int16 { "one": 1, "two": 2 },
+		Pointer: &helloWorldCompound { Name: "pointer" },
+		Renamed: "renamed",
+		Skipped: "skipped",
+		Fixed: [3]float64 {1, 2, 3},
+	}
+
+	data, err := Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output allTypes
+	err = Unmarshal(data, &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input.Skipped = ""
+	if !reflect.DeepEqual(input, output) {
+		t.Errorf("Round trip incorrect:\n%#v\n%#v", input, output)
+	}
+
+	var generic map[string]interface{}
+	err = Unmarshal(data, &generic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := generic["Omitted"]; ok {
+		t.Errorf("Empty field with omitempty was encoded")
+	}
+
+	if list, ok := generic["IntList"].([]interface{}); !ok || len(list) != 2 || list[0] != int32(7) {
+		t.Errorf("Field with list option was not encoded as a list: %#v", generic["IntList"])
+	}
+
+	if generic["renamed_field"] != "renamed" || generic["UByte"] != int8(-56) || generic["Long"] != int64(-1234567890123) {
+		t.Errorf("Generic output incorrect: %#v", generic)
+	}
+}
+
+func TestMixedListIsRejected(t *testing.T) {
+	_, err := Marshal(map[string]interface{} {
+		"list": []interface{} {int32(1), "two"},
+	})
+
+	if _, ok := err.(UnsupportedTypeError); !ok {
+		t.Errorf("Expected UnsupportedTypeError but instead got: %v", err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	iemap := [][]byte {
+		{},
+		{0x00},
+		{0x0d, 0x00, 0x00},
+		helloWorld[:len(helloWorld) - 1],
+		{0x07, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff},
+	}
+
+	for i, mappingInput := range iemap {
+		var result interface{}
+		err := Unmarshal(mappingInput, &result)
+
+		if _, ok := err.(MalformedDataError); !ok {
+			t.Errorf("Expected MalformedDataError for mapping %d but instead got: %v", i, err)
+		}
+	}
+}
+
+func TestReadCompressed(t *testing.T) {
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	gzipWriter.Write(helloWorld)
+	gzipWriter.Close()
+
+	var zlibbed bytes.Buffer
+	zlibWriter := zlib.NewWriter(&zlibbed)
+	zlibWriter.Write(helloWorld)
+	zlibWriter.Close()
+
+	for i, input := range [][]byte {gzipped.Bytes(), zlibbed.Bytes(), helloWorld} {
+		var result helloWorldCompound
+		name, err := ReadCompressed(bytes.NewReader(input), &result)
+
+		if err != nil || name != "hello world" || result.Name != "Bananrama" {
+			t.Errorf("Output incorrect for mapping %d: %q %#v %v", i, name, result, err)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nbt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "level.dat")
+
+	// Writing twice replaces the file
+	for _, name := range []string {"Banana", "Bananrama"} {
+		err = WriteFile(path, helloWorldCompound { Name: name })
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var result helloWorldCompound
+	_, err = ReadFile(path, &result)
+	if err != nil || result.Name != "Bananrama" {
+		t.Errorf("Output incorrect: %#v %v", result, err)
+	}
+
+	// Nothing is left behind besides the file itself
+	files, _ := ioutil.ReadDir(directory)
+	if len(files) != 1 {
+		t.Errorf("Expected 1 file but instead found %d", len(files))
+	}
+}
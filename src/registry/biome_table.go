@@ -0,0 +1,82 @@
+package registry
+
+// Versions are inclusive and use the numbering described in docs/protocol_versions.md.
+// Biome ids are not consecutive, as the variants of a biome have its id plus 128.
+const biomeTable = `
+# first last  name                               id
+022E  0293  ocean                              0
+022E  0293  plains                             1
+022E  0293  desert                             2
+022E  0293  mountains                          3
+022E  0293  forest                             4
+022E  0293  taiga                              5
+022E  0293  swamp                              6
+022E  0293  river                              7
+022E  0293  nether                             8
+022E  0293  the_end                            9
+022E  0293  frozen_ocean                       10
+022E  0293  frozen_river                       11
+022E  0293  snowy_tundra                       12
+022E  0293  snowy_mountains                    13
+022E  0293  mushroom_fields                    14
+022E  0293  mushroom_field_shore               15
+022E  0293  beach                              16
+022E  0293  desert_hills                       17
+022E  0293  wooded_hills                       18
+022E  0293  taiga_hills                        19
+022E  0293  mountain_edge                      20
+022E  0293  jungle                             21
+022E  0293  jungle_hills                       22
+022E  0293  jungle_edge                        23
+022E  0293  deep_ocean                         24
+022E  0293  stone_shore                        25
+022E  0293  snowy_beach                        26
+022E  0293  birch_forest                       27
+022E  0293  birch_forest_hills                 28
+022E  0293  dark_forest                        29
+022E  0293  snowy_taiga                        30
+022E  0293  snowy_taiga_hills                  31
+022E  0293  giant_tree_taiga                   32
+022E  0293  giant_tree_taiga_hills             33
+022E  0293  wooded_mountains                   34
+022E  0293  savanna                            35
+022E  0293  savanna_plateau                    36
+022E  0293  badlands                           37
+022E  0293  wooded_badlands_plateau            38
+022E  0293  badlands_plateau                   39
+022E  0293  small_end_islands                  40
+022E  0293  end_midlands                       41
+022E  0293  end_highlands                      42
+022E  0293  end_barrens                        43
+022E  0293  warm_ocean                         44
+022E  0293  lukewarm_ocean                     45
+022E  0293  cold_ocean                         46
+022E  0293  deep_warm_ocean                    47
+022E  0293  deep_lukewarm_ocean                48
+022E  0293  deep_cold_ocean                    49
+022E  0293  deep_frozen_ocean                  50
+022E  0293  the_void                           127
+022E  0293  sunflower_plains                   129
+022E  0293  desert_lakes                       130
+022E  0293  gravelly_mountains                 131
+022E  0293  flower_forest                      132
+022E  0293  taiga_mountains                    133
+022E  0293  swamp_hills                        134
+022E  0293  ice_spikes                         140
+022E  0293  modified_jungle                    149
+022E  0293  modified_jungle_edge               151
+022E  0293  tall_birch_forest                  155
+022E  0293  tall_birch_hills                   156
+022E  0293  dark_forest_hills                  157
+022E  0293  snowy_taiga_mountains              158
+022E  0293  giant_spruce_taiga                 160
+022E  0293  giant_spruce_taiga_hills           161
+022E  0293  modified_gravelly_mountains        162
+022E  0293  shattered_savanna                  163
+022E  0293  shattered_savanna_plateau          164
+022E  0293  eroded_badlands                    165
+022E  0293  modified_wooded_badlands_plateau   166
+022E  0293  modified_badlands_plateau          167
+022E  0293  bamboo_jungle                      168
+022E  0293  bamboo_jungle_hills                169
+`
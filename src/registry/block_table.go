@@ -0,0 +1,729 @@
+package registry
+
+// Versions are inclusive and use the numbering described in docs/protocol_versions.md.
+// Each block takes the ids after the previous block, one for every combination of the values of its properties,
+// like in the block reports of the vanilla data generator. Properties are ordered by name, the last changing fastest,
+// and values are in the order they are listed. The default value of a property, which it takes when it is left
+// out, is in brackets, or is the first value if none is. Sets of properties that several blocks have start with @.
+const blockTable = `
+@facing4                 facing=[north],south,west,east
+@facing6                 facing=[north],east,south,west,up,down
+@axis                    axis=x,[y],z
+@powered                 powered=true,[false]
+@lit                     lit=true,[false]
+@waterlogged             waterlogged=true,[false]
+@snowy                   snowy=true,[false]
+@rail6                   shape=[north_south],east_west,ascending_east,ascending_west,ascending_north,ascending_south
+@stairs                  @facing4 half=top,[bottom] shape=[straight],inner_left,inner_right,outer_left,outer_right @waterlogged
+@slab                    type=top,[bottom],double @waterlogged
+@fence                   east=true,[false] north=true,[false] south=true,[false] west=true,[false] @waterlogged
+@wall                    east=true,[false] north=true,[false] south=true,[false] up=[true],false west=true,[false] @waterlogged
+@fence_gate              @facing4 in_wall=true,[false] open=true,[false] @powered
+@door                    @facing4 half=upper,[lower] hinge=[left],right open=true,[false] @powered
+@trapdoor                @facing4 half=top,[bottom] open=true,[false] @powered @waterlogged
+@button                  face=floor,[wall],ceiling @facing4 @powered
+@bed                     @facing4 occupied=true,[false] part=head,[foot]
+@sign                    rotation=[0]..15 @waterlogged
+@wall_sign               @facing4 @waterlogged
+@rotation                rotation=[0]..15
+@tall_plant              half=upper,[lower]
+@age7                    age=[0]..7
+@age3                    age=[0]..3
+@chest                   @facing4 type=[single],left,right @waterlogged
+@piston                  extended=true,[false] @facing6
+@dispenser               @facing6 triggered=true,[false]
+@command                 conditional=true,[false] @facing6
+@mushroom                down=[true],false east=[true],false north=[true],false south=[true],false up=[true],false west=[true],false
+@coral                   waterlogged=[true],false
+@coral_wall              @facing4 waterlogged=[true],false
+@furnace                 @facing4 @lit
+@level15                 level=[0]..15
+
+# first last  name                                  properties
+
+022E  0293  air
+022E  0293  stone
+022E  0293  granite
+022E  0293  polished_granite
+022E  0293  diorite
+022E  0293  polished_diorite
+022E  0293  andesite
+022E  0293  polished_andesite
+022E  0293  grass_block                           @snowy
+022E  0293  dirt
+022E  0293  coarse_dirt
+022E  0293  podzol                                @snowy
+022E  0293  cobblestone
+022E  0293  oak_planks
+022E  0293  spruce_planks
+022E  0293  birch_planks
+022E  0293  jungle_planks
+022E  0293  acacia_planks
+022E  0293  dark_oak_planks
+022E  0293  oak_sapling                           stage=[0]..1
+022E  0293  spruce_sapling                        stage=[0]..1
+022E  0293  birch_sapling                         stage=[0]..1
+022E  0293  jungle_sapling                        stage=[0]..1
+022E  0293  acacia_sapling                        stage=[0]..1
+022E  0293  dark_oak_sapling                      stage=[0]..1
+022E  0293  bedrock
+022E  0293  water                                 @level15
+022E  0293  lava                                  @level15
+022E  0293  sand
+022E  0293  red_sand
+022E  0293  gravel
+022E  0293  gold_ore
+022E  0293  iron_ore
+022E  0293  coal_ore
+022E  0293  oak_log                               @axis
+022E  0293  spruce_log                            @axis
+022E  0293  birch_log                             @axis
+022E  0293  jungle_log                            @axis
+022E  0293  acacia_log                            @axis
+022E  0293  dark_oak_log                          @axis
+022E  0293  stripped_spruce_log                   @axis
+022E  0293  stripped_birch_log                    @axis
+022E  0293  stripped_jungle_log                   @axis
+022E  0293  stripped_acacia_log                   @axis
+022E  0293  stripped_dark_oak_log                 @axis
+022E  0293  stripped_oak_log                      @axis
+022E  0293  oak_wood                              @axis
+022E  0293  spruce_wood                           @axis
+022E  0293  birch_wood                            @axis
+022E  0293  jungle_wood                           @axis
+022E  0293  acacia_wood                           @axis
+022E  0293  dark_oak_wood                         @axis
+022E  0293  stripped_oak_wood                     @axis
+022E  0293  stripped_spruce_wood                  @axis
+022E  0293  stripped_birch_wood                   @axis
+022E  0293  stripped_jungle_wood                  @axis
+022E  0293  stripped_acacia_wood                  @axis
+022E  0293  stripped_dark_oak_wood                @axis
+022E  0293  oak_leaves                            distance=1..[7] persistent=true,[false]
+022E  0293  spruce_leaves                         distance=1..[7] persistent=true,[false]
+022E  0293  birch_leaves                          distance=1..[7] persistent=true,[false]
+022E  0293  jungle_leaves                         distance=1..[7] persistent=true,[false]
+022E  0293  acacia_leaves                         distance=1..[7] persistent=true,[false]
+022E  0293  dark_oak_leaves                       distance=1..[7] persistent=true,[false]
+022E  0293  sponge
+022E  0293  wet_sponge
+022E  0293  glass
+022E  0293  lapis_ore
+022E  0293  lapis_block
+022E  0293  dispenser                             @dispenser
+022E  0293  sandstone
+022E  0293  chiseled_sandstone
+022E  0293  cut_sandstone
+022E  0293  note_block                            instrument=[harp],basedrum,snare,hat,bass,flute,bell,guitar,chime,xylophone,iron_xylophone,cow_bell,didgeridoo,bit,banjo,pling note=[0]..24 @powered
+022E  0293  white_bed                             @bed
+022E  0293  orange_bed                            @bed
+022E  0293  magenta_bed                           @bed
+022E  0293  light_blue_bed                        @bed
+022E  0293  yellow_bed                            @bed
+022E  0293  lime_bed                              @bed
+022E  0293  pink_bed                              @bed
+022E  0293  gray_bed                              @bed
+022E  0293  light_gray_bed                        @bed
+022E  0293  cyan_bed                              @bed
+022E  0293  purple_bed                            @bed
+022E  0293  blue_bed                              @bed
+022E  0293  brown_bed                             @bed
+022E  0293  green_bed                             @bed
+022E  0293  red_bed                               @bed
+022E  0293  black_bed                             @bed
+022E  0293  powered_rail                          @powered @rail6
+022E  0293  detector_rail                         @powered @rail6
+022E  0293  sticky_piston                         @piston
+022E  0293  cobweb
+022E  0293  grass
+022E  0293  fern
+022E  0293  dead_bush
+022E  0293  seagrass
+022E  0293  tall_seagrass                         @tall_plant
+022E  0293  piston                                @piston
+022E  0293  piston_head                           @facing6 short=true,[false] type=[normal],sticky
+022E  0293  white_wool
+022E  0293  orange_wool
+022E  0293  magenta_wool
+022E  0293  light_blue_wool
+022E  0293  yellow_wool
+022E  0293  lime_wool
+022E  0293  pink_wool
+022E  0293  gray_wool
+022E  0293  light_gray_wool
+022E  0293  cyan_wool
+022E  0293  purple_wool
+022E  0293  blue_wool
+022E  0293  brown_wool
+022E  0293  green_wool
+022E  0293  red_wool
+022E  0293  black_wool
+022E  0293  moving_piston                         @facing6 type=[normal],sticky
+022E  0293  dandelion
+022E  0293  poppy
+022E  0293  blue_orchid
+022E  0293  allium
+022E  0293  azure_bluet
+022E  0293  red_tulip
+022E  0293  orange_tulip
+022E  0293  white_tulip
+022E  0293  pink_tulip
+022E  0293  oxeye_daisy
+022E  0293  cornflower
+022E  0293  wither_rose
+022E  0293  lily_of_the_valley
+022E  0293  brown_mushroom
+022E  0293  red_mushroom
+022E  0293  gold_block
+022E  0293  iron_block
+022E  0293  bricks
+022E  0293  tnt                                   unstable=true,[false]
+022E  0293  bookshelf
+022E  0293  mossy_cobblestone
+022E  0293  obsidian
+022E  0293  torch
+022E  0293  wall_torch                            @facing4
+022E  0293  fire                                  age=[0]..15 east=true,[false] north=true,[false] south=true,[false] up=true,[false] west=true,[false]
+022E  0293  spawner
+022E  0293  oak_stairs                            @stairs
+022E  0293  chest                                 @chest
+022E  0293  redstone_wire                         east=up,side,[none] north=up,side,[none] power=[0]..15 south=up,side,[none] west=up,side,[none]
+022E  0293  diamond_ore
+022E  0293  diamond_block
+022E  0293  crafting_table
+022E  0293  wheat                                 @age7
+022E  0293  farmland                              moisture=[0]..7
+022E  0293  furnace                               @furnace
+022E  0293  oak_sign                              @sign
+022E  0293  spruce_sign                           @sign
+022E  0293  birch_sign                            @sign
+022E  0293  acacia_sign                           @sign
+022E  0293  jungle_sign                           @sign
+022E  0293  dark_oak_sign                         @sign
+022E  0293  oak_door                              @door
+022E  0293  ladder                                @facing4 @waterlogged
+022E  0293  rail                                  shape=[north_south],east_west,ascending_east,ascending_west,ascending_north,ascending_south,south_east,south_west,north_west,north_east
+022E  0293  cobblestone_stairs                    @stairs
+022E  0293  oak_wall_sign                         @wall_sign
+022E  0293  spruce_wall_sign                      @wall_sign
+022E  0293  birch_wall_sign                       @wall_sign
+022E  0293  acacia_wall_sign                      @wall_sign
+022E  0293  jungle_wall_sign                      @wall_sign
+022E  0293  dark_oak_wall_sign                    @wall_sign
+022E  0293  lever                                 @button
+022E  0293  stone_pressure_plate                  @powered
+022E  0293  iron_door                             @door
+022E  0293  oak_pressure_plate                    @powered
+022E  0293  spruce_pressure_plate                 @powered
+022E  0293  birch_pressure_plate                  @powered
+022E  0293  jungle_pressure_plate                 @powered
+022E  0293  acacia_pressure_plate                 @powered
+022E  0293  dark_oak_pressure_plate               @powered
+022E  0293  redstone_ore                          @lit
+022E  0293  redstone_torch                        lit=[true],false
+022E  0293  redstone_wall_torch                   @facing4 lit=[true],false
+022E  0293  stone_button                          @button
+022E  0293  snow                                  layers=[1]..8
+022E  0293  ice
+022E  0293  snow_block
+022E  0293  cactus                                age=[0]..15
+022E  0293  clay
+022E  0293  sugar_cane                            age=[0]..15
+022E  0293  jukebox                               has_record=true,[false]
+022E  0293  oak_fence                             @fence
+022E  0293  pumpkin
+022E  0293  netherrack
+022E  0293  soul_sand
+022E  0293  glowstone
+022E  0293  nether_portal                         axis=[x],z
+022E  0293  carved_pumpkin                        @facing4
+022E  0293  jack_o_lantern                        @facing4
+022E  0293  cake                                  bites=[0]..6
+022E  0293  repeater                              delay=[1]..4 @facing4 locked=true,[false] @powered
+022E  0293  white_stained_glass
+022E  0293  orange_stained_glass
+022E  0293  magenta_stained_glass
+022E  0293  light_blue_stained_glass
+022E  0293  yellow_stained_glass
+022E  0293  lime_stained_glass
+022E  0293  pink_stained_glass
+022E  0293  gray_stained_glass
+022E  0293  light_gray_stained_glass
+022E  0293  cyan_stained_glass
+022E  0293  purple_stained_glass
+022E  0293  blue_stained_glass
+022E  0293  brown_stained_glass
+022E  0293  green_stained_glass
+022E  0293  red_stained_glass
+022E  0293  black_stained_glass
+022E  0293  oak_trapdoor                          @trapdoor
+022E  0293  spruce_trapdoor                       @trapdoor
+022E  0293  birch_trapdoor                        @trapdoor
+022E  0293  jungle_trapdoor                       @trapdoor
+022E  0293  acacia_trapdoor                       @trapdoor
+022E  0293  dark_oak_trapdoor                     @trapdoor
+022E  0293  stone_bricks
+022E  0293  mossy_stone_bricks
+022E  0293  cracked_stone_bricks
+022E  0293  chiseled_stone_bricks
+022E  0293  infested_stone
+022E  0293  infested_cobblestone
+022E  0293  infested_stone_bricks
+022E  0293  infested_mossy_stone_bricks
+022E  0293  infested_cracked_stone_bricks
+022E  0293  infested_chiseled_stone_bricks
+022E  0293  brown_mushroom_block                  @mushroom
+022E  0293  red_mushroom_block                    @mushroom
+022E  0293  mushroom_stem                         @mushroom
+022E  0293  iron_bars                             @fence
+022E  0293  glass_pane                            @fence
+022E  0293  melon
+022E  0293  attached_pumpkin_stem                 @facing4
+022E  0293  attached_melon_stem                   @facing4
+022E  0293  pumpkin_stem                          @age7
+022E  0293  melon_stem                            @age7
+022E  0293  vine                                  east=true,[false] north=true,[false] south=true,[false] up=true,[false] west=true,[false]
+022E  0293  oak_fence_gate                        @fence_gate
+022E  0293  brick_stairs                          @stairs
+022E  0293  stone_brick_stairs                    @stairs
+022E  0293  mycelium                              @snowy
+022E  0293  lily_pad
+022E  0293  nether_bricks
+022E  0293  nether_brick_fence                    @fence
+022E  0293  nether_brick_stairs                   @stairs
+022E  0293  nether_wart                           @age3
+022E  0293  enchanting_table
+022E  0293  brewing_stand                         has_bottle_0=true,[false] has_bottle_1=true,[false] has_bottle_2=true,[false]
+022E  0293  cauldron                              level=[0]..3
+022E  0293  end_portal
+022E  0293  end_portal_frame                      eye=true,[false] @facing4
+022E  0293  end_stone
+022E  0293  dragon_egg
+022E  0293  redstone_lamp                         @lit
+022E  0293  cocoa                                 age=[0]..2 @facing4
+022E  0293  sandstone_stairs                      @stairs
+022E  0293  emerald_ore
+022E  0293  ender_chest                           @facing4 @waterlogged
+022E  0293  tripwire_hook                         attached=true,[false] @facing4 @powered
+022E  0293  tripwire                              attached=true,[false] disarmed=true,[false] east=true,[false] north=true,[false] @powered south=true,[false] west=true,[false]
+022E  0293  emerald_block
+022E  0293  spruce_stairs                         @stairs
+022E  0293  birch_stairs                          @stairs
+022E  0293  jungle_stairs                         @stairs
+022E  0293  command_block                         @command
+022E  0293  beacon
+022E  0293  cobblestone_wall                      @wall
+022E  0293  mossy_cobblestone_wall                @wall
+022E  0293  flower_pot
+022E  0293  potted_oak_sapling
+022E  0293  potted_spruce_sapling
+022E  0293  potted_birch_sapling
+022E  0293  potted_jungle_sapling
+022E  0293  potted_acacia_sapling
+022E  0293  potted_dark_oak_sapling
+022E  0293  potted_fern
+022E  0293  potted_dandelion
+022E  0293  potted_poppy
+022E  0293  potted_blue_orchid
+022E  0293  potted_allium
+022E  0293  potted_azure_bluet
+022E  0293  potted_red_tulip
+022E  0293  potted_orange_tulip
+022E  0293  potted_white_tulip
+022E  0293  potted_pink_tulip
+022E  0293  potted_oxeye_daisy
+022E  0293  potted_cornflower
+022E  0293  potted_lily_of_the_valley
+022E  0293  potted_wither_rose
+022E  0293  potted_red_mushroom
+022E  0293  potted_brown_mushroom
+022E  0293  potted_dead_bush
+022E  0293  potted_cactus
+022E  0293  carrots                               @age7
+022E  0293  potatoes                              @age7
+022E  0293  oak_button                            @button
+022E  0293  spruce_button                         @button
+022E  0293  birch_button                          @button
+022E  0293  jungle_button                         @button
+022E  0293  acacia_button                         @button
+022E  0293  dark_oak_button                       @button
+022E  0293  skeleton_skull                        @rotation
+022E  0293  skeleton_wall_skull                   @facing4
+022E  0293  wither_skeleton_skull                 @rotation
+022E  0293  wither_skeleton_wall_skull            @facing4
+022E  0293  zombie_head                           @rotation
+022E  0293  zombie_wall_head                      @facing4
+022E  0293  player_head                           @rotation
+022E  0293  player_wall_head                      @facing4
+022E  0293  creeper_head                          @rotation
+022E  0293  creeper_wall_head                     @facing4
+022E  0293  dragon_head                           @rotation
+022E  0293  dragon_wall_head                      @facing4
+022E  0293  anvil                                 @facing4
+022E  0293  chipped_anvil                         @facing4
+022E  0293  damaged_anvil                         @facing4
+022E  0293  trapped_chest                         @chest
+022E  0293  light_weighted_pressure_plate         power=[0]..15
+022E  0293  heavy_weighted_pressure_plate         power=[0]..15
+022E  0293  comparator                            @facing4 mode=[compare],subtract @powered
+022E  0293  daylight_detector                     inverted=true,[false] power=[0]..15
+022E  0293  redstone_block
+022E  0293  nether_quartz_ore
+022E  0293  hopper                                enabled=[true],false facing=[down],north,south,west,east
+022E  0293  quartz_block
+022E  0293  chiseled_quartz_block
+022E  0293  quartz_pillar                         @axis
+022E  0293  quartz_stairs                         @stairs
+022E  0293  activator_rail                        @powered @rail6
+022E  0293  dropper                               @dispenser
+022E  0293  white_terracotta
+022E  0293  orange_terracotta
+022E  0293  magenta_terracotta
+022E  0293  light_blue_terracotta
+022E  0293  yellow_terracotta
+022E  0293  lime_terracotta
+022E  0293  pink_terracotta
+022E  0293  gray_terracotta
+022E  0293  light_gray_terracotta
+022E  0293  cyan_terracotta
+022E  0293  purple_terracotta
+022E  0293  blue_terracotta
+022E  0293  brown_terracotta
+022E  0293  green_terracotta
+022E  0293  red_terracotta
+022E  0293  black_terracotta
+022E  0293  white_stained_glass_pane              @fence
+022E  0293  orange_stained_glass_pane             @fence
+022E  0293  magenta_stained_glass_pane            @fence
+022E  0293  light_blue_stained_glass_pane         @fence
+022E  0293  yellow_stained_glass_pane             @fence
+022E  0293  lime_stained_glass_pane               @fence
+022E  0293  pink_stained_glass_pane               @fence
+022E  0293  gray_stained_glass_pane               @fence
+022E  0293  light_gray_stained_glass_pane         @fence
+022E  0293  cyan_stained_glass_pane               @fence
+022E  0293  purple_stained_glass_pane             @fence
+022E  0293  blue_stained_glass_pane               @fence
+022E  0293  brown_stained_glass_pane              @fence
+022E  0293  green_stained_glass_pane              @fence
+022E  0293  red_stained_glass_pane                @fence
+022E  0293  black_stained_glass_pane              @fence
+022E  0293  acacia_stairs                         @stairs
+022E  0293  dark_oak_stairs                       @stairs
+022E  0293  slime_block
+022E  0293  barrier
+022E  0293  iron_trapdoor                         @trapdoor
+022E  0293  prismarine
+022E  0293  prismarine_bricks
+022E  0293  dark_prismarine
+022E  0293  prismarine_stairs                     @stairs
+022E  0293  prismarine_brick_stairs               @stairs
+022E  0293  dark_prismarine_stairs                @stairs
+022E  0293  prismarine_slab                       @slab
+022E  0293  prismarine_brick_slab                 @slab
+022E  0293  dark_prismarine_slab                  @slab
+022E  0293  sea_lantern
+022E  0293  hay_block                             @axis
+022E  0293  white_carpet
+022E  0293  orange_carpet
+022E  0293  magenta_carpet
+022E  0293  light_blue_carpet
+022E  0293  yellow_carpet
+022E  0293  lime_carpet
+022E  0293  pink_carpet
+022E  0293  gray_carpet
+022E  0293  light_gray_carpet
+022E  0293  cyan_carpet
+022E  0293  purple_carpet
+022E  0293  blue_carpet
+022E  0293  brown_carpet
+022E  0293  green_carpet
+022E  0293  red_carpet
+022E  0293  black_carpet
+022E  0293  terracotta
+022E  0293  coal_block
+022E  0293  packed_ice
+022E  0293  sunflower                             @tall_plant
+022E  0293  lilac                                 @tall_plant
+022E  0293  rose_bush                             @tall_plant
+022E  0293  peony                                 @tall_plant
+022E  0293  tall_grass                            @tall_plant
+022E  0293  large_fern                            @tall_plant
+022E  0293  white_banner                          @rotation
+022E  0293  orange_banner                         @rotation
+022E  0293  magenta_banner                        @rotation
+022E  0293  light_blue_banner                     @rotation
+022E  0293  yellow_banner                         @rotation
+022E  0293  lime_banner                           @rotation
+022E  0293  pink_banner                           @rotation
+022E  0293  gray_banner                           @rotation
+022E  0293  light_gray_banner                     @rotation
+022E  0293  cyan_banner                           @rotation
+022E  0293  purple_banner                         @rotation
+022E  0293  blue_banner                           @rotation
+022E  0293  brown_banner                          @rotation
+022E  0293  green_banner                          @rotation
+022E  0293  red_banner                            @rotation
+022E  0293  black_banner                          @rotation
+022E  0293  white_wall_banner                     @facing4
+022E  0293  orange_wall_banner                    @facing4
+022E  0293  magenta_wall_banner                   @facing4
+022E  0293  light_blue_wall_banner                @facing4
+022E  0293  yellow_wall_banner                    @facing4
+022E  0293  lime_wall_banner                      @facing4
+022E  0293  pink_wall_banner                      @facing4
+022E  0293  gray_wall_banner                      @facing4
+022E  0293  light_gray_wall_banner                @facing4
+022E  0293  cyan_wall_banner                      @facing4
+022E  0293  purple_wall_banner                    @facing4
+022E  0293  blue_wall_banner                      @facing4
+022E  0293  brown_wall_banner                     @facing4
+022E  0293  green_wall_banner                     @facing4
+022E  0293  red_wall_banner                       @facing4
+022E  0293  black_wall_banner                     @facing4
+022E  0293  red_sandstone
+022E  0293  chiseled_red_sandstone
+022E  0293  cut_red_sandstone
+022E  0293  red_sandstone_stairs                  @stairs
+022E  0293  oak_slab                              @slab
+022E  0293  spruce_slab                           @slab
+022E  0293  birch_slab                            @slab
+022E  0293  jungle_slab                           @slab
+022E  0293  acacia_slab                           @slab
+022E  0293  dark_oak_slab                         @slab
+022E  0293  stone_slab                            @slab
+022E  0293  smooth_stone_slab                     @slab
+022E  0293  sandstone_slab                        @slab
+022E  0293  cut_sandstone_slab                    @slab
+022E  0293  petrified_oak_slab                    @slab
+022E  0293  cobblestone_slab                      @slab
+022E  0293  brick_slab                            @slab
+022E  0293  stone_brick_slab                      @slab
+022E  0293  nether_brick_slab                     @slab
+022E  0293  quartz_slab                           @slab
+022E  0293  red_sandstone_slab                    @slab
+022E  0293  cut_red_sandstone_slab                @slab
+022E  0293  purpur_slab                           @slab
+022E  0293  smooth_stone
+022E  0293  smooth_sandstone
+022E  0293  smooth_quartz
+022E  0293  smooth_red_sandstone
+022E  0293  spruce_fence_gate                     @fence_gate
+022E  0293  birch_fence_gate                      @fence_gate
+022E  0293  jungle_fence_gate                     @fence_gate
+022E  0293  acacia_fence_gate                     @fence_gate
+022E  0293  dark_oak_fence_gate                   @fence_gate
+022E  0293  spruce_fence                          @fence
+022E  0293  birch_fence                           @fence
+022E  0293  jungle_fence                          @fence
+022E  0293  acacia_fence                          @fence
+022E  0293  dark_oak_fence                        @fence
+022E  0293  spruce_door                           @door
+022E  0293  birch_door                            @door
+022E  0293  jungle_door                           @door
+022E  0293  acacia_door                           @door
+022E  0293  dark_oak_door                         @door
+022E  0293  end_rod                               facing=north,east,south,west,[up],down
+022E  0293  chorus_plant                          down=true,[false] east=true,[false] north=true,[false] south=true,[false] up=true,[false] west=true,[false]
+022E  0293  chorus_flower                         age=[0]..5
+022E  0293  purpur_block
+022E  0293  purpur_pillar                         @axis
+022E  0293  purpur_stairs                         @stairs
+022E  0293  end_stone_bricks
+022E  0293  beetroots                             @age3
+022E  0293  grass_path
+022E  0293  end_gateway
+022E  0293  repeating_command_block               @command
+022E  0293  chain_command_block                   @command
+022E  0293  frosted_ice                           @age3
+022E  0293  magma_block
+022E  0293  nether_wart_block
+022E  0293  red_nether_bricks
+022E  0293  bone_block                            @axis
+022E  0293  structure_void
+022E  0293  observer                              facing=north,east,[south],west,up,down @powered
+022E  0293  shulker_box                           facing=north,east,south,west,[up],down
+022E  0293  white_shulker_box                     facing=north,east,south,west,[up],down
+022E  0293  orange_shulker_box                    facing=north,east,south,west,[up],down
+022E  0293  magenta_shulker_box                   facing=north,east,south,west,[up],down
+022E  0293  light_blue_shulker_box                facing=north,east,south,west,[up],down
+022E  0293  yellow_shulker_box                    facing=north,east,south,west,[up],down
+022E  0293  lime_shulker_box                      facing=north,east,south,west,[up],down
+022E  0293  pink_shulker_box                      facing=north,east,south,west,[up],down
+022E  0293  gray_shulker_box                      facing=north,east,south,west,[up],down
+022E  0293  light_gray_shulker_box                facing=north,east,south,west,[up],down
+022E  0293  cyan_shulker_box                      facing=north,east,south,west,[up],down
+022E  0293  purple_shulker_box                    facing=north,east,south,west,[up],down
+022E  0293  blue_shulker_box                      facing=north,east,south,west,[up],down
+022E  0293  brown_shulker_box                     facing=north,east,south,west,[up],down
+022E  0293  green_shulker_box                     facing=north,east,south,west,[up],down
+022E  0293  red_shulker_box                       facing=north,east,south,west,[up],down
+022E  0293  black_shulker_box                     facing=north,east,south,west,[up],down
+022E  0293  white_glazed_terracotta               @facing4
+022E  0293  orange_glazed_terracotta              @facing4
+022E  0293  magenta_glazed_terracotta             @facing4
+022E  0293  light_blue_glazed_terracotta          @facing4
+022E  0293  yellow_glazed_terracotta              @facing4
+022E  0293  lime_glazed_terracotta                @facing4
+022E  0293  pink_glazed_terracotta                @facing4
+022E  0293  gray_glazed_terracotta                @facing4
+022E  0293  light_gray_glazed_terracotta          @facing4
+022E  0293  cyan_glazed_terracotta                @facing4
+022E  0293  purple_glazed_terracotta              @facing4
+022E  0293  blue_glazed_terracotta                @facing4
+022E  0293  brown_glazed_terracotta               @facing4
+022E  0293  green_glazed_terracotta               @facing4
+022E  0293  red_glazed_terracotta                 @facing4
+022E  0293  black_glazed_terracotta               @facing4
+022E  0293  white_concrete
+022E  0293  orange_concrete
+022E  0293  magenta_concrete
+022E  0293  light_blue_concrete
+022E  0293  yellow_concrete
+022E  0293  lime_concrete
+022E  0293  pink_concrete
+022E  0293  gray_concrete
+022E  0293  light_gray_concrete
+022E  0293  cyan_concrete
+022E  0293  purple_concrete
+022E  0293  blue_concrete
+022E  0293  brown_concrete
+022E  0293  green_concrete
+022E  0293  red_concrete
+022E  0293  black_concrete
+022E  0293  white_concrete_powder
+022E  0293  orange_concrete_powder
+022E  0293  magenta_concrete_powder
+022E  0293  light_blue_concrete_powder
+022E  0293  yellow_concrete_powder
+022E  0293  lime_concrete_powder
+022E  0293  pink_concrete_powder
+022E  0293  gray_concrete_powder
+022E  0293  light_gray_concrete_powder
+022E  0293  cyan_concrete_powder
+022E  0293  purple_concrete_powder
+022E  0293  blue_concrete_powder
+022E  0293  brown_concrete_powder
+022E  0293  green_concrete_powder
+022E  0293  red_concrete_powder
+022E  0293  black_concrete_powder
+022E  0293  kelp                                  age=[0]..25
+022E  0293  kelp_plant
+022E  0293  dried_kelp_block
+022E  0293  turtle_egg                            eggs=[1]..4 hatch=[0]..2
+022E  0293  dead_tube_coral_block
+022E  0293  dead_brain_coral_block
+022E  0293  dead_bubble_coral_block
+022E  0293  dead_fire_coral_block
+022E  0293  dead_horn_coral_block
+022E  0293  tube_coral_block
+022E  0293  brain_coral_block
+022E  0293  bubble_coral_block
+022E  0293  fire_coral_block
+022E  0293  horn_coral_block
+022E  0293  dead_tube_coral                       @coral
+022E  0293  dead_brain_coral                      @coral
+022E  0293  dead_bubble_coral                     @coral
+022E  0293  dead_fire_coral                       @coral
+022E  0293  dead_horn_coral                       @coral
+022E  0293  tube_coral                            @coral
+022E  0293  brain_coral                           @coral
+022E  0293  bubble_coral                          @coral
+022E  0293  fire_coral                            @coral
+022E  0293  horn_coral                            @coral
+022E  0293  dead_tube_coral_fan                   @coral
+022E  0293  dead_brain_coral_fan                  @coral
+022E  0293  dead_bubble_coral_fan                 @coral
+022E  0293  dead_fire_coral_fan                   @coral
+022E  0293  dead_horn_coral_fan                   @coral
+022E  0293  tube_coral_fan                        @coral
+022E  0293  brain_coral_fan                       @coral
+022E  0293  bubble_coral_fan                      @coral
+022E  0293  fire_coral_fan                        @coral
+022E  0293  horn_coral_fan                        @coral
+022E  0293  dead_tube_coral_wall_fan              @coral_wall
+022E  0293  dead_brain_coral_wall_fan             @coral_wall
+022E  0293  dead_bubble_coral_wall_fan            @coral_wall
+022E  0293  dead_fire_coral_wall_fan              @coral_wall
+022E  0293  dead_horn_coral_wall_fan              @coral_wall
+022E  0293  tube_coral_wall_fan                   @coral_wall
+022E  0293  brain_coral_wall_fan                  @coral_wall
+022E  0293  bubble_coral_wall_fan                 @coral_wall
+022E  0293  fire_coral_wall_fan                   @coral_wall
+022E  0293  horn_coral_wall_fan                   @coral_wall
+022E  0293  sea_pickle                            pickles=[1]..4 waterlogged=[true],false
+022E  0293  blue_ice
+022E  0293  conduit                               waterlogged=[true],false
+022E  0293  bamboo_sapling
+022E  0293  bamboo                                age=[0]..1 leaves=[none],small,large stage=[0]..1
+022E  0293  potted_bamboo
+022E  0293  void_air
+022E  0293  cave_air
+022E  0293  bubble_column                         drag=[true],false
+022E  0293  polished_granite_stairs               @stairs
+022E  0293  smooth_red_sandstone_stairs           @stairs
+022E  0293  mossy_stone_brick_stairs              @stairs
+022E  0293  polished_diorite_stairs               @stairs
+022E  0293  mossy_cobblestone_stairs              @stairs
+022E  0293  end_stone_brick_stairs                @stairs
+022E  0293  stone_stairs                          @stairs
+022E  0293  smooth_sandstone_stairs               @stairs
+022E  0293  smooth_quartz_stairs                  @stairs
+022E  0293  granite_stairs                        @stairs
+022E  0293  andesite_stairs                       @stairs
+022E  0293  red_nether_brick_stairs               @stairs
+022E  0293  polished_andesite_stairs              @stairs
+022E  0293  diorite_stairs                        @stairs
+022E  0293  polished_granite_slab                 @slab
+022E  0293  smooth_red_sandstone_slab             @slab
+022E  0293  mossy_stone_brick_slab                @slab
+022E  0293  polished_diorite_slab                 @slab
+022E  0293  mossy_cobblestone_slab                @slab
+022E  0293  end_stone_brick_slab                  @slab
+022E  0293  smooth_sandstone_slab                 @slab
+022E  0293  smooth_quartz_slab                    @slab
+022E  0293  granite_slab                          @slab
+022E  0293  andesite_slab                         @slab
+022E  0293  red_nether_brick_slab                 @slab
+022E  0293  polished_andesite_slab                @slab
+022E  0293  diorite_slab                          @slab
+022E  0293  brick_wall                            @wall
+022E  0293  prismarine_wall                       @wall
+022E  0293  red_sandstone_wall                    @wall
+022E  0293  mossy_stone_brick_wall                @wall
+022E  0293  granite_wall                          @wall
+022E  0293  stone_brick_wall                      @wall
+022E  0293  nether_brick_wall                     @wall
+022E  0293  andesite_wall                         @wall
+022E  0293  red_nether_brick_wall                 @wall
+022E  0293  sandstone_wall                        @wall
+022E  0293  end_stone_brick_wall                  @wall
+022E  0293  diorite_wall                          @wall
+022E  0293  scaffolding                           bottom=true,[false] distance=0..[7] @waterlogged
+022E  0293  loom                                  @facing4
+022E  0293  barrel                                @facing6 open=true,[false]
+022E  0293  smoker                                @furnace
+022E  0293  blast_furnace                         @furnace
+022E  0293  cartography_table
+022E  0293  fletching_table
+022E  0293  grindstone                            face=floor,[wall],ceiling @facing4
+022E  0293  lectern                               @facing4 has_book=true,[false] @powered
+022E  0293  smithing_table
+022E  0293  stonecutter                           @facing4
+# Bells can be powered from 1.15
+022E  0285  bell                                  attachment=[floor],ceiling,single_wall,double_wall @facing4
+0286  0293  bell                                  attachment=[floor],ceiling,single_wall,double_wall @facing4 @powered
+
+022E  0293  lantern                               hanging=true,[false]
+022E  0293  campfire                              @facing4 lit=[true],false signal_fire=true,[false] @waterlogged
+022E  0293  sweet_berry_bush                      @age3
+022E  0293  structure_block                       mode=[save],load,corner,data
+022E  0293  jigsaw                                facing=north,east,south,west,[up],down
+022E  0293  composter                             level=[0]..8
+
+# Added in 1.15
+0286  0293  bee_nest                              @facing4 honey_level=[0]..5
+0286  0293  beehive                               @facing4 honey_level=[0]..5
+0286  0293  honey_block
+0286  0293  honeycomb_block
+`
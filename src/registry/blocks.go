@@ -0,0 +1,456 @@
+package registry
+
+import "fmt"
+import "sort"
+import "strconv"
+import "strings"
+
+// A block and the values of its properties, such as minecraft:grass_block[snowy=false].
+type BlockState struct {
+	Name string `nbt:"Name"`
+	Properties map[string]string `nbt:"Properties,omitempty"`
+}
+
+// Formats the state like vanilla commands do, with the properties in alphabetical order.
+func (state BlockState) String() string {
+	if len(state.Properties) == 0 {
+		return state.Name
+	}
+
+	names := make([]string, 0, len(state.Properties))
+	for name := range state.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	properties := make([]string, len(names))
+	for i, name := range names {
+		properties[i] = name + "=" + state.Properties[name]
+	}
+
+	return state.Name + "[" + strings.Join(properties, ",") + "]"
+}
+
+// Returned when text cannot be parsed as a block state.
+type MalformedBlockStateError struct {
+	details string
+}
+
+func (err MalformedBlockStateError) Error() string {
+	return fmt.Sprintf("Malformed block state: %s", err.details)
+}
+
+// Parses a block state written like vanilla commands do, such as minecraft:grass_block[snowy=false] or oak_log.
+// Names without a namespace are given the minecraft namespace.
+func ParseBlockState(text string) (result BlockState, err error) {
+	name := text
+	var properties string
+
+	if i := strings.Index(text, "["); i >= 0 {
+		if !strings.HasSuffix(text, "]") {
+			err = MalformedBlockStateError { fmt.Sprintf("Missing ] in %q", text) }
+			return
+		}
+
+		name = text[:i]
+		properties = text[i + 1:len(text) - 1]
+	}
+
+	if name == "" || strings.ContainsAny(name, " ]=,") {
+		err = MalformedBlockStateError { fmt.Sprintf("Invalid name in %q", text) }
+		return
+	}
+
+	if !strings.Contains(name, ":") {
+		name = namespace + name
+	}
+	result.Name = name
+
+	if strings.TrimSpace(properties) == "" {
+		return
+	}
+
+	result.Properties = make(map[string]string)
+	for _, property := range strings.Split(properties, ",") {
+		parts := strings.Split(property, "=")
+		if len(parts) != 2 {
+			err = MalformedBlockStateError { fmt.Sprintf("Invalid property %q in %q", property, text) }
+			return
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if key == "" || value == "" {
+			err = MalformedBlockStateError { fmt.Sprintf("Invalid property %q in %q", property, text) }
+			return
+		}
+
+		if _, ok := result.Properties[key]; ok {
+			err = MalformedBlockStateError { fmt.Sprintf("Property %s given twice in %q", key, text) }
+			return
+		}
+
+		result.Properties[key] = value
+	}
+
+	return
+}
+
+type blockProperty struct {
+	name string
+	values []string
+	defaultValue int
+}
+
+func (property blockProperty) valueIndex(value string) (int, bool) {
+	for i, allowed := range property.values {
+		if allowed == value {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// A block, and the ids of its states in a range of versions.
+type block struct {
+	name string
+	// In alphabetical order, the last of which changes fastest between consecutive ids.
+	properties []blockProperty
+	firstId uint32
+	stateCount uint32
+}
+
+// The blocks of a range of versions, in the order of their ids.
+type blockPart struct {
+	versions versionRange
+	blocks []*block
+	byName map[string]*block
+	stateCount uint32
+}
+
+// Maps block states to their ids in the global palette, as sent in chunk data and block changes.
+// Implements anvil.GlobalPalette.
+type BlockRegistry struct {
+	parts []blockPart
+}
+
+// Every block of the versions this package knows about.
+var Blocks = newBlockRegistry(blockTable)
+
+type blockEntry struct {
+	versions versionRange
+	name string
+	properties []blockProperty
+}
+
+// The table is part of the source, so any mistake in it is a bug in this package.
+func newBlockRegistry(table string) *BlockRegistry {
+	propertySets := make(map[string][]blockProperty)
+	var entries []blockEntry
+	var ranges []versionRange
+
+	for lineNumber, fields := range tableLines(table) {
+		if len(fields) == 0 {
+			continue
+		}
+
+		invalid := func(reason string) {
+			invalidTableLine("block", lineNumber, reason)
+		}
+
+		// A set of properties shared by several blocks
+		if strings.HasPrefix(fields[0], "@") {
+			if _, ok := propertySets[fields[0]]; ok {
+				invalid("property set defined twice")
+			}
+
+			properties, reason := parseBlockProperties(fields[1:], propertySets)
+			if reason != "" {
+				invalid(reason)
+			}
+
+			propertySets[fields[0]] = properties
+			continue
+		}
+
+		if len(fields) < 3 {
+			invalid("expected at least 3 fields")
+		}
+
+		versions, ok := parseVersionRange(fields[0], fields[1])
+		if !ok {
+			invalid("invalid versions")
+		}
+
+		properties, reason := parseBlockProperties(fields[3:], propertySets)
+		if reason != "" {
+			invalid(reason)
+		}
+
+		sort.Slice(properties, func(i int, j int) bool { return properties[i].name < properties[j].name })
+		entries = append(entries, blockEntry { versions, namespace + fields[2], properties })
+		ranges = append(ranges, versions)
+	}
+
+	registry := &BlockRegistry {}
+	for _, versions := range splitVersionRanges(ranges) {
+		part := blockPart {
+			versions: versions,
+			byName: make(map[string]*block),
+		}
+
+		for _, entry := range entries {
+			if !entry.versions.contains(versions.firstProtocol) {
+				continue
+			}
+
+			if _, ok := part.byName[entry.name]; ok {
+				panic(fmt.Sprintf("Internal package bug: block table: %s listed twice", entry.name))
+			}
+
+			stateCount := uint32(1)
+			for _, property := range entry.properties {
+				stateCount *= uint32(len(property.values))
+			}
+
+			block := &block {
+				name: entry.name,
+				properties: entry.properties,
+				firstId: part.stateCount,
+				stateCount: stateCount,
+			}
+
+			part.blocks = append(part.blocks, block)
+			part.byName[block.name] = block
+			part.stateCount += stateCount
+		}
+
+		registry.parts = append(registry.parts, part)
+	}
+
+	return registry
+}
+
+// Parses properties such as facing=[north],south,west,east or age=[0]..15, where the default value is in brackets,
+// and the names of property sets. Returns the reason the properties are invalid, if they are.
+func parseBlockProperties(fields []string, propertySets map[string][]blockProperty) (result []blockProperty, reason string) {
+	names := make(map[string]bool)
+	add := func(property blockProperty) {
+		if names[property.name] {
+			reason = "property " + property.name + " given twice"
+		}
+		names[property.name] = true
+		result = append(result, property)
+	}
+
+	for _, field := range fields {
+		if strings.HasPrefix(field, "@") {
+			properties, ok := propertySets[field]
+			if !ok {
+				return nil, "unknown property set " + field
+			}
+
+			for _, property := range properties {
+				add(property)
+			}
+			continue
+		}
+
+		parts := strings.Split(field, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, "invalid property " + field
+		}
+
+		property := blockProperty { name: parts[0], defaultValue: -1 }
+		for _, item := range strings.Split(parts[1], ",") {
+			bounds := strings.Split(item, "..")
+			if len(bounds) > 2 {
+				return nil, "invalid value " + item
+			}
+
+			// The first or last value of a range may be the default
+			defaultBound := -1
+			for i, bound := range bounds {
+				if strings.HasPrefix(bound, "[") && strings.HasSuffix(bound, "]") {
+					if defaultBound >= 0 || property.defaultValue >= 0 {
+						return nil, "two default values for " + property.name
+					}
+
+					bounds[i] = bound[1:len(bound) - 1]
+					defaultBound = i
+				}
+
+				if bounds[i] == "" {
+					return nil, "invalid value " + item
+				}
+			}
+
+			values := bounds
+			if len(bounds) == 2 {
+				first, err := strconv.Atoi(bounds[0])
+				if err != nil {
+					return nil, "invalid range " + item
+				}
+
+				last, err := strconv.Atoi(bounds[1])
+				if err != nil || last < first {
+					return nil, "invalid range " + item
+				}
+
+				values = nil
+				for value := first; value <= last; value++ {
+					values = append(values, strconv.Itoa(value))
+				}
+			}
+
+			switch defaultBound {
+			case 0:
+				property.defaultValue = len(property.values)
+			case 1:
+				property.defaultValue = len(property.values) + len(values) - 1
+			}
+
+			property.values = append(property.values, values...)
+		}
+
+		if property.defaultValue < 0 {
+			property.defaultValue = 0
+		}
+
+		for i, value := range property.values {
+			if j, _ := property.valueIndex(value); j != i {
+				return nil, "value " + value + " given twice"
+			}
+		}
+
+		add(property)
+	}
+
+	return
+}
+
+func (registry *BlockRegistry) part(protocol uint) *blockPart {
+	for i := range registry.parts {
+		if registry.parts[i].versions.contains(protocol) {
+			return &registry.parts[i]
+		}
+	}
+
+	return nil
+}
+
+// Properties that are left out take their default values, so minecraft:grass_block is minecraft:grass_block[snowy=false].
+// Returns false for block states that do not exist in the version, including those with properties the block does not have.
+func (registry *BlockRegistry) BlockStateId(state BlockState, protocol uint) (id uint32, ok bool) {
+	part := registry.part(protocol)
+	if part == nil {
+		return
+	}
+
+	name, ok := normalizeName(state.Name)
+	if !ok {
+		return
+	}
+
+	block, ok := part.byName[name]
+	if !ok {
+		return
+	}
+
+	known := 0
+	offset := uint32(0)
+	for _, property := range block.properties {
+		index := property.defaultValue
+		if value, given := state.Properties[property.name]; given {
+			index, ok = property.valueIndex(value)
+			if !ok {
+				return
+			}
+			known++
+		}
+
+		offset = offset * uint32(len(property.values)) + uint32(index)
+	}
+
+	if known != len(state.Properties) {
+		return 0, false
+	}
+
+	return block.firstId + offset, true
+}
+
+// Returns the state with every property of the block, or false for ids that are not used by the version.
+func (registry *BlockRegistry) BlockState(id uint32, protocol uint) (state BlockState, ok bool) {
+	part := registry.part(protocol)
+	if part == nil || id >= part.stateCount {
+		return
+	}
+
+	i := sort.Search(len(part.blocks), func(i int) bool {
+		return part.blocks[i].firstId + part.blocks[i].stateCount > id
+	})
+	block := part.blocks[i]
+
+	state.Name = block.name
+	if len(block.properties) == 0 {
+		return state, true
+	}
+
+	state.Properties = make(map[string]string, len(block.properties))
+	offset := id - block.firstId
+	for j := len(block.properties) - 1; j >= 0; j-- {
+		property := block.properties[j]
+		count := uint32(len(property.values))
+		state.Properties[property.name] = property.values[offset % count]
+		offset /= count
+	}
+
+	return state, true
+}
+
+// The ids of every state of a block, which are consecutive. Returns false for blocks that do not exist in the version.
+func (registry *BlockRegistry) BlockStateIds(name string, protocol uint) (first uint32, last uint32, ok bool) {
+	part := registry.part(protocol)
+	if part == nil {
+		return
+	}
+
+	name, ok = normalizeName(name)
+	if !ok {
+		return
+	}
+
+	block, ok := part.byName[name]
+	if !ok {
+		return
+	}
+
+	return block.firstId, block.firstId + block.stateCount - 1, true
+}
+
+// Parses a block state like ParseBlockState and looks up its id.
+// Fails with an UnknownEntryError if the state does not exist in the version.
+func (registry *BlockRegistry) LookupId(text string, protocol uint) (uint32, error) {
+	state, err := ParseBlockState(text)
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := registry.BlockStateId(state, protocol)
+	if !ok {
+		return 0, UnknownEntryError { fmt.Sprintf("No block state %s in version %04X", state, protocol) }
+	}
+
+	return id, nil
+}
+
+// The number of block states in the version, all of which have ids below it. Returns false for unknown versions.
+func (registry *BlockRegistry) StateCount(protocol uint) (count uint32, ok bool) {
+	part := registry.part(protocol)
+	if part == nil {
+		return
+	}
+
+	return part.stateCount, true
+}
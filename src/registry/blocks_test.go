@@ -0,0 +1,187 @@
+package registry
+
+import "testing"
+
+func TestParseBlockState(t *testing.T) {
+	iomap := []struct {
+		text string
+		output string
+	} {
+		{"minecraft:stone", "minecraft:stone"},
+		{"stone", "minecraft:stone"},
+		{"stone[]", "minecraft:stone"},
+		{"minecraft:oak_log[axis=x]", "minecraft:oak_log[axis=x]"},
+		{"oak_stairs[waterlogged=true, facing=east]", "minecraft:oak_stairs[facing=east,waterlogged=true]"},
+		{"other:block[a=b]", "other:block[a=b]"},
+	}
+
+	for i, mapping := range iomap {
+		state, err := ParseBlockState(mapping.text)
+		if err != nil || state.String() != mapping.output {
+			t.Errorf("Output incorrect for mapping %d: %s %v", i, state, err)
+		}
+	}
+
+	iemap := []string {
+		"",
+		"[axis=x]",
+		"oak_log[axis=x",
+		"oak_log[axis]",
+		"oak_log[axis=x,axis=y]",
+		"oak_log[=x]",
+	}
+
+	for i, text := range iemap {
+		_, err := ParseBlockState(text)
+		if _, ok := err.(MalformedBlockStateError); !ok {
+			t.Errorf("Expected MalformedBlockStateError for mapping %d but instead got: %v", i, err)
+		}
+	}
+}
+
+func TestBlockStateIds(t *testing.T) {
+	iomap := []struct {
+		protocol uint
+		text string
+		id uint32
+	} {
+		{0x022E, "minecraft:air", 0},
+		{0x022E, "stone", 1},
+		{0x022E, "minecraft:grass_block[snowy=true]", 8},
+		{0x022E, "minecraft:grass_block[snowy=false]", 9},
+		{0x022E, "minecraft:dirt", 10},
+		{0x022E, "minecraft:bedrock", 33},
+		{0x022E, "minecraft:water[level=0]", 34},
+		{0x022E, "minecraft:oak_log[axis=y]", 73},
+		{0x022E, "minecraft:glass", 230},
+		{0x022E, "minecraft:white_wool", 1383},
+		{0x022E, "minecraft:tnt[unstable=false]", 1430},
+		{0x022E, "minecraft:nether_portal[axis=z]", 4001},
+		{0x022E, "minecraft:bell[attachment=floor,facing=north]", 11198},
+		{0x022E, "minecraft:composter[level=8]", 11270},
+		{0x0286, "minecraft:grass_block[snowy=false]", 9},
+		{0x0286, "minecraft:bell[attachment=floor,facing=north,powered=true]", 11198},
+		{0x0286, "minecraft:composter[level=0]", 11278},
+		{0x0286, "minecraft:honeycomb_block", 11336},
+	}
+
+	for i, mapping := range iomap {
+		id, err := Blocks.LookupId(mapping.text, mapping.protocol)
+		if err != nil || id != mapping.id {
+			t.Errorf("Output incorrect for mapping %d: %d %v", i, id, err)
+			continue
+		}
+
+		state, ok := Blocks.BlockState(id, mapping.protocol)
+		parsed, _ := ParseBlockState(mapping.text)
+		if !ok || state.String() != parsed.String() {
+			t.Errorf("Reverse output incorrect for mapping %d: %s", i, state)
+		}
+	}
+}
+
+func TestDefaultBlockStates(t *testing.T) {
+	iomap := []struct {
+		text string
+		output string
+	} {
+		{"minecraft:grass_block", "minecraft:grass_block[snowy=false]"},
+		{"minecraft:oak_leaves", "minecraft:oak_leaves[distance=7,persistent=false]"},
+		{"minecraft:oak_stairs[facing=east]", "minecraft:oak_stairs[facing=east,half=bottom,shape=straight,waterlogged=false]"},
+		{"minecraft:redstone_wire", "minecraft:redstone_wire[east=none,north=none,power=0,south=none,west=none]"},
+		{"minecraft:hopper", "minecraft:hopper[enabled=true,facing=down]"},
+		{"minecraft:cobblestone_wall", "minecraft:cobblestone_wall[east=false,north=false,south=false,up=true,waterlogged=false,west=false]"},
+	}
+
+	for i, mapping := range iomap {
+		id, err := Blocks.LookupId(mapping.text, 0x022E)
+		if err != nil {
+			t.Errorf("Error for mapping %d: %v", i, err)
+			continue
+		}
+
+		if state, _ := Blocks.BlockState(id, 0x022E); state.String() != mapping.output {
+			t.Errorf("Output incorrect for mapping %d: %s", i, state)
+		}
+	}
+}
+
+func TestUnknownBlockStates(t *testing.T) {
+	iemap := []struct {
+		protocol uint
+		text string
+	} {
+		{0x022E, "minecraft:honey_block"},
+		{0x022E, "minecraft:bell[powered=false]"},
+		{0x022E, "minecraft:grass_block[snowy=maybe]"},
+		{0x022E, "minecraft:stone[snowy=false]"},
+		{0x022E, "other:stone"},
+		// 1.13.2 and 1.16 are not known
+		{0x0185, "minecraft:stone"},
+		{0x0286 + 0x0100, "minecraft:stone"},
+	}
+
+	for i, mapping := range iemap {
+		_, err := Blocks.LookupId(mapping.text, mapping.protocol)
+		if _, ok := err.(UnknownEntryError); !ok {
+			t.Errorf("Expected UnknownEntryError for mapping %d but instead got: %v", i, err)
+		}
+	}
+
+	if _, ok := Blocks.BlockState(11271, 0x022E); ok {
+		t.Error("Id after the last block state found")
+	}
+}
+
+func TestBlockStateCount(t *testing.T) {
+	for _, protocol := range []uint {0x022E, 0x0286} {
+		count, ok := Blocks.StateCount(protocol)
+		expected := map[uint]uint32 { 0x022E: 11271, 0x0286: 11337 }[protocol]
+		if !ok || count != expected {
+			t.Errorf("Count incorrect for %04X: %d", protocol, count)
+			continue
+		}
+
+		// Every id has a state, which has that id
+		for id := uint32(0); id < count; id++ {
+			state, ok := Blocks.BlockState(id, protocol)
+			if !ok {
+				t.Fatalf("No state for %d in %04X", id, protocol)
+			}
+
+			if result, ok := Blocks.BlockStateId(state, protocol); !ok || result != id {
+				t.Fatalf("Id of %s incorrect in %04X: %d", state, protocol, result)
+			}
+		}
+	}
+
+	first, last, ok := Blocks.BlockStateIds("minecraft:water", 0x022E)
+	if !ok || first != 34 || last != 49 {
+		t.Errorf("Water incorrect: %d %d", first, last)
+	}
+}
+
+func TestBlockTable(t *testing.T) {
+	iemap := []string {
+		"022E  0293  stone  facing=north,north",
+		"022E  0293  stone  age=[0]..[3]",
+		"022E  0293  stone  age=3..0",
+		"022E  0293  stone  @unknown",
+		"022E  0293  stone  age=1..3 age=1..3",
+		"022E  0293",
+		"0293  022E  stone",
+		"022E  0293  stone\n022E  0293  stone",
+	}
+
+	for i, table := range iemap {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for mapping %d", i)
+				}
+			}()
+
+			newBlockRegistry(table)
+		}()
+	}
+}
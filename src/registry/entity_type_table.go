@@ -0,0 +1,110 @@
+package registry
+
+// Versions are inclusive and use the numbering described in docs/protocol_versions.md.
+// Each entity type takes the id after the previous entity type.
+const entityTypeTable = `
+# first last  name
+022E  0293  area_effect_cloud
+022E  0293  armor_stand
+022E  0293  arrow
+022E  0293  bat
+0286  0293  bee
+022E  0293  blaze
+022E  0293  boat
+022E  0293  cat
+022E  0293  cave_spider
+022E  0293  chicken
+022E  0293  cod
+022E  0293  cow
+022E  0293  creeper
+022E  0293  donkey
+022E  0293  dolphin
+022E  0293  dragon_fireball
+022E  0293  drowned
+022E  0293  elder_guardian
+022E  0293  end_crystal
+022E  0293  ender_dragon
+022E  0293  enderman
+022E  0293  endermite
+022E  0293  evoker_fangs
+022E  0293  evoker
+022E  0293  experience_orb
+022E  0293  eye_of_ender
+022E  0293  falling_block
+022E  0293  firework_rocket
+022E  0293  fox
+022E  0293  ghast
+022E  0293  giant
+022E  0293  guardian
+022E  0293  horse
+022E  0293  husk
+022E  0293  illusioner
+022E  0293  item
+022E  0293  item_frame
+022E  0293  fireball
+022E  0293  leash_knot
+022E  0293  llama
+022E  0293  llama_spit
+022E  0293  magma_cube
+022E  0293  minecart
+022E  0293  chest_minecart
+022E  0293  command_block_minecart
+022E  0293  furnace_minecart
+022E  0293  hopper_minecart
+022E  0293  spawner_minecart
+022E  0293  tnt_minecart
+022E  0293  mule
+022E  0293  mooshroom
+022E  0293  ocelot
+022E  0293  painting
+022E  0293  panda
+022E  0293  parrot
+022E  0293  pig
+022E  0293  pufferfish
+022E  0293  zombie_pigman
+022E  0293  polar_bear
+022E  0293  tnt
+022E  0293  rabbit
+022E  0293  salmon
+022E  0293  sheep
+022E  0293  shulker
+022E  0293  shulker_bullet
+022E  0293  silverfish
+022E  0293  skeleton
+022E  0293  skeleton_horse
+022E  0293  slime
+022E  0293  small_fireball
+022E  0293  snow_golem
+022E  0293  snowball
+022E  0293  spectral_arrow
+022E  0293  spider
+022E  0293  squid
+022E  0293  stray
+022E  0293  trader_llama
+022E  0293  tropical_fish
+022E  0293  turtle
+022E  0293  egg
+022E  0293  ender_pearl
+022E  0293  experience_bottle
+022E  0293  potion
+022E  0293  vex
+022E  0293  villager
+022E  0293  iron_golem
+022E  0293  vindicator
+022E  0293  pillager
+022E  0293  wandering_trader
+022E  0293  witch
+022E  0293  wither
+022E  0293  wither_skeleton
+022E  0293  wither_skull
+022E  0293  wolf
+022E  0293  zombie
+022E  0293  zombie_horse
+022E  0293  zombie_villager
+022E  0293  phantom
+022E  0293  ravager
+022E  0293  lightning_bolt
+022E  0293  player
+022E  0293  fishing_bobber
+022E  0293  trident
+`
@@ -0,0 +1,893 @@
+package registry
+
+// Versions are inclusive and use the numbering described in docs/protocol_versions.md.
+// Each item takes the id after the previous item.
+const itemTable = `
+# first last  name
+022E  0293  air
+022E  0293  stone
+022E  0293  granite
+022E  0293  polished_granite
+022E  0293  diorite
+022E  0293  polished_diorite
+022E  0293  andesite
+022E  0293  polished_andesite
+022E  0293  grass_block
+022E  0293  dirt
+022E  0293  coarse_dirt
+022E  0293  podzol
+022E  0293  cobblestone
+022E  0293  oak_planks
+022E  0293  spruce_planks
+022E  0293  birch_planks
+022E  0293  jungle_planks
+022E  0293  acacia_planks
+022E  0293  dark_oak_planks
+022E  0293  oak_sapling
+022E  0293  spruce_sapling
+022E  0293  birch_sapling
+022E  0293  jungle_sapling
+022E  0293  acacia_sapling
+022E  0293  dark_oak_sapling
+022E  0293  bedrock
+022E  0293  sand
+022E  0293  red_sand
+022E  0293  gravel
+022E  0293  gold_ore
+022E  0293  iron_ore
+022E  0293  coal_ore
+022E  0293  oak_log
+022E  0293  spruce_log
+022E  0293  birch_log
+022E  0293  jungle_log
+022E  0293  acacia_log
+022E  0293  dark_oak_log
+022E  0293  stripped_oak_log
+022E  0293  stripped_spruce_log
+022E  0293  stripped_birch_log
+022E  0293  stripped_jungle_log
+022E  0293  stripped_acacia_log
+022E  0293  stripped_dark_oak_log
+022E  0293  stripped_oak_wood
+022E  0293  stripped_spruce_wood
+022E  0293  stripped_birch_wood
+022E  0293  stripped_jungle_wood
+022E  0293  stripped_acacia_wood
+022E  0293  stripped_dark_oak_wood
+022E  0293  oak_wood
+022E  0293  spruce_wood
+022E  0293  birch_wood
+022E  0293  jungle_wood
+022E  0293  acacia_wood
+022E  0293  dark_oak_wood
+022E  0293  oak_leaves
+022E  0293  spruce_leaves
+022E  0293  birch_leaves
+022E  0293  jungle_leaves
+022E  0293  acacia_leaves
+022E  0293  dark_oak_leaves
+022E  0293  sponge
+022E  0293  wet_sponge
+022E  0293  glass
+022E  0293  lapis_ore
+022E  0293  lapis_block
+022E  0293  dispenser
+022E  0293  sandstone
+022E  0293  chiseled_sandstone
+022E  0293  cut_sandstone
+022E  0293  note_block
+022E  0293  powered_rail
+022E  0293  detector_rail
+022E  0293  sticky_piston
+022E  0293  cobweb
+022E  0293  grass
+022E  0293  fern
+022E  0293  dead_bush
+022E  0293  seagrass
+022E  0293  sea_pickle
+022E  0293  piston
+022E  0293  white_wool
+022E  0293  orange_wool
+022E  0293  magenta_wool
+022E  0293  light_blue_wool
+022E  0293  yellow_wool
+022E  0293  lime_wool
+022E  0293  pink_wool
+022E  0293  gray_wool
+022E  0293  light_gray_wool
+022E  0293  cyan_wool
+022E  0293  purple_wool
+022E  0293  blue_wool
+022E  0293  brown_wool
+022E  0293  green_wool
+022E  0293  red_wool
+022E  0293  black_wool
+022E  0293  dandelion
+022E  0293  poppy
+022E  0293  blue_orchid
+022E  0293  allium
+022E  0293  azure_bluet
+022E  0293  red_tulip
+022E  0293  orange_tulip
+022E  0293  white_tulip
+022E  0293  pink_tulip
+022E  0293  oxeye_daisy
+022E  0293  cornflower
+022E  0293  lily_of_the_valley
+022E  0293  wither_rose
+022E  0293  brown_mushroom
+022E  0293  red_mushroom
+022E  0293  gold_block
+022E  0293  iron_block
+022E  0293  oak_slab
+022E  0293  spruce_slab
+022E  0293  birch_slab
+022E  0293  jungle_slab
+022E  0293  acacia_slab
+022E  0293  dark_oak_slab
+022E  0293  stone_slab
+022E  0293  smooth_stone_slab
+022E  0293  sandstone_slab
+022E  0293  cut_sandstone_slab
+022E  0293  petrified_oak_slab
+022E  0293  cobblestone_slab
+022E  0293  brick_slab
+022E  0293  stone_brick_slab
+022E  0293  nether_brick_slab
+022E  0293  quartz_slab
+022E  0293  red_sandstone_slab
+022E  0293  cut_red_sandstone_slab
+022E  0293  purpur_slab
+022E  0293  prismarine_slab
+022E  0293  prismarine_brick_slab
+022E  0293  dark_prismarine_slab
+022E  0293  smooth_quartz
+022E  0293  smooth_red_sandstone
+022E  0293  smooth_sandstone
+022E  0293  smooth_stone
+022E  0293  bricks
+022E  0293  tnt
+022E  0293  bookshelf
+022E  0293  mossy_cobblestone
+022E  0293  obsidian
+022E  0293  torch
+022E  0293  end_rod
+022E  0293  chorus_plant
+022E  0293  chorus_flower
+022E  0293  purpur_block
+022E  0293  purpur_pillar
+022E  0293  purpur_stairs
+022E  0293  spawner
+022E  0293  oak_stairs
+022E  0293  chest
+022E  0293  diamond_ore
+022E  0293  diamond_block
+022E  0293  crafting_table
+022E  0293  farmland
+022E  0293  furnace
+022E  0293  ladder
+022E  0293  rail
+022E  0293  cobblestone_stairs
+022E  0293  lever
+022E  0293  stone_pressure_plate
+022E  0293  oak_pressure_plate
+022E  0293  spruce_pressure_plate
+022E  0293  birch_pressure_plate
+022E  0293  jungle_pressure_plate
+022E  0293  acacia_pressure_plate
+022E  0293  dark_oak_pressure_plate
+022E  0293  redstone_ore
+022E  0293  redstone_torch
+022E  0293  stone_button
+022E  0293  snow
+022E  0293  ice
+022E  0293  snow_block
+022E  0293  cactus
+022E  0293  clay
+022E  0293  jukebox
+022E  0293  oak_fence
+022E  0293  spruce_fence
+022E  0293  birch_fence
+022E  0293  jungle_fence
+022E  0293  acacia_fence
+022E  0293  dark_oak_fence
+022E  0293  pumpkin
+022E  0293  carved_pumpkin
+022E  0293  netherrack
+022E  0293  soul_sand
+022E  0293  glowstone
+022E  0293  jack_o_lantern
+022E  0293  oak_trapdoor
+022E  0293  spruce_trapdoor
+022E  0293  birch_trapdoor
+022E  0293  jungle_trapdoor
+022E  0293  acacia_trapdoor
+022E  0293  dark_oak_trapdoor
+022E  0293  infested_stone
+022E  0293  infested_cobblestone
+022E  0293  infested_stone_bricks
+022E  0293  infested_mossy_stone_bricks
+022E  0293  infested_cracked_stone_bricks
+022E  0293  infested_chiseled_stone_bricks
+022E  0293  stone_bricks
+022E  0293  mossy_stone_bricks
+022E  0293  cracked_stone_bricks
+022E  0293  chiseled_stone_bricks
+022E  0293  brown_mushroom_block
+022E  0293  red_mushroom_block
+022E  0293  mushroom_stem
+022E  0293  iron_bars
+022E  0293  glass_pane
+022E  0293  melon
+022E  0293  vine
+022E  0293  oak_fence_gate
+022E  0293  spruce_fence_gate
+022E  0293  birch_fence_gate
+022E  0293  jungle_fence_gate
+022E  0293  acacia_fence_gate
+022E  0293  dark_oak_fence_gate
+022E  0293  brick_stairs
+022E  0293  stone_brick_stairs
+022E  0293  mycelium
+022E  0293  lily_pad
+022E  0293  nether_bricks
+022E  0293  nether_brick_fence
+022E  0293  nether_brick_stairs
+022E  0293  enchanting_table
+022E  0293  end_portal_frame
+022E  0293  end_stone
+022E  0293  end_stone_bricks
+022E  0293  dragon_egg
+022E  0293  redstone_lamp
+022E  0293  sandstone_stairs
+022E  0293  emerald_ore
+022E  0293  ender_chest
+022E  0293  tripwire_hook
+022E  0293  emerald_block
+022E  0293  spruce_stairs
+022E  0293  birch_stairs
+022E  0293  jungle_stairs
+022E  0293  command_block
+022E  0293  beacon
+022E  0293  cobblestone_wall
+022E  0293  mossy_cobblestone_wall
+022E  0293  brick_wall
+022E  0293  prismarine_wall
+022E  0293  red_sandstone_wall
+022E  0293  mossy_stone_brick_wall
+022E  0293  granite_wall
+022E  0293  stone_brick_wall
+022E  0293  nether_brick_wall
+022E  0293  andesite_wall
+022E  0293  red_nether_brick_wall
+022E  0293  sandstone_wall
+022E  0293  end_stone_brick_wall
+022E  0293  diorite_wall
+022E  0293  oak_button
+022E  0293  spruce_button
+022E  0293  birch_button
+022E  0293  jungle_button
+022E  0293  acacia_button
+022E  0293  dark_oak_button
+022E  0293  anvil
+022E  0293  chipped_anvil
+022E  0293  damaged_anvil
+022E  0293  trapped_chest
+022E  0293  light_weighted_pressure_plate
+022E  0293  heavy_weighted_pressure_plate
+022E  0293  daylight_detector
+022E  0293  redstone_block
+022E  0293  nether_quartz_ore
+022E  0293  hopper
+022E  0293  chiseled_quartz_block
+022E  0293  quartz_block
+022E  0293  quartz_pillar
+022E  0293  quartz_stairs
+022E  0293  activator_rail
+022E  0293  dropper
+022E  0293  white_terracotta
+022E  0293  orange_terracotta
+022E  0293  magenta_terracotta
+022E  0293  light_blue_terracotta
+022E  0293  yellow_terracotta
+022E  0293  lime_terracotta
+022E  0293  pink_terracotta
+022E  0293  gray_terracotta
+022E  0293  light_gray_terracotta
+022E  0293  cyan_terracotta
+022E  0293  purple_terracotta
+022E  0293  blue_terracotta
+022E  0293  brown_terracotta
+022E  0293  green_terracotta
+022E  0293  red_terracotta
+022E  0293  black_terracotta
+022E  0293  barrier
+022E  0293  iron_trapdoor
+022E  0293  hay_block
+022E  0293  white_carpet
+022E  0293  orange_carpet
+022E  0293  magenta_carpet
+022E  0293  light_blue_carpet
+022E  0293  yellow_carpet
+022E  0293  lime_carpet
+022E  0293  pink_carpet
+022E  0293  gray_carpet
+022E  0293  light_gray_carpet
+022E  0293  cyan_carpet
+022E  0293  purple_carpet
+022E  0293  blue_carpet
+022E  0293  brown_carpet
+022E  0293  green_carpet
+022E  0293  red_carpet
+022E  0293  black_carpet
+022E  0293  terracotta
+022E  0293  coal_block
+022E  0293  packed_ice
+022E  0293  acacia_stairs
+022E  0293  dark_oak_stairs
+022E  0293  slime_block
+022E  0293  grass_path
+022E  0293  sunflower
+022E  0293  lilac
+022E  0293  rose_bush
+022E  0293  peony
+022E  0293  tall_grass
+022E  0293  large_fern
+022E  0293  white_stained_glass
+022E  0293  orange_stained_glass
+022E  0293  magenta_stained_glass
+022E  0293  light_blue_stained_glass
+022E  0293  yellow_stained_glass
+022E  0293  lime_stained_glass
+022E  0293  pink_stained_glass
+022E  0293  gray_stained_glass
+022E  0293  light_gray_stained_glass
+022E  0293  cyan_stained_glass
+022E  0293  purple_stained_glass
+022E  0293  blue_stained_glass
+022E  0293  brown_stained_glass
+022E  0293  green_stained_glass
+022E  0293  red_stained_glass
+022E  0293  black_stained_glass
+022E  0293  white_stained_glass_pane
+022E  0293  orange_stained_glass_pane
+022E  0293  magenta_stained_glass_pane
+022E  0293  light_blue_stained_glass_pane
+022E  0293  yellow_stained_glass_pane
+022E  0293  lime_stained_glass_pane
+022E  0293  pink_stained_glass_pane
+022E  0293  gray_stained_glass_pane
+022E  0293  light_gray_stained_glass_pane
+022E  0293  cyan_stained_glass_pane
+022E  0293  purple_stained_glass_pane
+022E  0293  blue_stained_glass_pane
+022E  0293  brown_stained_glass_pane
+022E  0293  green_stained_glass_pane
+022E  0293  red_stained_glass_pane
+022E  0293  black_stained_glass_pane
+022E  0293  prismarine
+022E  0293  prismarine_bricks
+022E  0293  dark_prismarine
+022E  0293  prismarine_stairs
+022E  0293  prismarine_brick_stairs
+022E  0293  dark_prismarine_stairs
+022E  0293  sea_lantern
+022E  0293  red_sandstone
+022E  0293  chiseled_red_sandstone
+022E  0293  cut_red_sandstone
+022E  0293  red_sandstone_stairs
+022E  0293  repeating_command_block
+022E  0293  chain_command_block
+022E  0293  magma_block
+022E  0293  nether_wart_block
+022E  0293  red_nether_bricks
+022E  0293  bone_block
+022E  0293  structure_void
+022E  0293  observer
+022E  0293  shulker_box
+022E  0293  white_shulker_box
+022E  0293  orange_shulker_box
+022E  0293  magenta_shulker_box
+022E  0293  light_blue_shulker_box
+022E  0293  yellow_shulker_box
+022E  0293  lime_shulker_box
+022E  0293  pink_shulker_box
+022E  0293  gray_shulker_box
+022E  0293  light_gray_shulker_box
+022E  0293  cyan_shulker_box
+022E  0293  purple_shulker_box
+022E  0293  blue_shulker_box
+022E  0293  brown_shulker_box
+022E  0293  green_shulker_box
+022E  0293  red_shulker_box
+022E  0293  black_shulker_box
+022E  0293  white_glazed_terracotta
+022E  0293  orange_glazed_terracotta
+022E  0293  magenta_glazed_terracotta
+022E  0293  light_blue_glazed_terracotta
+022E  0293  yellow_glazed_terracotta
+022E  0293  lime_glazed_terracotta
+022E  0293  pink_glazed_terracotta
+022E  0293  gray_glazed_terracotta
+022E  0293  light_gray_glazed_terracotta
+022E  0293  cyan_glazed_terracotta
+022E  0293  purple_glazed_terracotta
+022E  0293  blue_glazed_terracotta
+022E  0293  brown_glazed_terracotta
+022E  0293  green_glazed_terracotta
+022E  0293  red_glazed_terracotta
+022E  0293  black_glazed_terracotta
+022E  0293  white_concrete
+022E  0293  orange_concrete
+022E  0293  magenta_concrete
+022E  0293  light_blue_concrete
+022E  0293  yellow_concrete
+022E  0293  lime_concrete
+022E  0293  pink_concrete
+022E  0293  gray_concrete
+022E  0293  light_gray_concrete
+022E  0293  cyan_concrete
+022E  0293  purple_concrete
+022E  0293  blue_concrete
+022E  0293  brown_concrete
+022E  0293  green_concrete
+022E  0293  red_concrete
+022E  0293  black_concrete
+022E  0293  white_concrete_powder
+022E  0293  orange_concrete_powder
+022E  0293  magenta_concrete_powder
+022E  0293  light_blue_concrete_powder
+022E  0293  yellow_concrete_powder
+022E  0293  lime_concrete_powder
+022E  0293  pink_concrete_powder
+022E  0293  gray_concrete_powder
+022E  0293  light_gray_concrete_powder
+022E  0293  cyan_concrete_powder
+022E  0293  purple_concrete_powder
+022E  0293  blue_concrete_powder
+022E  0293  brown_concrete_powder
+022E  0293  green_concrete_powder
+022E  0293  red_concrete_powder
+022E  0293  black_concrete_powder
+022E  0293  turtle_egg
+022E  0293  dead_tube_coral_block
+022E  0293  dead_brain_coral_block
+022E  0293  dead_bubble_coral_block
+022E  0293  dead_fire_coral_block
+022E  0293  dead_horn_coral_block
+022E  0293  tube_coral_block
+022E  0293  brain_coral_block
+022E  0293  bubble_coral_block
+022E  0293  fire_coral_block
+022E  0293  horn_coral_block
+022E  0293  tube_coral
+022E  0293  brain_coral
+022E  0293  bubble_coral
+022E  0293  fire_coral
+022E  0293  horn_coral
+022E  0293  dead_brain_coral
+022E  0293  dead_bubble_coral
+022E  0293  dead_fire_coral
+022E  0293  dead_horn_coral
+022E  0293  dead_tube_coral
+022E  0293  tube_coral_fan
+022E  0293  brain_coral_fan
+022E  0293  bubble_coral_fan
+022E  0293  fire_coral_fan
+022E  0293  horn_coral_fan
+022E  0293  dead_tube_coral_fan
+022E  0293  dead_brain_coral_fan
+022E  0293  dead_bubble_coral_fan
+022E  0293  dead_fire_coral_fan
+022E  0293  dead_horn_coral_fan
+022E  0293  blue_ice
+022E  0293  conduit
+022E  0293  polished_granite_stairs
+022E  0293  smooth_red_sandstone_stairs
+022E  0293  mossy_stone_brick_stairs
+022E  0293  polished_diorite_stairs
+022E  0293  mossy_cobblestone_stairs
+022E  0293  end_stone_brick_stairs
+022E  0293  stone_stairs
+022E  0293  smooth_sandstone_stairs
+022E  0293  smooth_quartz_stairs
+022E  0293  granite_stairs
+022E  0293  andesite_stairs
+022E  0293  red_nether_brick_stairs
+022E  0293  polished_andesite_stairs
+022E  0293  diorite_stairs
+022E  0293  polished_granite_slab
+022E  0293  smooth_red_sandstone_slab
+022E  0293  mossy_stone_brick_slab
+022E  0293  polished_diorite_slab
+022E  0293  mossy_cobblestone_slab
+022E  0293  end_stone_brick_slab
+022E  0293  smooth_sandstone_slab
+022E  0293  smooth_quartz_slab
+022E  0293  granite_slab
+022E  0293  andesite_slab
+022E  0293  red_nether_brick_slab
+022E  0293  polished_andesite_slab
+022E  0293  diorite_slab
+022E  0293  scaffolding
+022E  0293  iron_door
+022E  0293  oak_door
+022E  0293  spruce_door
+022E  0293  birch_door
+022E  0293  jungle_door
+022E  0293  acacia_door
+022E  0293  dark_oak_door
+022E  0293  repeater
+022E  0293  comparator
+022E  0293  structure_block
+022E  0293  jigsaw
+022E  0293  composter
+022E  0293  turtle_helmet
+022E  0293  scute
+022E  0293  iron_shovel
+022E  0293  iron_pickaxe
+022E  0293  iron_axe
+022E  0293  flint_and_steel
+022E  0293  apple
+022E  0293  bow
+022E  0293  arrow
+022E  0293  coal
+022E  0293  charcoal
+022E  0293  diamond
+022E  0293  iron_ingot
+022E  0293  gold_ingot
+022E  0293  iron_sword
+022E  0293  wooden_sword
+022E  0293  wooden_shovel
+022E  0293  wooden_pickaxe
+022E  0293  wooden_axe
+022E  0293  stone_sword
+022E  0293  stone_shovel
+022E  0293  stone_pickaxe
+022E  0293  stone_axe
+022E  0293  diamond_sword
+022E  0293  diamond_shovel
+022E  0293  diamond_pickaxe
+022E  0293  diamond_axe
+022E  0293  stick
+022E  0293  bowl
+022E  0293  mushroom_stew
+022E  0293  golden_sword
+022E  0293  golden_shovel
+022E  0293  golden_pickaxe
+022E  0293  golden_axe
+022E  0293  string
+022E  0293  feather
+022E  0293  gunpowder
+022E  0293  wooden_hoe
+022E  0293  stone_hoe
+022E  0293  iron_hoe
+022E  0293  diamond_hoe
+022E  0293  golden_hoe
+022E  0293  wheat_seeds
+022E  0293  wheat
+022E  0293  bread
+022E  0293  leather_helmet
+022E  0293  leather_chestplate
+022E  0293  leather_leggings
+022E  0293  leather_boots
+022E  0293  chainmail_helmet
+022E  0293  chainmail_chestplate
+022E  0293  chainmail_leggings
+022E  0293  chainmail_boots
+022E  0293  iron_helmet
+022E  0293  iron_chestplate
+022E  0293  iron_leggings
+022E  0293  iron_boots
+022E  0293  diamond_helmet
+022E  0293  diamond_chestplate
+022E  0293  diamond_leggings
+022E  0293  diamond_boots
+022E  0293  golden_helmet
+022E  0293  golden_chestplate
+022E  0293  golden_leggings
+022E  0293  golden_boots
+022E  0293  flint
+022E  0293  porkchop
+022E  0293  cooked_porkchop
+022E  0293  painting
+022E  0293  golden_apple
+022E  0293  enchanted_golden_apple
+022E  0293  oak_sign
+022E  0293  spruce_sign
+022E  0293  birch_sign
+022E  0293  jungle_sign
+022E  0293  acacia_sign
+022E  0293  dark_oak_sign
+022E  0293  bucket
+022E  0293  water_bucket
+022E  0293  lava_bucket
+022E  0293  minecart
+022E  0293  saddle
+022E  0293  redstone
+022E  0293  snowball
+022E  0293  oak_boat
+022E  0293  leather
+022E  0293  milk_bucket
+022E  0293  pufferfish_bucket
+022E  0293  salmon_bucket
+022E  0293  cod_bucket
+022E  0293  tropical_fish_bucket
+022E  0293  brick
+022E  0293  clay_ball
+022E  0293  sugar_cane
+022E  0293  kelp
+022E  0293  dried_kelp_block
+022E  0293  bamboo
+022E  0293  paper
+022E  0293  book
+022E  0293  slime_ball
+022E  0293  chest_minecart
+022E  0293  furnace_minecart
+022E  0293  egg
+022E  0293  compass
+022E  0293  fishing_rod
+022E  0293  clock
+022E  0293  glowstone_dust
+022E  0293  cod
+022E  0293  salmon
+022E  0293  tropical_fish
+022E  0293  pufferfish
+022E  0293  cooked_cod
+022E  0293  cooked_salmon
+022E  0293  ink_sac
+022E  0293  red_dye
+022E  0293  green_dye
+022E  0293  cocoa_beans
+022E  0293  lapis_lazuli
+022E  0293  purple_dye
+022E  0293  cyan_dye
+022E  0293  light_gray_dye
+022E  0293  gray_dye
+022E  0293  pink_dye
+022E  0293  lime_dye
+022E  0293  yellow_dye
+022E  0293  light_blue_dye
+022E  0293  magenta_dye
+022E  0293  orange_dye
+022E  0293  bone_meal
+022E  0293  blue_dye
+022E  0293  brown_dye
+022E  0293  black_dye
+022E  0293  white_dye
+022E  0293  bone
+022E  0293  sugar
+022E  0293  cake
+022E  0293  white_bed
+022E  0293  orange_bed
+022E  0293  magenta_bed
+022E  0293  light_blue_bed
+022E  0293  yellow_bed
+022E  0293  lime_bed
+022E  0293  pink_bed
+022E  0293  gray_bed
+022E  0293  light_gray_bed
+022E  0293  cyan_bed
+022E  0293  purple_bed
+022E  0293  blue_bed
+022E  0293  brown_bed
+022E  0293  green_bed
+022E  0293  red_bed
+022E  0293  black_bed
+022E  0293  cookie
+022E  0293  filled_map
+022E  0293  shears
+022E  0293  melon_slice
+022E  0293  dried_kelp
+022E  0293  pumpkin_seeds
+022E  0293  melon_seeds
+022E  0293  beef
+022E  0293  cooked_beef
+022E  0293  chicken
+022E  0293  cooked_chicken
+022E  0293  rotten_flesh
+022E  0293  ender_pearl
+022E  0293  blaze_rod
+022E  0293  ghast_tear
+022E  0293  gold_nugget
+022E  0293  nether_wart
+022E  0293  potion
+022E  0293  glass_bottle
+022E  0293  spider_eye
+022E  0293  fermented_spider_eye
+022E  0293  blaze_powder
+022E  0293  magma_cream
+022E  0293  brewing_stand
+022E  0293  cauldron
+022E  0293  ender_eye
+022E  0293  glistering_melon_slice
+022E  0293  bat_spawn_egg
+0286  0293  bee_spawn_egg
+022E  0293  blaze_spawn_egg
+022E  0293  cat_spawn_egg
+022E  0293  cave_spider_spawn_egg
+022E  0293  chicken_spawn_egg
+022E  0293  cod_spawn_egg
+022E  0293  cow_spawn_egg
+022E  0293  creeper_spawn_egg
+022E  0293  dolphin_spawn_egg
+022E  0293  donkey_spawn_egg
+022E  0293  drowned_spawn_egg
+022E  0293  elder_guardian_spawn_egg
+022E  0293  enderman_spawn_egg
+022E  0293  endermite_spawn_egg
+022E  0293  evoker_spawn_egg
+022E  0293  fox_spawn_egg
+022E  0293  ghast_spawn_egg
+022E  0293  guardian_spawn_egg
+022E  0293  horse_spawn_egg
+022E  0293  husk_spawn_egg
+022E  0293  llama_spawn_egg
+022E  0293  magma_cube_spawn_egg
+022E  0293  mooshroom_spawn_egg
+022E  0293  mule_spawn_egg
+022E  0293  ocelot_spawn_egg
+022E  0293  panda_spawn_egg
+022E  0293  parrot_spawn_egg
+022E  0293  phantom_spawn_egg
+022E  0293  pig_spawn_egg
+022E  0293  pillager_spawn_egg
+022E  0293  polar_bear_spawn_egg
+022E  0293  pufferfish_spawn_egg
+022E  0293  rabbit_spawn_egg
+022E  0293  ravager_spawn_egg
+022E  0293  salmon_spawn_egg
+022E  0293  sheep_spawn_egg
+022E  0293  shulker_spawn_egg
+022E  0293  silverfish_spawn_egg
+022E  0293  skeleton_spawn_egg
+022E  0293  skeleton_horse_spawn_egg
+022E  0293  slime_spawn_egg
+022E  0293  spider_spawn_egg
+022E  0293  squid_spawn_egg
+022E  0293  stray_spawn_egg
+022E  0293  trader_llama_spawn_egg
+022E  0293  tropical_fish_spawn_egg
+022E  0293  turtle_spawn_egg
+022E  0293  vex_spawn_egg
+022E  0293  villager_spawn_egg
+022E  0293  vindicator_spawn_egg
+022E  0293  wandering_trader_spawn_egg
+022E  0293  witch_spawn_egg
+022E  0293  wither_skeleton_spawn_egg
+022E  0293  wolf_spawn_egg
+022E  0293  zombie_spawn_egg
+022E  0293  zombie_horse_spawn_egg
+022E  0293  zombie_pigman_spawn_egg
+022E  0293  zombie_villager_spawn_egg
+022E  0293  experience_bottle
+022E  0293  fire_charge
+022E  0293  writable_book
+022E  0293  written_book
+022E  0293  emerald
+022E  0293  item_frame
+022E  0293  flower_pot
+022E  0293  carrot
+022E  0293  potato
+022E  0293  baked_potato
+022E  0293  poisonous_potato
+022E  0293  map
+022E  0293  golden_carrot
+022E  0293  skeleton_skull
+022E  0293  wither_skeleton_skull
+022E  0293  player_head
+022E  0293  zombie_head
+022E  0293  creeper_head
+022E  0293  dragon_head
+022E  0293  carrot_on_a_stick
+022E  0293  nether_star
+022E  0293  pumpkin_pie
+022E  0293  firework_rocket
+022E  0293  firework_star
+022E  0293  enchanted_book
+022E  0293  nether_brick
+022E  0293  quartz
+022E  0293  tnt_minecart
+022E  0293  hopper_minecart
+022E  0293  prismarine_shard
+022E  0293  prismarine_crystals
+022E  0293  rabbit
+022E  0293  cooked_rabbit
+022E  0293  rabbit_stew
+022E  0293  rabbit_foot
+022E  0293  rabbit_hide
+022E  0293  armor_stand
+022E  0293  iron_horse_armor
+022E  0293  golden_horse_armor
+022E  0293  diamond_horse_armor
+022E  0293  leather_horse_armor
+022E  0293  lead
+022E  0293  name_tag
+022E  0293  command_block_minecart
+022E  0293  mutton
+022E  0293  cooked_mutton
+022E  0293  white_banner
+022E  0293  orange_banner
+022E  0293  magenta_banner
+022E  0293  light_blue_banner
+022E  0293  yellow_banner
+022E  0293  lime_banner
+022E  0293  pink_banner
+022E  0293  gray_banner
+022E  0293  light_gray_banner
+022E  0293  cyan_banner
+022E  0293  purple_banner
+022E  0293  blue_banner
+022E  0293  brown_banner
+022E  0293  green_banner
+022E  0293  red_banner
+022E  0293  black_banner
+022E  0293  end_crystal
+022E  0293  chorus_fruit
+022E  0293  popped_chorus_fruit
+022E  0293  beetroot
+022E  0293  beetroot_seeds
+022E  0293  beetroot_soup
+022E  0293  dragon_breath
+022E  0293  splash_potion
+022E  0293  spectral_arrow
+022E  0293  tipped_arrow
+022E  0293  lingering_potion
+022E  0293  shield
+022E  0293  elytra
+022E  0293  spruce_boat
+022E  0293  birch_boat
+022E  0293  jungle_boat
+022E  0293  acacia_boat
+022E  0293  dark_oak_boat
+022E  0293  totem_of_undying
+022E  0293  shulker_shell
+022E  0293  iron_nugget
+022E  0293  knowledge_book
+022E  0293  debug_stick
+022E  0293  music_disc_13
+022E  0293  music_disc_cat
+022E  0293  music_disc_blocks
+022E  0293  music_disc_chirp
+022E  0293  music_disc_far
+022E  0293  music_disc_mall
+022E  0293  music_disc_mellohi
+022E  0293  music_disc_stal
+022E  0293  music_disc_strad
+022E  0293  music_disc_ward
+022E  0293  music_disc_11
+022E  0293  music_disc_wait
+022E  0293  trident
+022E  0293  phantom_membrane
+022E  0293  nautilus_shell
+022E  0293  heart_of_the_sea
+022E  0293  crossbow
+022E  0293  suspicious_stew
+022E  0293  loom
+022E  0293  flower_banner_pattern
+022E  0293  creeper_banner_pattern
+022E  0293  skull_banner_pattern
+022E  0293  mojang_banner_pattern
+022E  0293  globe_banner_pattern
+022E  0293  barrel
+022E  0293  smoker
+022E  0293  blast_furnace
+022E  0293  cartography_table
+022E  0293  fletching_table
+022E  0293  grindstone
+022E  0293  lectern
+022E  0293  smithing_table
+022E  0293  stonecutter
+022E  0293  bell
+022E  0293  lantern
+022E  0293  sweet_berries
+022E  0293  campfire
+
+# Added in 1.15
+0286  0293  honeycomb
+0286  0293  bee_nest
+0286  0293  beehive
+0286  0293  honey_bottle
+0286  0293  honey_block
+0286  0293  honeycomb_block
+`
@@ -0,0 +1,248 @@
+// Package registry knows the numeric ids that each protocol version gives to blocks, items and biomes.
+//
+// Versions use the numbering described in docs/protocol_versions.md. Names may be given with or without the
+// "minecraft:" namespace, and are always returned with it.
+package registry
+
+import "fmt"
+import "sort"
+import "strconv"
+import "strings"
+
+const namespace = "minecraft:"
+
+// Returned when a name does not exist, or is not known to exist, in a particular version.
+type UnknownEntryError struct {
+	details string
+}
+
+func (err UnknownEntryError) Error() string {
+	return fmt.Sprintf("Unknown registry entry: %s", err.details)
+}
+
+// Adds the namespace to names without one. Returns false for names in other namespaces.
+func normalizeName(name string) (string, bool) {
+	if !strings.Contains(name, ":") {
+		return namespace + name, true
+	}
+
+	return name, strings.HasPrefix(name, namespace)
+}
+
+// A part of a table that applies to a range of versions, inclusive.
+type versionRange struct {
+	firstProtocol uint
+	lastProtocol uint
+}
+
+func (versions versionRange) contains(protocol uint) bool {
+	return protocol >= versions.firstProtocol && protocol <= versions.lastProtocol
+}
+
+// Splits the versions covered by the ranges wherever one of them starts or ends,
+// so that the same entries apply to every version of each part.
+func splitVersionRanges(ranges []versionRange) []versionRange {
+	var bounds []uint
+	for _, versions := range ranges {
+		bounds = append(bounds, versions.firstProtocol, versions.lastProtocol + 1)
+	}
+	sort.Slice(bounds, func(i int, j int) bool { return bounds[i] < bounds[j] })
+
+	var result []versionRange
+	for i := 0; i + 1 < len(bounds); i++ {
+		if bounds[i] == bounds[i + 1] {
+			continue
+		}
+
+		part := versionRange { bounds[i], bounds[i + 1] - 1 }
+		for _, versions := range ranges {
+			if versions.contains(part.firstProtocol) {
+				result = append(result, part)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func parseVersionRange(first string, last string) (result versionRange, ok bool) {
+	firstProtocol, err := strconv.ParseUint(first, 16, 16)
+	if err != nil {
+		return
+	}
+
+	lastProtocol, err := strconv.ParseUint(last, 16, 16)
+	if err != nil || lastProtocol < firstProtocol {
+		return
+	}
+
+	return versionRange { uint(firstProtocol), uint(lastProtocol) }, true
+}
+
+// Splits a table into the fields of each line, leaving out comments. Blank lines are kept, so that indices are line numbers.
+func tableLines(table string) [][]string {
+	var lines [][]string
+	for _, line := range strings.Split(table, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		lines = append(lines, strings.Fields(line))
+	}
+
+	return lines
+}
+
+func invalidTableLine(kind string, lineNumber int, reason string) {
+	panic(fmt.Sprintf("Internal package bug: %s table line %d: %s", kind, lineNumber, reason))
+}
+
+// Names and their ids in a range of versions.
+type registryPart struct {
+	versions versionRange
+	ids map[string]int32
+	names map[int32]string
+}
+
+// Maps names to ids, such as "minecraft:stone" to the id of the stone item.
+type Registry struct {
+	kind string
+	parts []registryPart
+}
+
+// The items that can be held, as sent in slots.
+var Items = newRegistry("item", itemTable)
+
+// The biomes of the overworld, the nether and the end, as sent in chunk data.
+var Biomes = newRegistry("biome", biomeTable)
+
+// The types of entities, such as "minecraft:pig", as sent when entities are spawned.
+var EntityTypes = newRegistry("entity_type", entityTypeTable)
+
+type registryEntry struct {
+	versions versionRange
+	name string
+	// Negative when the entry takes the id after the previous entry.
+	id int32
+}
+
+// The table is part of the source, so any mistake in it is a bug in this package.
+func newRegistry(kind string, table string) *Registry {
+	var entries []registryEntry
+	var ranges []versionRange
+
+	for lineNumber, fields := range tableLines(table) {
+		if len(fields) == 0 {
+			continue
+		}
+
+		invalid := func(reason string) {
+			invalidTableLine(kind, lineNumber, reason)
+		}
+
+		if len(fields) != 3 && len(fields) != 4 {
+			invalid("expected 3 or 4 fields")
+		}
+
+		versions, ok := parseVersionRange(fields[0], fields[1])
+		if !ok {
+			invalid("invalid versions")
+		}
+
+		entry := registryEntry { versions: versions, name: namespace + fields[2], id: -1 }
+		if len(fields) == 4 {
+			id, err := strconv.ParseInt(fields[3], 0, 32)
+			if err != nil || id < 0 {
+				invalid("invalid id")
+			}
+			entry.id = int32(id)
+		}
+
+		entries = append(entries, entry)
+		ranges = append(ranges, versions)
+	}
+
+	registry := &Registry { kind: kind }
+	for _, versions := range splitVersionRanges(ranges) {
+		part := registryPart {
+			versions: versions,
+			ids: make(map[string]int32),
+			names: make(map[int32]string),
+		}
+
+		next := int32(0)
+		for _, entry := range entries {
+			if !entry.versions.contains(versions.firstProtocol) {
+				continue
+			}
+
+			id := entry.id
+			if id < 0 {
+				id = next
+			}
+			next = id + 1
+
+			if _, ok := part.ids[entry.name]; ok {
+				panic(fmt.Sprintf("Internal package bug: %s table: %s listed twice", kind, entry.name))
+			}
+			if _, ok := part.names[id]; ok {
+				panic(fmt.Sprintf("Internal package bug: %s table: id %d used twice", kind, id))
+			}
+
+			part.ids[entry.name] = id
+			part.names[id] = entry.name
+		}
+
+		registry.parts = append(registry.parts, part)
+	}
+
+	return registry
+}
+
+func (registry *Registry) part(protocol uint) *registryPart {
+	for i := range registry.parts {
+		if registry.parts[i].versions.contains(protocol) {
+			return &registry.parts[i]
+		}
+	}
+
+	return nil
+}
+
+// Returns false for names that do not exist in the version.
+func (registry *Registry) Id(name string, protocol uint) (id int32, ok bool) {
+	part := registry.part(protocol)
+	if part == nil {
+		return
+	}
+
+	name, ok = normalizeName(name)
+	if !ok {
+		return
+	}
+
+	id, ok = part.ids[name]
+	return
+}
+
+// Returns false for ids that are not used by the version.
+func (registry *Registry) Name(id int32, protocol uint) (name string, ok bool) {
+	part := registry.part(protocol)
+	if part == nil {
+		return
+	}
+
+	name, ok = part.names[id]
+	return
+}
+
+// Like Id, but fails with an UnknownEntryError that names the entry.
+func (registry *Registry) LookupId(name string, protocol uint) (int32, error) {
+	id, ok := registry.Id(name, protocol)
+	if !ok {
+		return 0, UnknownEntryError { fmt.Sprintf("No %s %s in version %04X", registry.kind, name, protocol) }
+	}
+
+	return id, nil
+}
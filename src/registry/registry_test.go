@@ -0,0 +1,94 @@
+package registry
+
+import "testing"
+
+func TestRegistryIds(t *testing.T) {
+	iomap := []struct {
+		registry *Registry
+		protocol uint
+		name string
+		id int32
+	} {
+		{Items, 0x022E, "minecraft:air", 0},
+		{Items, 0x022E, "minecraft:stone", 1},
+		{Items, 0x022E, "minecraft:campfire", 876},
+		{Items, 0x0286, "minecraft:bat_spawn_egg", 697},
+		{Items, 0x0286, "minecraft:bee_spawn_egg", 698},
+		{Items, 0x0286, "minecraft:campfire", 877},
+		{Items, 0x0286, "minecraft:honeycomb_block", 883},
+		{Biomes, 0x022E, "minecraft:ocean", 0},
+		{Biomes, 0x022E, "minecraft:plains", 1},
+		{Biomes, 0x0286, "minecraft:the_void", 127},
+		{Biomes, 0x0286, "minecraft:bamboo_jungle_hills", 169},
+		{EntityTypes, 0x022E, "minecraft:area_effect_cloud", 0},
+		{EntityTypes, 0x022E, "minecraft:pig", 54},
+		{EntityTypes, 0x022E, "minecraft:player", 99},
+		{EntityTypes, 0x022E, "minecraft:trident", 101},
+		{EntityTypes, 0x0286, "minecraft:bee", 4},
+		{EntityTypes, 0x0286, "minecraft:pig", 55},
+		{EntityTypes, 0x0286, "minecraft:player", 100},
+	}
+
+	for i, mapping := range iomap {
+		id, ok := mapping.registry.Id(mapping.name, mapping.protocol)
+		if !ok || id != mapping.id {
+			t.Errorf("Output incorrect for mapping %d: %d", i, id)
+		}
+
+		name, ok := mapping.registry.Name(mapping.id, mapping.protocol)
+		if !ok || name != mapping.name {
+			t.Errorf("Reverse output incorrect for mapping %d: %s", i, name)
+		}
+	}
+
+	// The namespace may be left out
+	if id, ok := Items.Id("stone", 0x022E); !ok || id != 1 {
+		t.Errorf("Output incorrect without namespace: %d", id)
+	}
+}
+
+func TestUnknownRegistryEntries(t *testing.T) {
+	iemap := []struct {
+		registry *Registry
+		protocol uint
+		name string
+	} {
+		{Items, 0x022E, "minecraft:honeycomb"},
+		{Items, 0x022E, "other:stone"},
+		{Items, 0x0185, "minecraft:stone"},
+		{Biomes, 0x022E, "minecraft:nether_wastes"},
+		{EntityTypes, 0x022E, "minecraft:bee"},
+	}
+
+	for i, mapping := range iemap {
+		_, err := mapping.registry.LookupId(mapping.name, mapping.protocol)
+		if _, ok := err.(UnknownEntryError); !ok {
+			t.Errorf("Expected UnknownEntryError for mapping %d but instead got: %v", i, err)
+		}
+	}
+
+	if _, ok := Biomes.Name(128, 0x022E); ok {
+		t.Error("Unused biome id found")
+	}
+}
+
+func TestRegistryTable(t *testing.T) {
+	iemap := []string {
+		"022E  0293",
+		"022E  0293  stone  x",
+		"022E  0293  stone\n022E  0293  stone",
+		"022E  0293  stone  1\n022E  0293  dirt  1",
+	}
+
+	for i, table := range iemap {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for mapping %d", i)
+				}
+			}()
+
+			newRegistry("test", table)
+		}()
+	}
+}